@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/repository"
+)
+
+// backfillPhone rewrites every stored phone number into the canonical +62
+// format used by the API since phone numbers are normalized on input. Rows
+// that would end up sharing the same phone number are reported and left
+// untouched so they can be resolved manually.
+func backfillPhone(cfg config, args []string) error {
+	fs := flags("backfill-phone")
+	dryRun := fs.Bool("dry-run", false, "only report the changes without writing them")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	return normalizePhones(context.Background(), cfg.repository(), *dryRun, os.Stdout)
+}
+
+func normalizePhones(ctx context.Context, repo repository.RepositoryInterface, dryRun bool, w io.Writer) error {
+	rows, err := repo.FindAllPhone(ctx)
+	if nil != err {
+		return err
+	}
+
+	// group every row by its canonical phone number, a group with more than
+	// one member means the rows will collide on the unique phone index
+	groups := make(map[string][]repository.FindAllPhoneOutput)
+	for _, row := range rows {
+		p := handler.NormalizePhone(row.Phone)
+		groups[p] = append(groups[p], row)
+	}
+
+	var updated, collided int
+	for _, row := range rows {
+		p := handler.NormalizePhone(row.Phone)
+		if p == row.Phone {
+			continue
+		}
+
+		if group := groups[p]; len(group) > 1 {
+			collided++
+			fmt.Fprintf(w, "collision: id=%d phone=%q normalized=%q shared with ids=%v\n", row.Id, row.Phone, p, phoneIds(group))
+			continue
+		}
+
+		fmt.Fprintf(w, "update: id=%d phone=%q normalized=%q\n", row.Id, row.Phone, p)
+		if dryRun {
+			updated++
+			continue
+		}
+		if err = repo.PutPhone(ctx, repository.UpdatePhoneInput{Id: row.Id, Phone: p, Actor: repository.SystemActor}); nil != err {
+			return fmt.Errorf("updating user id %d: %w", row.Id, err)
+		}
+		updated++
+	}
+
+	fmt.Fprintf(w, "done: %d rows scanned, %d updated, %d collisions (dry run: %t)\n", len(rows), updated, collided, dryRun)
+
+	return nil
+}
+
+func phoneIds(rows []repository.FindAllPhoneOutput) []int {
+	out := make([]int, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, row.Id)
+	}
+
+	return out
+}
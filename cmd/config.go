@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/notification"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/storage"
+	"github.com/labstack/echo/v4"
+)
+
+// config is read from the environment once and shared by every command.
+type config struct {
+	// DatabaseDriver is postgres, the default, or sqlite in binaries built
+	// with the sqlite tag
+	DatabaseDriver string
+	DatabaseURL    string
+	// PrivateKey is the PEM file the tokens are signed with, see handler.LoadKeys
+	PrivateKey string
+	Server     handler.NewServerOptions
+	// AvatarDir and ExportDir hold the blobs of the server
+	AvatarDir     string
+	AvatarBaseURL string
+	ExportDir     string
+	// OpenAPIStrict rejects responses drifting from api.yml, keep it for
+	// development and testing only
+	OpenAPIStrict bool
+	PurgeInterval time.Duration
+	// AutoMigrate applies the pending migrations before serving
+	AutoMigrate bool
+	// TrustedProxies are the comma separated CIDR ranges whose
+	// X-Forwarded-For is believed, see ipExtractor
+	TrustedProxies string
+}
+
+func loadConfig() (config, error) {
+	cfg := config{
+		DatabaseDriver: os.Getenv("DATABASE_DRIVER"),
+		DatabaseURL:    os.Getenv("DATABASE_URL"),
+		PrivateKey:     handler.PrivateKeyPath(),
+		Server: handler.NewServerOptions{
+			// replace with an SMS gateway, messages are only logged for now
+			SMSSender: notification.NewLogSMSSender(os.Stdout),
+			// replace with an SMTP relay or mail API, mails are only written
+			// into files for now
+			Mailer: notification.NewFileMailer(notification.NewFileMailerOptions{
+				Dir:  envOr("MAIL_DIR", "mail"),
+				From: envOr("MAIL_FROM", "no-reply@localhost"),
+			}),
+			PublicURL: envOr("PUBLIC_URL", "http://localhost:1323"),
+			NameRules: handler.DefaultNameRules,
+		},
+		AvatarDir: envOr("AVATAR_DIR", "avatars"),
+		// point it to a CDN or proxy in front of /avatars when there is one
+		AvatarBaseURL:  envOr("AVATAR_BASE_URL", "/avatars"),
+		ExportDir:      envOr("EXPORT_DIR", "exports"),
+		OpenAPIStrict:  os.Getenv("OPENAPI_STRICT") == "true",
+		PurgeInterval:  time.Hour,
+		AutoMigrate:    os.Getenv("AUTO_MIGRATE") == "true",
+		TrustedProxies: os.Getenv("TRUSTED_PROXIES"),
+	}
+
+	opts := &cfg.Server
+	// comma separated unicode script names allowed in full names, e.g. Latin,Han
+	if scripts := os.Getenv("NAME_SCRIPTS"); scripts != "" {
+		opts.NameRules.Scripts = nil
+		for _, script := range strings.Split(scripts, ",") {
+			opts.NameRules.Scripts = append(opts.NameRules.Scripts, strings.TrimSpace(script))
+		}
+		// a typo would lock out every name of the script, refuse to start
+		if err := opts.NameRules.Validate(); nil != err {
+			return config{}, fmt.Errorf("NAME_SCRIPTS: %w", err)
+		}
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("PHONE_CHANGE_TTL")); nil == err {
+		opts.PhoneChangeTTL = ttl
+	}
+	if window, err := time.ParseDuration(os.Getenv("PHONE_CHANGE_CANCEL_WINDOW")); nil == err {
+		opts.PhoneChangeCancel = window
+	}
+	opts.RequireIfMatch = os.Getenv("REQUIRE_IF_MATCH") == "true"
+	if size, err := strconv.ParseInt(os.Getenv("AVATAR_MAX_BYTES"), 10, 64); nil == err {
+		opts.AvatarMaxBytes = size
+	}
+	if grace, err := time.ParseDuration(os.Getenv("DELETION_GRACE")); nil == err {
+		opts.DeletionGrace = grace
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("EXPORT_TTL")); nil == err {
+		opts.ExportTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("EMAIL_VERIFICATION_TTL")); nil == err {
+		opts.EmailVerificationTTL = ttl
+	}
+	if ttl, err := time.ParseDuration(os.Getenv("SESSION_CACHE_TTL")); nil == err {
+		opts.SessionCacheTTL = ttl
+	}
+	if interval, err := time.ParseDuration(os.Getenv("PURGE_INTERVAL")); nil == err {
+		cfg.PurgeInterval = interval
+	}
+
+	return cfg, nil
+}
+
+func (c config) repository() *repository.Repository {
+	return repository.NewRepository(repository.NewRepositoryOptions{
+		Driver: c.DatabaseDriver,
+		Dsn:    c.DatabaseURL,
+	})
+}
+
+// ipExtractor tells the address of the client, recorded in the audit trails.
+// Without trusted proxies it is the peer address, X-Forwarded-For is only
+// believed as far as it was appended by the listed proxies.
+func (c config) ipExtractor() (echo.IPExtractor, error) {
+	if c.TrustedProxies == "" {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range strings.Split(c.TrustedProxies, ",") {
+		_, ipRange, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if nil != err {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+func (c config) avatars() *storage.LocalBlobStore {
+	return storage.NewLocalBlobStore(storage.NewLocalBlobStoreOptions{
+		Dir:     c.AvatarDir,
+		BaseURL: c.AvatarBaseURL,
+	})
+}
+
+func (c config) exports() *storage.LocalBlobStore {
+	// exports hold personal data, they are only downloaded through the API
+	return storage.NewLocalBlobStore(storage.NewLocalBlobStoreOptions{
+		Dir: c.ExportDir,
+	})
+}
+
+// server builds the handlers on top of the repository. Only serve stores
+// blobs, the other commands get a server without blob stores.
+func (c config) server(repo repository.RepositoryInterface) *handler.Server {
+	opts := c.Server
+	opts.Repository = repo
+
+	return handler.NewServer(opts)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
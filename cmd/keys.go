@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/SawitProRecruitment/UserService/handler"
+)
+
+func generateKeys(cfg config, args []string) error {
+	fs := flags("generate-keys")
+	keyType := fs.String("type", handler.KeyRSA, "key type, rsa or ed25519")
+	out := fs.String("out", cfg.PrivateKey, "file to write the key into, PRIVATE_KEY by default")
+	force := fs.Bool("force", false, "overwrite an existing key, invalidating every token it signed")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	if _, err := os.Stat(*out); nil == err && !*force {
+		return fmt.Errorf("%s exists, use rotate-keys to replace it", *out)
+	} else if nil != err && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	key, err := handler.GenerateKey(*keyType)
+	if nil != err {
+		return err
+	}
+	if err = handler.WriteKey(*out, key); nil != err {
+		return err
+	}
+	fmt.Printf("wrote %s key to %s\n", *keyType, *out)
+
+	return nil
+}
+
+func rotateKeys(cfg config, args []string) error {
+	fs := flags("rotate-keys")
+	keyType := fs.String("type", "", "key type of the new key, rsa or ed25519, the type of the current key by default")
+	path := fs.String("key", cfg.PrivateKey, "file of the current key, PRIVATE_KEY by default")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	if err := handler.RotateKeys(*path, *keyType); nil != err {
+		return err
+	}
+	fmt.Printf("rotated %s, the replaced key is kept in %s.previous; restart the servers to sign with the new key\n", *path, *path)
+
+	return nil
+}
@@ -1,31 +1,78 @@
+// Command main runs the user service and the commands operating it, all
+// configured by the same environment variables:
+//
+//	main [command] [flags]
+//
+// Without a command it serves the API, run main help for the list.
 package main
 
 import (
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"sort"
+)
 
-	"github.com/SawitProRecruitment/UserService/generated"
-	"github.com/SawitProRecruitment/UserService/handler"
-	"github.com/SawitProRecruitment/UserService/repository"
+type command struct {
+	summary string
+	run     func(cfg config, args []string) error
+}
 
-	"github.com/labstack/echo/v4"
-)
+var commands = map[string]command{
+	"serve":          {summary: "serve the API on :1323, the default", run: serve},
+	"migrate":        {summary: "apply the pending schema migrations", run: migrate},
+	"create-user":    {summary: "register a user, the password is read from standard input", run: createUser},
+	"reset-password": {summary: "replace the password of a user, read from standard input", run: resetPassword},
+	"suspend-user":   {summary: "suspend a user on behalf of the service", run: suspendUser},
+	"import-users":   {summary: "register the users listed in a CSV file", run: importUsers},
+	"generate-keys":  {summary: "write a new token signing key", run: generateKeys},
+	"rotate-keys":    {summary: "replace the signing key, keeping the replaced one to verify", run: rotateKeys},
+	"backfill-phone": {summary: "rewrite the stored phone numbers into the +62 format", run: backfillPhone},
+}
 
 func main() {
-	e := echo.New()
-
-	var server generated.ServerInterface = newServer()
+	name, args := "serve", os.Args[1:]
+	// the container starts the server without arguments
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" || name == "-h" || name == "-help" {
+		usage(os.Stdout)
+		return
+	}
 
-	generated.RegisterHandlers(e, server)
-	e.Logger.Fatal(e.Start(":1323"))
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	cfg, err := loadConfig()
+	if nil == err {
+		err = cmd.run(cfg, args)
+	}
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "%s failed. stack trace: %s\n", name, err)
+		os.Exit(1)
+	}
 }
 
-func newServer() *handler.Server {
-	dbDsn := os.Getenv("DATABASE_URL")
-	var repo repository.RepositoryInterface = repository.NewRepository(repository.NewRepositoryOptions{
-		Dsn: dbDsn,
-	})
-	opts := handler.NewServerOptions{
-		Repository: repo,
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
-	return handler.NewServer(opts)
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "usage: %s [command] [flags]\n\ncommands:\n", os.Args[0])
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-20s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nrun %s <command> -h for the flags of a command\n", os.Args[0])
+}
+
+// flags returns the flag set of the command, exiting on invalid flags.
+func flags(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
 }
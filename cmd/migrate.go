@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/migrations"
+)
+
+func migrate(cfg config, args []string) error {
+	fs := flags("migrate")
+	down := fs.Int("down", 0, "revert the last n applied migrations instead")
+	status := fs.Bool("status", false, "list the migrations, applied or pending, without changing anything")
+	baseline := fs.Int("baseline", 0, "record the migrations up to this version as applied without running them, "+
+		"for databases created before migrations")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	repo := cfg.repository()
+	migrator, err := migrations.NewMigrator(migrations.NewMigratorOptions{Db: repo.Db.DB, Driver: repo.Db.Driver()})
+	if nil != err {
+		return err
+	}
+	ctx := context.Background()
+
+	switch {
+	case *status:
+		statuses, err := migrator.Status(ctx)
+		if nil != err {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if nil != s.AppliedAt {
+				applied = "applied " + s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Printf("%s %s\n", s.Migration, applied)
+		}
+
+		return nil
+	case *baseline > 0:
+		return migrator.Baseline(ctx, *baseline)
+	case *down > 0:
+		reverted, err := migrator.Down(ctx, *down)
+		for _, m := range reverted {
+			fmt.Printf("reverted %s\n", m)
+		}
+
+		return err
+	}
+
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		fmt.Printf("applied %s\n", m)
+	}
+
+	return err
+}
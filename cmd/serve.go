@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/handler"
+	"github.com/SawitProRecruitment/UserService/migrations"
+
+	"github.com/labstack/echo/v4"
+)
+
+func serve(cfg config, args []string) error {
+	fs := flags("serve")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+	// without a key no token can be issued, refuse to start instead
+	if err := handler.LoadKeys(cfg.PrivateKey); nil != err {
+		return err
+	}
+
+	e := echo.New()
+	ipExtractor, err := cfg.ipExtractor()
+	if nil != err {
+		return err
+	}
+	e.IPExtractor = ipExtractor
+
+	repo := cfg.repository()
+	if cfg.AutoMigrate {
+		migrator, err := migrations.NewMigrator(migrations.NewMigratorOptions{Db: repo.Db.DB, Driver: repo.Db.Driver()})
+		if nil != err {
+			return err
+		}
+		// replicas starting together wait for the first one to migrate
+		applied, err := migrator.Up(context.Background())
+		for _, m := range applied {
+			e.Logger.Infof("applied migration %s", m)
+		}
+		if nil != err {
+			return err
+		}
+	}
+
+	avatars := cfg.avatars()
+	cfg.Server.BlobStore = avatars
+	cfg.Server.Exports = cfg.exports()
+	server := cfg.server(repo)
+
+	// authentication comes first, a request without a valid token learns
+	// nothing about the schema of the operation
+	e.Use(handler.Middleware(handler.MiddlewareOptions{Sessions: server}))
+	e.Use(handler.OpenAPIMiddleware(handler.OpenAPIMiddlewareOptions{
+		Strict: cfg.OpenAPIStrict,
+	}))
+
+	generated.RegisterHandlers(e, server)
+	e.GET("/avatars/*", echo.WrapHandler(http.StripPrefix("/avatars/", avatars.Handler())))
+
+	go purge(e.Logger, server, cfg.PurgeInterval)
+
+	return e.Start(":1323")
+}
+
+// purge removes the accounts past their grace period and the expired exports
+// every interval. Every instance runs it, purging twice is harmless.
+func purge(logger echo.Logger, server *handler.Server, interval time.Duration) {
+	for range time.Tick(interval) {
+		n, err := server.PurgeDeletedAccounts(context.Background())
+		if nil != err {
+			logger.Errorf("purging deleted accounts: %s", err)
+		}
+		if n > 0 {
+			logger.Infof("purged %d deleted accounts", n)
+		}
+
+		n, err = server.PurgeExpiredExports(context.Background())
+		if nil != err {
+			logger.Errorf("purging expired exports: %s", err)
+		}
+		if n > 0 {
+			logger.Infof("purged %d expired exports", n)
+		}
+	}
+}
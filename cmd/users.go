@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/handler"
+)
+
+func createUser(cfg config, args []string) error {
+	fs := flags("create-user")
+	name := fs.String("name", "", "full name of the user")
+	phone := fs.String("phone", "", "phone number of the user")
+	admin := fs.Bool("admin", false, "appoint the user administrator")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	password, err := readPassword(os.Stdin)
+	if nil != err {
+		return err
+	}
+	publicId, err := cfg.server(cfg.repository()).CreateUser(context.Background(), handler.CreateUserInput{
+		FullName: *name,
+		Phone:    *phone,
+		Password: password,
+		Admin:    *admin,
+	})
+	if nil != err {
+		return err
+	}
+	fmt.Println(publicId)
+
+	return nil
+}
+
+func resetPassword(cfg config, args []string) error {
+	fs := flags("reset-password")
+	user := fs.String("user", "", "phone number or public id of the user")
+	reason := fs.String("reason", "", "why the password is replaced, kept with the action")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	password, err := readPassword(os.Stdin)
+	if nil != err {
+		return err
+	}
+
+	return cfg.server(cfg.repository()).SetUserPassword(context.Background(), *user, password, *reason)
+}
+
+func suspendUser(cfg config, args []string) error {
+	fs := flags("suspend-user")
+	user := fs.String("user", "", "phone number or public id of the user")
+	reason := fs.String("reason", "", "why the user is suspended, kept with the action")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	return cfg.server(cfg.repository()).SuspendAccount(context.Background(), *user, *reason)
+}
+
+// readPassword reads the password from the first line of r, so it stays out
+// of the shell history and the process list.
+func readPassword(r io.Reader) (string, error) {
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); nil == err && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, "password: ")
+		}
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if nil != err && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func importUsers(cfg config, args []string) error {
+	fs := flags("import-users")
+	dryRun := fs.Bool("dry-run", false, "only report what the import would do without registering anyone")
+	file := fs.String("file", "-", "CSV file to import, - reads standard input")
+	if err := fs.Parse(args); nil != err {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if nil != err {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		in = f
+	}
+
+	report, err := cfg.server(cfg.repository()).Import(context.Background(), in, *dryRun, "en")
+	writeImportReport(os.Stdout, report)
+
+	return err
+}
+
+func writeImportReport(w io.Writer, report generated.ImportReport) {
+	for _, row := range report.Rows {
+		fmt.Fprintf(w, "line %d: %s", row.Line, row.Status)
+		if nil != row.Phone {
+			fmt.Fprintf(w, " phone=%q", *row.Phone)
+		}
+		if nil != row.Id {
+			fmt.Fprintf(w, " id=%s", *row.Id)
+		}
+		if nil != row.Errors {
+			for _, e := range *row.Errors {
+				fmt.Fprintf(w, " %s: %s;", e.Field, e.Message)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "done: %d created, %d duplicate, %d invalid (dry run: %t)\n", report.Created, report.Duplicate, report.Invalid, report.DryRun)
+}
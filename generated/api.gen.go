@@ -8,9 +8,11 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
@@ -20,31 +22,502 @@ const (
 	BearerAuthScopes = "bearerAuth.Scopes"
 )
 
+// Defines values for ImportRowStatus.
+const (
+	ImportRowStatusCreated   ImportRowStatus = "created"
+	ImportRowStatusDuplicate ImportRowStatus = "duplicate"
+	ImportRowStatusInvalid   ImportRowStatus = "invalid"
+)
+
+// Defines values for PreferencesLanguage.
+const (
+	En PreferencesLanguage = "en"
+	Id PreferencesLanguage = "id"
+)
+
+// Defines values for PreferencesUnits.
+const (
+	Imperial PreferencesUnits = "imperial"
+	Metric   PreferencesUnits = "metric"
+)
+
+// Defines values for AccountStatus.
+const (
+	AccountStatusActive    AccountStatus = "active"
+	AccountStatusDeleted   AccountStatus = "deleted"
+	AccountStatusSuspended AccountStatus = "suspended"
+)
+
+// Defines values for DataExportStatus.
+const (
+	DataExportStatusPending DataExportStatus = "pending"
+	DataExportStatusReady   DataExportStatus = "ready"
+)
+
+// Defines values for ErrorCode.
+const (
+	ErrorCodeAccountSuspended      ErrorCode = "account_suspended"
+	ErrorCodeAdminRequired         ErrorCode = "admin_required"
+	ErrorCodeAvatarInvalid         ErrorCode = "avatar_invalid"
+	ErrorCodeAvatarTooLarge        ErrorCode = "avatar_too_large"
+	ErrorCodeAvatarType            ErrorCode = "avatar_type"
+	ErrorCodeBirthDateInvalid      ErrorCode = "birth_date_invalid"
+	ErrorCodeCountryInvalid        ErrorCode = "country_invalid"
+	ErrorCodeCsvInvalid            ErrorCode = "csv_invalid"
+	ErrorCodeEmailChanged          ErrorCode = "email_changed"
+	ErrorCodeEmailInvalid          ErrorCode = "email_invalid"
+	ErrorCodeEmailNotSet           ErrorCode = "email_not_set"
+	ErrorCodeEmailTaken            ErrorCode = "email_taken"
+	ErrorCodeEmailVerified         ErrorCode = "email_verified"
+	ErrorCodeExportNotFound        ErrorCode = "export_not_found"
+	ErrorCodeGenderInvalid         ErrorCode = "gender_invalid"
+	ErrorCodeImportTooLarge        ErrorCode = "import_too_large"
+	ErrorCodeInternalError         ErrorCode = "internal_error"
+	ErrorCodeInvalid               ErrorCode = "invalid"
+	ErrorCodeInvalidCredentials    ErrorCode = "invalid_credentials"
+	ErrorCodeInvalidRequest        ErrorCode = "invalid_request"
+	ErrorCodeNameCharacters        ErrorCode = "name_characters"
+	ErrorCodeNameLength            ErrorCode = "name_length"
+	ErrorCodeOtpInvalid            ErrorCode = "otp_invalid"
+	ErrorCodePasswordComplexity    ErrorCode = "password_complexity"
+	ErrorCodePasswordLength        ErrorCode = "password_length"
+	ErrorCodePasswordResetRequired ErrorCode = "password_reset_required"
+	ErrorCodePasswordReused        ErrorCode = "password_reused"
+	ErrorCodePhoneChangeNotFound   ErrorCode = "phone_change_not_found"
+	ErrorCodePhoneLength           ErrorCode = "phone_length"
+	ErrorCodePhonePrefix           ErrorCode = "phone_prefix"
+	ErrorCodePhoneTaken            ErrorCode = "phone_taken"
+	ErrorCodePostalCodeInvalid     ErrorCode = "postal_code_invalid"
+	ErrorCodePreconditionFailed    ErrorCode = "precondition_failed"
+	ErrorCodePreconditionRequired  ErrorCode = "precondition_required"
+	ErrorCodeRequired              ErrorCode = "required"
+	ErrorCodeStatusTransition      ErrorCode = "status_transition"
+	ErrorCodeTokenInvalid          ErrorCode = "token_invalid"
+	ErrorCodeTokenMissing          ErrorCode = "token_missing"
+	ErrorCodeTooLong               ErrorCode = "too_long"
+	ErrorCodeUnsupportedMediaType  ErrorCode = "unsupported_media_type"
+	ErrorCodeUserNotFound          ErrorCode = "user_not_found"
+)
+
+// Defines values for Gender.
+const (
+	GenderFemale Gender = "female"
+	GenderMale   Gender = "male"
+	GenderOther  Gender = "other"
+)
+
+// Defines values for ExportUsersParamsFormat.
+const (
+	Csv    ExportUsersParamsFormat = "csv"
+	Ndjson ExportUsersParamsFormat = "ndjson"
+)
+
+// AccountDeletionResponse defines model for AccountDeletionResponse.
+type AccountDeletionResponse struct {
+	// RestoreUntil Logging in until this time restores the account.
+	RestoreUntil time.Time `json:"restore_until"`
+}
+
+// ImportReport defines model for ImportReport.
+type ImportReport struct {
+	Created   int         `json:"created"`
+	DryRun    bool        `json:"dry_run"`
+	Duplicate int         `json:"duplicate"`
+	Invalid   int         `json:"invalid"`
+	Rows      []ImportRow `json:"rows"`
+}
+
+// ImportRow defines model for ImportRow.
+type ImportRow struct {
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Id Public identifier of a user.
+	Id *PublicId `json:"id,omitempty"`
+
+	// Line Line of the row in the file, the header is line 1.
+	Line int `json:"line"`
+
+	// Phone Normalized phone number of the row.
+	Phone *string `json:"phone,omitempty"`
+
+	// Status * `created` - the user is registered, or would be on a dry run.
+	// * `duplicate` - the phone number is registered already or listed on an earlier line.
+	// * `invalid` - see `errors`.
+	Status ImportRowStatus `json:"status"`
+}
+
+// ImportRowStatus * `created` - the user is registered, or would be on a dry run.
+// * `duplicate` - the phone number is registered already or listed on an earlier line.
+// * `invalid` - see `errors`.
+type ImportRowStatus string
+
+// AdminActionRequest defines model for AdminActionRequest.
+type AdminActionRequest struct {
+	// Reason Why the administrator acts, recorded together with the action.
+	Reason string `json:"reason"`
+}
+
+// AccountStatus Only `active` accounts can log in, `deleted` ones wait for the purge.
+type AccountStatus string
+
+// Address Postal address, `postal_code` has 5 digits in Indonesia.
+type Address struct {
+	City string `json:"city"`
+
+	// Country ISO 3166-1 alpha-2 country code.
+	Country    string  `json:"country"`
+	PostalCode string  `json:"postal_code"`
+	Province   *string `json:"province,omitempty"`
+	Street     string  `json:"street"`
+}
+
+// AddressPatch Members of the postal address to change, the ones left out keep their
+// value. `province` is removed by sending `null`. Without a stored address
+// `street`, `city`, `postal_code` and `country` are required.
+type AddressPatch struct {
+	City *string `json:"city,omitempty"`
+
+	// Country ISO 3166-1 alpha-2 country code.
+	Country    *string `json:"country,omitempty"`
+	PostalCode *string `json:"postal_code,omitempty"`
+	Province   *string `json:"province,omitempty"`
+	Street     *string `json:"street,omitempty"`
+}
+
+// Avatar URLs of the square profile photo thumbnails.
+type Avatar struct {
+	// Large 512×512 pixels.
+	Large string `json:"large"`
+
+	// Medium 256×256 pixels.
+	Medium string `json:"medium"`
+
+	// Small 64×64 pixels.
+	Small string `json:"small"`
+}
+
+// AvatarUploadRequest defines model for AvatarUploadRequest.
+type AvatarUploadRequest struct {
+	Avatar []byte `json:"avatar"`
+}
+
+// CancelPhoneChangeRequest defines model for CancelPhoneChangeRequest.
+type CancelPhoneChangeRequest struct {
+	Token string `json:"token"`
+}
+
+// ConfirmPhoneChangeRequest defines model for ConfirmPhoneChangeRequest.
+type ConfirmPhoneChangeRequest struct {
+	Code string `json:"code"`
+}
+
+// DataExportResponse defines model for DataExportResponse.
+type DataExportResponse struct {
+	// ExpiresAt The download link stops working at this time.
+	ExpiresAt *time.Time       `json:"expires_at,omitempty"`
+	Status    DataExportStatus `json:"status"`
+
+	// Url Download link of a ready export, it needs no Authorization header.
+	Url *string `json:"url,omitempty"`
+}
+
+// DataExportStatus defines model for DataExportStatus.
+type DataExportStatus string
+
+// EmailVerificationResponse defines model for EmailVerificationResponse.
+type EmailVerificationResponse struct {
+	Email string `json:"email"`
+
+	// ExpiresAt The link sent to the email address stops working at this time.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// EmailVerifiedResponse defines model for EmailVerifiedResponse.
+type EmailVerifiedResponse struct {
+	Email      string    `json:"email"`
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// ErrorCode Stable machine readable error code. New codes may be added but existing
+// codes will never change their meaning.
+// * `invalid` - the value can not be processed.
+// * `required` - the value is missing or empty.
+// * `phone_prefix` - phone number is not an Indonesian (+62) number.
+// * `phone_length` - phone number must have 10 to 13 digits after +62.
+// * `password_length` - password must have 6 to 64 characters.
+// * `password_complexity` - password must contain a capital letter, a number and a special character.
+// * `invalid_request` - request body is malformed and can not be parsed.
+// * `invalid_credentials` - phone number and password do not match.
+// * `user_not_found` - no user is registered with the phone number.
+// * `phone_taken` - phone number is already used by another user.
+// * `token_missing` - request has no bearer token.
+// * `token_invalid` - bearer token is invalid or expired.
+// * `internal_error` - unexpected server failure.
+// * `name_length` - full name must have the configured number of characters, 1 to 60 by default.
+// * `name_characters` - full name contains digits, control characters or letters of a script that is not allowed.
+// * `phone_change_not_found` - no pending phone number change, it may be expired, cancelled or confirmed.
+// * `otp_invalid` - OTP does not match, the change is cancelled after too many attempts.
+// * `unsupported_media_type` - request body is not sent with the documented content type.
+// * `precondition_failed` - `If-Match` does not match the current entity tag, the resource was changed in the meantime.
+// * `precondition_required` - request has to carry the `If-Match` header.
+// * `email_invalid` - email is not a valid address.
+// * `birth_date_invalid` - date of birth is malformed, in the future or before 1900.
+// * `gender_invalid` - gender is not one of the documented values.
+// * `postal_code_invalid` - postal code does not match the format of the country.
+// * `country_invalid` - country is not an ISO 3166-1 alpha-2 code.
+// * `too_long` - value exceeds the documented maximum length.
+// * `avatar_type` - image is neither JPEG nor PNG.
+// * `avatar_invalid` - image can not be decoded.
+// * `avatar_too_large` - image exceeds the file size or dimension limit.
+// * `export_not_found` - export does not exist or was removed after expiring.
+// * `email_not_set` - profile has no email address to verify.
+// * `email_verified` - email address is verified already.
+// * `email_taken` - email address is verified by another user.
+// * `email_changed` - email address was changed after the verification link was sent.
+// * `admin_required` - user calling is not an administrator.
+// * `account_suspended` - account is suspended.
+// * `status_transition` - account can not move from its current status to the requested one.
+// * `password_reset_required` - password has to be changed, log in again with `new_password`.
+// * `password_reused` - new password is the current one.
+// * `csv_invalid` - file is not a CSV file with the `full_name`, `phone` and `password` columns.
+// * `import_too_large` - file has more rows than a single import accepts.
+type ErrorCode string
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
+	// Code Stable machine readable error code. New codes may be added but existing
+	// codes will never change their meaning.
+	// * `invalid` - the value can not be processed.
+	// * `required` - the value is missing or empty.
+	// * `phone_prefix` - phone number is not an Indonesian (+62) number.
+	// * `phone_length` - phone number must have 10 to 13 digits after +62.
+	// * `password_length` - password must have 6 to 64 characters.
+	// * `password_complexity` - password must contain a capital letter, a number and a special character.
+	// * `invalid_request` - request body is malformed and can not be parsed.
+	// * `invalid_credentials` - phone number and password do not match.
+	// * `user_not_found` - no user is registered with the phone number.
+	// * `phone_taken` - phone number is already used by another user.
+	// * `token_missing` - request has no bearer token.
+	// * `token_invalid` - bearer token is invalid or expired.
+	// * `internal_error` - unexpected server failure.
+	// * `name_length` - full name must have the configured number of characters, 1 to 60 by default.
+	// * `name_characters` - full name contains digits, control characters or letters of a script that is not allowed.
+	// * `phone_change_not_found` - no pending phone number change, it may be expired, cancelled or confirmed.
+	// * `otp_invalid` - OTP does not match, the change is cancelled after too many attempts.
+	// * `unsupported_media_type` - request body is not sent with the documented content type.
+	// * `precondition_failed` - `If-Match` does not match the current entity tag, the resource was changed in the meantime.
+	// * `precondition_required` - request has to carry the `If-Match` header.
+	// * `email_invalid` - email is not a valid address.
+	// * `birth_date_invalid` - date of birth is malformed, in the future or before 1900.
+	// * `gender_invalid` - gender is not one of the documented values.
+	// * `postal_code_invalid` - postal code does not match the format of the country.
+	// * `country_invalid` - country is not an ISO 3166-1 alpha-2 code.
+	// * `too_long` - value exceeds the documented maximum length.
+	// * `avatar_type` - image is neither JPEG nor PNG.
+	// * `avatar_invalid` - image can not be decoded.
+	// * `avatar_too_large` - image exceeds the file size or dimension limit.
+	// * `export_not_found` - export does not exist or was removed after expiring.
+	// * `email_not_set` - profile has no email address to verify.
+	// * `email_verified` - email address is verified already.
+	// * `email_taken` - email address is verified by another user.
+	// * `email_changed` - email address was changed after the verification link was sent.
+	// * `admin_required` - user calling is not an administrator.
+	// * `account_suspended` - account is suspended.
+	// * `status_transition` - account can not move from its current status to the requested one.
+	// * `password_reset_required` - password has to be changed, log in again with `new_password`.
+	// * `password_reused` - new password is the current one.
+	// * `csv_invalid` - file is not a CSV file with the `full_name`, `phone` and `password` columns.
+	// * `import_too_large` - file has more rows than a single import accepts.
+	Code ErrorCode `json:"code"`
+
+	// Errors Every validation failure of the request, one entry per offending field.
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Message Message of the code, translated according to the `Accept-Language` request header.
+	Message string `json:"message"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Code Stable machine readable error code. New codes may be added but existing
+	// codes will never change their meaning.
+	// * `invalid` - the value can not be processed.
+	// * `required` - the value is missing or empty.
+	// * `phone_prefix` - phone number is not an Indonesian (+62) number.
+	// * `phone_length` - phone number must have 10 to 13 digits after +62.
+	// * `password_length` - password must have 6 to 64 characters.
+	// * `password_complexity` - password must contain a capital letter, a number and a special character.
+	// * `invalid_request` - request body is malformed and can not be parsed.
+	// * `invalid_credentials` - phone number and password do not match.
+	// * `user_not_found` - no user is registered with the phone number.
+	// * `phone_taken` - phone number is already used by another user.
+	// * `token_missing` - request has no bearer token.
+	// * `token_invalid` - bearer token is invalid or expired.
+	// * `internal_error` - unexpected server failure.
+	// * `name_length` - full name must have the configured number of characters, 1 to 60 by default.
+	// * `name_characters` - full name contains digits, control characters or letters of a script that is not allowed.
+	// * `phone_change_not_found` - no pending phone number change, it may be expired, cancelled or confirmed.
+	// * `otp_invalid` - OTP does not match, the change is cancelled after too many attempts.
+	// * `unsupported_media_type` - request body is not sent with the documented content type.
+	// * `precondition_failed` - `If-Match` does not match the current entity tag, the resource was changed in the meantime.
+	// * `precondition_required` - request has to carry the `If-Match` header.
+	// * `email_invalid` - email is not a valid address.
+	// * `birth_date_invalid` - date of birth is malformed, in the future or before 1900.
+	// * `gender_invalid` - gender is not one of the documented values.
+	// * `postal_code_invalid` - postal code does not match the format of the country.
+	// * `country_invalid` - country is not an ISO 3166-1 alpha-2 code.
+	// * `too_long` - value exceeds the documented maximum length.
+	// * `avatar_type` - image is neither JPEG nor PNG.
+	// * `avatar_invalid` - image can not be decoded.
+	// * `avatar_too_large` - image exceeds the file size or dimension limit.
+	// * `export_not_found` - export does not exist or was removed after expiring.
+	// * `email_not_set` - profile has no email address to verify.
+	// * `email_verified` - email address is verified already.
+	// * `email_taken` - email address is verified by another user.
+	// * `email_changed` - email address was changed after the verification link was sent.
+	// * `admin_required` - user calling is not an administrator.
+	// * `account_suspended` - account is suspended.
+	// * `status_transition` - account can not move from its current status to the requested one.
+	// * `password_reset_required` - password has to be changed, log in again with `new_password`.
+	// * `password_reused` - new password is the current one.
+	// * `csv_invalid` - file is not a CSV file with the `full_name`, `phone` and `password` columns.
+	// * `import_too_large` - file has more rows than a single import accepts.
+	Code ErrorCode `json:"code"`
+
+	// Field Name of the request body property, e.g. `phone`.
+	Field string `json:"field"`
+
+	// Message Message of the code, translated according to the `Accept-Language` request header.
 	Message string `json:"message"`
 }
 
-// LoginRequest defines model for LoginRequest.
+// Gender defines model for Gender.
+type Gender string
+
+// LoginRequest Either `phone` or a verified `email` identifies the user.
 type LoginRequest struct {
-	Password string `json:"password"`
-	Phone    string `json:"phone"`
+	// Email Matched regardless of case, only once it is verified.
+	Email string `json:"email,omitempty"`
+
+	// NewPassword Replaces the password when an administrator required changing it.
+	NewPassword string `json:"new_password,omitempty"`
+	Password    string `json:"password"`
+	Phone       string `json:"phone,omitempty"`
 }
 
 // LoginResponse defines model for LoginResponse.
 type LoginResponse struct {
-	Id    int    `json:"id"`
-	Token string `json:"token"`
+	// Id Stable opaque user id, a ULID which never changes.
+	Id PublicId `json:"id"`
+
+	// Restored The account was deleted and is restored by this login.
+	Restored *bool  `json:"restored,omitempty"`
+	Token    string `json:"token"`
 }
 
+// PatchProfileRequest `full_name` and `phone` can not be removed, the optional attributes are
+// removed by sending `null`.
+type PatchProfileRequest struct {
+	// Address Members of the postal address to change, the ones left out keep their
+	// value. `province` is removed by sending `null`. Without a stored address
+	// `street`, `city`, `postal_code` and `country` are required.
+	Address   *AddressPatch `json:"address,omitempty"`
+	BirthDate *string       `json:"birth_date,omitempty"`
+	Email     *string       `json:"email,omitempty"`
+
+	// FullName Normalized into NFC with collapsed white space, 1 to 60 characters by default.
+	FullName *string `json:"full_name,omitempty"`
+	Gender   *Gender `json:"gender,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+}
+
+// PhoneChangeResponse defines model for PhoneChangeResponse.
+type PhoneChangeResponse struct {
+	// ExpiresAt The OTP and the cancel token are valid until this time.
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Phone New phone number waiting for verification.
+	Phone string `json:"phone"`
+}
+
+// Preferences Settings of the user kept across devices. New preferences may be added,
+// clients keep the ones they do not know as they are.
+type Preferences struct {
+	// Language Language of the app and of the messages sent to the user.
+	Language *PreferencesLanguage `json:"language,omitempty"`
+
+	// MarketingOptIn Consent to receive promotions, off until the user opts in.
+	MarketingOptIn *bool `json:"marketing_opt_in,omitempty"`
+
+	// NotifyProduct Messages about new features and changes of the service.
+	NotifyProduct *bool `json:"notify_product,omitempty"`
+
+	// NotifySecurity Messages about logins and changes of the phone number or email address.
+	NotifySecurity *bool             `json:"notify_security,omitempty"`
+	Units          *PreferencesUnits `json:"units,omitempty"`
+}
+
+// PreferencesLanguage Language of the app and of the messages sent to the user.
+type PreferencesLanguage string
+
+// PreferencesUnits defines model for Preferences.Units.
+type PreferencesUnits string
+
 // ProfileResponse defines model for ProfileResponse.
 type ProfileResponse struct {
-	FullName string `json:"full_name"`
-	Phone    string `json:"phone"`
+	// Address Postal address, `postal_code` has 5 digits in Indonesia.
+	Address *Address `json:"address,omitempty"`
+
+	// Avatar URLs of the square profile photo thumbnails.
+	Avatar *Avatar `json:"avatar,omitempty"`
+
+	// BirthDate Date of birth, not in the future and not before 1900-01-01.
+	BirthDate *string `json:"birth_date,omitempty"`
+
+	// Email The domain is lower cased, the local part is kept as is.
+	Email *string `json:"email,omitempty"`
+
+	// EmailVerified Present with `email`, a verified email address can be used to log in.
+	// Changing the address takes the verification back.
+	EmailVerified *bool   `json:"email_verified,omitempty"`
+	FullName      string  `json:"full_name"`
+	Gender        *Gender `json:"gender,omitempty"`
+	Phone         string  `json:"phone"`
 }
 
+// ProfileChange A single changed field of the profile.
+type ProfileChange struct {
+	// Actor Id of the user making the change, `system` for changes made by the service itself.
+	Actor     string    `json:"actor"`
+	ChangedAt time.Time `json:"changed_at"`
+
+	// Field Name of the field, `address.` prefixes the lines of the address.
+	Field string `json:"field"`
+
+	// NewValue Value after the change, missing when the field was removed.
+	NewValue *string `json:"new_value,omitempty"`
+
+	// OldValue Value before the change, missing when the field was not set.
+	OldValue *string `json:"old_value,omitempty"`
+
+	// SourceIp Address the change was requested from.
+	SourceIp *string `json:"source_ip,omitempty"`
+}
+
+// ProfileHistoryResponse defines model for ProfileHistoryResponse.
+type ProfileHistoryResponse struct {
+	Changes []ProfileChange `json:"changes"`
+
+	// NextCursor Cursor of the next page, missing on the last page.
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// PublicId Stable opaque user id, a ULID which never changes.
+type PublicId = string
+
 // RegistrationRequest defines model for RegistrationRequest.
 type RegistrationRequest struct {
+	// FullName Normalized into NFC with collapsed white space, 1 to 60 characters by default.
 	FullName string `json:"full_name"`
 	Password string `json:"password"`
 	Phone    string `json:"phone"`
@@ -52,35 +525,273 @@ type RegistrationRequest struct {
 
 // RegistrationResponse defines model for RegistrationResponse.
 type RegistrationResponse struct {
-	Id int `json:"id"`
+	// Id Stable opaque user id, a ULID which never changes.
+	Id PublicId `json:"id"`
 }
 
-// UpdateRequest defines model for UpdateRequest.
+// UpdateRequest Replaces the user information. The optional attributes left out of the
+// request keep their current value, remove them with `PATCH /profile`.
 type UpdateRequest struct {
-	FullName string `json:"full_name"`
-	Phone    string `json:"phone"`
+	// Address Postal address, `postal_code` has 5 digits in Indonesia.
+	Address *Address `json:"address,omitempty"`
+
+	// BirthDate Date of birth, not in the future and not before 1900-01-01.
+	BirthDate *string `json:"birth_date,omitempty"`
+
+	// Email The domain is lower cased, the local part is kept as is.
+	Email *string `json:"email,omitempty"`
+
+	// FullName Normalized into NFC with collapsed white space, 1 to 60 characters by default.
+	FullName string  `json:"full_name"`
+	Gender   *Gender `json:"gender,omitempty"`
+	Phone    string  `json:"phone"`
+}
+
+// UserListResponse defines model for UserListResponse.
+type UserListResponse struct {
+	// NextCursor Cursor of the next page, missing on the last page.
+	NextCursor *string       `json:"next_cursor,omitempty"`
+	Users      []UserSummary `json:"users"`
+}
+
+// UserMatch defines model for UserMatch.
+type UserMatch struct {
+	// Score Similarity of the name from 0 to 1, 1 being an exact match.
+	Score float64     `json:"score"`
+	User  UserSummary `json:"user"`
+}
+
+// UserSearchResponse defines model for UserSearchResponse.
+type UserSearchResponse struct {
+	Users []UserMatch `json:"users"`
+}
+
+// UserSummary defines model for UserSummary.
+type UserSummary struct {
+	Admin         bool       `json:"admin"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	Email         *string    `json:"email,omitempty"`
+	EmailVerified bool       `json:"email_verified"`
+	FullName      string     `json:"full_name"`
+
+	// Id Stable opaque user id, a ULID which never changes.
+	Id    PublicId `json:"id"`
+	Phone string   `json:"phone"`
+
+	// Status Only `active` accounts can log in, `deleted` ones wait for the purge.
+	Status AccountStatus `json:"status"`
+}
+
+// Cursor defines model for Cursor.
+type Cursor = string
+
+// Limit defines model for Limit.
+type Limit = int
+
+// AdminProfileHistoryParams defines parameters for AdminProfileHistory.
+type AdminProfileHistoryParams struct {
+	// Cursor Opaque `next_cursor` of the previous page, the first page without it.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items on the page.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// DownloadProfileExportParams defines parameters for DownloadProfileExport.
+type DownloadProfileExportParams struct {
+	Token string `form:"token" json:"token"`
+}
+
+// ExportUsersParams defines parameters for ExportUsers.
+type ExportUsersParams struct {
+	// Format CSV with a header line, or one JSON object per line. Defaults to `csv`.
+	Format *ExportUsersParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+
+	// Columns Comma separated columns in the order to write them, all of them by default:
+	// `id`, `full_name`, `phone`, `email`, `email_verified`, `status`, `admin`,
+	// `created_at` and `deleted_at`.
+	Columns *string `form:"columns,omitempty" json:"columns,omitempty"`
+
+	// Q Same as for listing the users.
+	Q      *string        `form:"q,omitempty" json:"q,omitempty"`
+	Status *AccountStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// CreatedAfter Only users registered at or after this time.
+	CreatedAfter *time.Time `form:"created_after,omitempty" json:"created_after,omitempty"`
+
+	// CreatedBefore Only users registered before this time.
+	CreatedBefore *time.Time `form:"created_before,omitempty" json:"created_before,omitempty"`
+
+	// Verified Only users with, or without, a verified email address.
+	Verified *bool `form:"verified,omitempty" json:"verified,omitempty"`
+}
+
+// ExportUsersParamsFormat defines parameters for ExportUsers.
+type ExportUsersParamsFormat string
+
+// ImportUsersParams defines parameters for ImportUsers.
+type ImportUsersParams struct {
+	// DryRun Only report what the import would do without registering anyone.
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// ListUsersParams defines parameters for ListUsers.
+type ListUsersParams struct {
+	// Q Start of the phone number when it only holds digits, `+` and separators,
+	// `0812` matches `+62812...`. Otherwise the start of the full name, ignoring case.
+	Q      *string        `form:"q,omitempty" json:"q,omitempty"`
+	Status *AccountStatus `form:"status,omitempty" json:"status,omitempty"`
+
+	// CreatedAfter Only users registered at or after this time.
+	CreatedAfter *time.Time `form:"created_after,omitempty" json:"created_after,omitempty"`
+
+	// CreatedBefore Only users registered before this time.
+	CreatedBefore *time.Time `form:"created_before,omitempty" json:"created_before,omitempty"`
+
+	// Verified Only users with, or without, a verified email address.
+	Verified *bool `form:"verified,omitempty" json:"verified,omitempty"`
+
+	// Cursor Opaque `next_cursor` of the previous page, the first page without it.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items on the page.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
 }
 
+// ProfileHistoryParams defines parameters for ProfileHistory.
+type ProfileHistoryParams struct {
+	// Cursor Opaque `next_cursor` of the previous page, the first page without it.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Limit Maximum number of items on the page.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// SearchUsersParams defines parameters for SearchUsers.
+type SearchUsersParams struct {
+	// Q Name or part of it, at least two letters.
+	Q string `form:"q" json:"q"`
+
+	// Limit Maximum number of items on the page.
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
+// VerifyEmailParams defines parameters for VerifyEmail.
+type VerifyEmailParams struct {
+	Token string `form:"token" json:"token"`
+}
+
+// ForceUserPasswordResetJSONRequestBody defines body for ForceUserPasswordReset for application/json ContentType.
+type ForceUserPasswordResetJSONRequestBody = AdminActionRequest
+
+// ReactivateUserJSONRequestBody defines body for ReactivateUser for application/json ContentType.
+type ReactivateUserJSONRequestBody = AdminActionRequest
+
+// RevokeUserSessionsJSONRequestBody defines body for RevokeUserSessions for application/json ContentType.
+type RevokeUserSessionsJSONRequestBody = AdminActionRequest
+
+// SuspendUserJSONRequestBody defines body for SuspendUser for application/json ContentType.
+type SuspendUserJSONRequestBody = AdminActionRequest
+
 // LoginJSONRequestBody defines body for Login for application/json ContentType.
 type LoginJSONRequestBody = LoginRequest
 
+// CancelPhoneChangeJSONRequestBody defines body for CancelPhoneChange for application/json ContentType.
+type CancelPhoneChangeJSONRequestBody = CancelPhoneChangeRequest
+
+// PatchProfileApplicationMergePatchPlusJSONRequestBody defines body for PatchProfile for application/merge-patch+json ContentType.
+type PatchProfileApplicationMergePatchPlusJSONRequestBody = PatchProfileRequest
+
 // UpdateProfileJSONRequestBody defines body for UpdateProfile for application/json ContentType.
 type UpdateProfileJSONRequestBody = UpdateRequest
 
+// UploadAvatarMultipartRequestBody defines body for UploadAvatar for multipart/form-data ContentType.
+type UploadAvatarMultipartRequestBody = AvatarUploadRequest
+
+// ConfirmPhoneChangeJSONRequestBody defines body for ConfirmPhoneChange for application/json ContentType.
+type ConfirmPhoneChangeJSONRequestBody = ConfirmPhoneChangeRequest
+
+// UpdatePreferencesJSONRequestBody defines body for UpdatePreferences for application/json ContentType.
+type UpdatePreferencesJSONRequestBody = Preferences
+
 // RegisterJSONRequestBody defines body for Register for application/json ContentType.
 type RegisterJSONRequestBody = RegistrationRequest
 
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// This will list the users, newest first
+	// (GET /admin/users)
+	ListUsers(ctx echo.Context, params ListUsersParams) error
+	// This will stream every user matching the filters, newest first
+	// (GET /admin/users/export)
+	ExportUsers(ctx echo.Context, params ExportUsersParams) error
+	// This will register the users listed in a CSV file
+	// (POST /admin/users/import)
+	ImportUsers(ctx echo.Context, params ImportUsersParams) error
+	// This will find the users by a partial or misspelled name, best match first
+	// (GET /admin/users/search)
+	SearchUsers(ctx echo.Context, params SearchUsersParams) error
+	// This will return the history of the profile changes of a user, newest first
+	// (GET /admin/users/{id}/history)
+	AdminProfileHistory(ctx echo.Context, id PublicId, params AdminProfileHistoryParams) error
+	// This will force the user to change the password at the next login
+	// (POST /admin/users/{id}/password-reset)
+	ForceUserPasswordReset(ctx echo.Context, id PublicId) error
+	// This will reactivate a suspended account
+	// (POST /admin/users/{id}/reactivate)
+	ReactivateUser(ctx echo.Context, id PublicId) error
+	// This will revoke every token issued to the user
+	// (POST /admin/users/{id}/sessions/revoke)
+	RevokeUserSessions(ctx echo.Context, id PublicId) error
+	// This will suspend an active account
+	// (POST /admin/users/{id}/suspend)
+	SuspendUser(ctx echo.Context, id PublicId) error
+	// This will verify an email address with the link sent to it
+	// (GET /email/verify)
+	VerifyEmail(ctx echo.Context, params VerifyEmailParams) error
 	// This will handle user login
 	// (POST /login)
 	Login(ctx echo.Context) error
+	// This will cancel a pending phone number change with the token sent to the old number
+	// (POST /phone-changes/cancel)
+	CancelPhoneChange(ctx echo.Context) error
+	// This will delete the account
+	// (DELETE /profile)
+	DeleteProfile(ctx echo.Context) error
 	// This will handle get user information
 	// (GET /profile)
 	Profile(ctx echo.Context) error
+	// This will partially update user information
+	// (PATCH /profile)
+	PatchProfile(ctx echo.Context) error
 	// This will handle update user information
 	// (PUT /profile)
 	UpdateProfile(ctx echo.Context) error
+	// This will replace the profile photo
+	// (PUT /profile/avatar)
+	UploadAvatar(ctx echo.Context) error
+	// This will send a verification link to the email address of the profile
+	// (POST /profile/email/verification)
+	RequestEmailVerification(ctx echo.Context) error
+	// This will export everything stored about the user
+	// (GET /profile/export)
+	ExportProfile(ctx echo.Context) error
+	// This will download a ready export
+	// (GET /profile/export/download)
+	DownloadProfileExport(ctx echo.Context, params DownloadProfileExportParams) error
+	// This will return the history of the profile changes, newest first
+	// (GET /profile/history)
+	ProfileHistory(ctx echo.Context, params ProfileHistoryParams) error
+	// This will confirm a pending phone number change with the OTP sent to the new number
+	// (POST /profile/phone/confirm)
+	ConfirmPhoneChange(ctx echo.Context) error
+	// This will return the preferences of the user
+	// (GET /profile/preferences)
+	Preferences(ctx echo.Context) error
+	// This will replace the preferences of the user
+	// (PUT /profile/preferences)
+	UpdatePreferences(ctx echo.Context) error
 	// This will handle process user registration.
 	// (POST /register)
 	Register(ctx echo.Context) error
@@ -91,6 +802,324 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// ListUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ListUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListUsersParams
+	// ------------- Optional query parameter "q" -------------
+
+	if ctx.QueryParams().Has("q") {
+		value := ctx.QueryParam("q")
+		params.Q = &value
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	if ctx.QueryParams().Has("status") {
+		value := AccountStatus(ctx.QueryParam("status"))
+		params.Status = &value
+	}
+
+	// ------------- Optional query parameter "created_after" -------------
+
+	if ctx.QueryParams().Has("created_after") {
+		var value time.Time
+		err = echo.QueryParamsBinder(ctx).Time("created_after", &value, time.RFC3339Nano).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_after: %s", err))
+		}
+		params.CreatedAfter = &value
+	}
+
+	// ------------- Optional query parameter "created_before" -------------
+
+	if ctx.QueryParams().Has("created_before") {
+		var value time.Time
+		err = echo.QueryParamsBinder(ctx).Time("created_before", &value, time.RFC3339Nano).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_before: %s", err))
+		}
+		params.CreatedBefore = &value
+	}
+
+	// ------------- Optional query parameter "verified" -------------
+
+	if ctx.QueryParams().Has("verified") {
+		var value bool
+		err = echo.QueryParamsBinder(ctx).Bool("verified", &value).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter verified: %s", err))
+		}
+		params.Verified = &value
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	if value := ctx.QueryParam("cursor"); value != "" {
+		params.Cursor = &value
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	if ctx.QueryParams().Has("limit") {
+		var limit Limit
+		err = echo.QueryParamsBinder(ctx).Int("limit", &limit).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+		}
+		params.Limit = &limit
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListUsers(ctx, params)
+	return err
+}
+
+// ExportUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ExportUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportUsersParams
+	// ------------- Optional query parameter "format" -------------
+
+	if ctx.QueryParams().Has("format") {
+		value := ExportUsersParamsFormat(ctx.QueryParam("format"))
+		params.Format = &value
+	}
+
+	// ------------- Optional query parameter "columns" -------------
+
+	if ctx.QueryParams().Has("columns") {
+		value := ctx.QueryParam("columns")
+		params.Columns = &value
+	}
+
+	// ------------- Optional query parameter "q" -------------
+
+	if ctx.QueryParams().Has("q") {
+		value := ctx.QueryParam("q")
+		params.Q = &value
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	if ctx.QueryParams().Has("status") {
+		value := AccountStatus(ctx.QueryParam("status"))
+		params.Status = &value
+	}
+
+	// ------------- Optional query parameter "created_after" -------------
+
+	if ctx.QueryParams().Has("created_after") {
+		var value time.Time
+		err = echo.QueryParamsBinder(ctx).Time("created_after", &value, time.RFC3339Nano).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_after: %s", err))
+		}
+		params.CreatedAfter = &value
+	}
+
+	// ------------- Optional query parameter "created_before" -------------
+
+	if ctx.QueryParams().Has("created_before") {
+		var value time.Time
+		err = echo.QueryParamsBinder(ctx).Time("created_before", &value, time.RFC3339Nano).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_before: %s", err))
+		}
+		params.CreatedBefore = &value
+	}
+
+	// ------------- Optional query parameter "verified" -------------
+
+	if ctx.QueryParams().Has("verified") {
+		var value bool
+		err = echo.QueryParamsBinder(ctx).Bool("verified", &value).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter verified: %s", err))
+		}
+		params.Verified = &value
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ExportUsers(ctx, params)
+	return err
+}
+
+// ImportUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ImportUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportUsersParams
+	// ------------- Optional query parameter "dry_run" -------------
+
+	if ctx.QueryParams().Has("dry_run") {
+		var value bool
+		err = echo.QueryParamsBinder(ctx).Bool("dry_run", &value).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter dry_run: %s", err))
+		}
+		params.DryRun = &value
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ImportUsers(ctx, params)
+	return err
+}
+
+// SearchUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) SearchUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchUsersParams
+	// ------------- Required query parameter "q" -------------
+
+	err = echo.QueryParamsBinder(ctx).MustString("q", &params.Q).BindError()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	if ctx.QueryParams().Has("limit") {
+		var limit Limit
+		err = echo.QueryParamsBinder(ctx).Int("limit", &limit).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+		}
+		params.Limit = &limit
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SearchUsers(ctx, params)
+	return err
+}
+
+// AdminProfileHistory converts echo context to params.
+func (w *ServerInterfaceWrapper) AdminProfileHistory(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PublicId
+
+	id = ctx.Param("id")
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AdminProfileHistoryParams
+	// ------------- Optional query parameter "cursor" -------------
+
+	if value := ctx.QueryParam("cursor"); value != "" {
+		params.Cursor = &value
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	if ctx.QueryParams().Has("limit") {
+		var limit Limit
+		err = echo.QueryParamsBinder(ctx).Int("limit", &limit).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+		}
+		params.Limit = &limit
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.AdminProfileHistory(ctx, id, params)
+	return err
+}
+
+// ForceUserPasswordReset converts echo context to params.
+func (w *ServerInterfaceWrapper) ForceUserPasswordReset(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PublicId
+
+	id = ctx.Param("id")
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ForceUserPasswordReset(ctx, id)
+	return err
+}
+
+// ReactivateUser converts echo context to params.
+func (w *ServerInterfaceWrapper) ReactivateUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PublicId
+
+	id = ctx.Param("id")
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ReactivateUser(ctx, id)
+	return err
+}
+
+// RevokeUserSessions converts echo context to params.
+func (w *ServerInterfaceWrapper) RevokeUserSessions(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PublicId
+
+	id = ctx.Param("id")
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RevokeUserSessions(ctx, id)
+	return err
+}
+
+// SuspendUser converts echo context to params.
+func (w *ServerInterfaceWrapper) SuspendUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id PublicId
+
+	id = ctx.Param("id")
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.SuspendUser(ctx, id)
+	return err
+}
+
+// VerifyEmail converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyEmail(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params VerifyEmailParams
+	// ------------- Required query parameter "token" -------------
+
+	err = echo.QueryParamsBinder(ctx).MustString("token", &params.Token).BindError()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.VerifyEmail(ctx, params)
+	return err
+}
+
 // Login converts echo context to params.
 func (w *ServerInterfaceWrapper) Login(ctx echo.Context) error {
 	var err error
@@ -100,6 +1129,26 @@ func (w *ServerInterfaceWrapper) Login(ctx echo.Context) error {
 	return err
 }
 
+// CancelPhoneChange converts echo context to params.
+func (w *ServerInterfaceWrapper) CancelPhoneChange(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.CancelPhoneChange(ctx)
+	return err
+}
+
+// DeleteProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteProfile(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteProfile(ctx)
+	return err
+}
+
 // Profile converts echo context to params.
 func (w *ServerInterfaceWrapper) Profile(ctx echo.Context) error {
 	var err error
@@ -111,6 +1160,17 @@ func (w *ServerInterfaceWrapper) Profile(ctx echo.Context) error {
 	return err
 }
 
+// PatchProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchProfile(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.PatchProfile(ctx)
+	return err
+}
+
 // UpdateProfile converts echo context to params.
 func (w *ServerInterfaceWrapper) UpdateProfile(ctx echo.Context) error {
 	var err error
@@ -122,6 +1182,120 @@ func (w *ServerInterfaceWrapper) UpdateProfile(ctx echo.Context) error {
 	return err
 }
 
+// UploadAvatar converts echo context to params.
+func (w *ServerInterfaceWrapper) UploadAvatar(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UploadAvatar(ctx)
+	return err
+}
+
+// RequestEmailVerification converts echo context to params.
+func (w *ServerInterfaceWrapper) RequestEmailVerification(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RequestEmailVerification(ctx)
+	return err
+}
+
+// ExportProfile converts echo context to params.
+func (w *ServerInterfaceWrapper) ExportProfile(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ExportProfile(ctx)
+	return err
+}
+
+// DownloadProfileExport converts echo context to params.
+func (w *ServerInterfaceWrapper) DownloadProfileExport(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DownloadProfileExportParams
+	// ------------- Required query parameter "token" -------------
+
+	err = echo.QueryParamsBinder(ctx).MustString("token", &params.Token).BindError()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DownloadProfileExport(ctx, params)
+	return err
+}
+
+// ProfileHistory converts echo context to params.
+func (w *ServerInterfaceWrapper) ProfileHistory(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ProfileHistoryParams
+	// ------------- Optional query parameter "cursor" -------------
+
+	if value := ctx.QueryParam("cursor"); value != "" {
+		params.Cursor = &value
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	if ctx.QueryParams().Has("limit") {
+		var limit Limit
+		err = echo.QueryParamsBinder(ctx).Int("limit", &limit).BindError()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+		}
+		params.Limit = &limit
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ProfileHistory(ctx, params)
+	return err
+}
+
+// ConfirmPhoneChange converts echo context to params.
+func (w *ServerInterfaceWrapper) ConfirmPhoneChange(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ConfirmPhoneChange(ctx)
+	return err
+}
+
+// Preferences converts echo context to params.
+func (w *ServerInterfaceWrapper) Preferences(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Preferences(ctx)
+	return err
+}
+
+// UpdatePreferences converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdatePreferences(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{})
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.UpdatePreferences(ctx)
+	return err
+}
+
 // Register converts echo context to params.
 func (w *ServerInterfaceWrapper) Register(ctx echo.Context) error {
 	var err error
@@ -159,9 +1333,30 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.GET(baseURL+"/admin/users", wrapper.ListUsers)
+	router.GET(baseURL+"/admin/users/export", wrapper.ExportUsers)
+	router.POST(baseURL+"/admin/users/import", wrapper.ImportUsers)
+	router.GET(baseURL+"/admin/users/search", wrapper.SearchUsers)
+	router.GET(baseURL+"/admin/users/:id/history", wrapper.AdminProfileHistory)
+	router.POST(baseURL+"/admin/users/:id/password-reset", wrapper.ForceUserPasswordReset)
+	router.POST(baseURL+"/admin/users/:id/reactivate", wrapper.ReactivateUser)
+	router.POST(baseURL+"/admin/users/:id/sessions/revoke", wrapper.RevokeUserSessions)
+	router.POST(baseURL+"/admin/users/:id/suspend", wrapper.SuspendUser)
+	router.GET(baseURL+"/email/verify", wrapper.VerifyEmail)
 	router.POST(baseURL+"/login", wrapper.Login)
+	router.POST(baseURL+"/phone-changes/cancel", wrapper.CancelPhoneChange)
+	router.DELETE(baseURL+"/profile", wrapper.DeleteProfile)
 	router.GET(baseURL+"/profile", wrapper.Profile)
+	router.PATCH(baseURL+"/profile", wrapper.PatchProfile)
 	router.PUT(baseURL+"/profile", wrapper.UpdateProfile)
+	router.PUT(baseURL+"/profile/avatar", wrapper.UploadAvatar)
+	router.POST(baseURL+"/profile/email/verification", wrapper.RequestEmailVerification)
+	router.GET(baseURL+"/profile/export", wrapper.ExportProfile)
+	router.GET(baseURL+"/profile/export/download", wrapper.DownloadProfileExport)
+	router.GET(baseURL+"/profile/history", wrapper.ProfileHistory)
+	router.POST(baseURL+"/profile/phone/confirm", wrapper.ConfirmPhoneChange)
+	router.GET(baseURL+"/profile/preferences", wrapper.Preferences)
+	router.PUT(baseURL+"/profile/preferences", wrapper.UpdatePreferences)
 	router.POST(baseURL+"/register", wrapper.Register)
 
 }
@@ -169,20 +1364,143 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xWTW/jNhD9K8K0RyNym/RQ3VqkBVy0QOEk6CEwCkYaS8xSJDMcOvAG+u8Lkv6MZTu7",
-	"62R3gb3Z0nDmcd6bN3qC0rTWaNTsoHgCVzbYivjzDyJDY3TWaIfhgSVjkVhifN2ic6KOL3huEQpwTFLX",
-	"0HUDIHzwkrCC4nYVOBksA83dPZYM3QD+NrXUY3zw6Hi3hBXOPRqqemoMwDZGv6B6Chuscx2Ase+qchOB",
-	"1Iw1UjjG5h3q4xBkBcvYvuL/kplKhfvLT71S/2vR4uf0YZ1keaQPyxhr6ZgES7OfliN4TkDaLtgjBG7j",
-	"/jged9nqq3BjK8H4qT05HUfdAByWniTPr8KspvJ3KAjpN8/N+t+fhlrBUMBf/13DIE12yJTewipzw2yh",
-	"C4mlnppwXskSFw1MV4J/RtdR8JJV+HvjkLIrpJksA9YZkpNGQwE/nQ3PhiHSWNTCSijgPD4K/HETseYq",
-	"TFtsoUmtDI2M1I0qKNIwQuoMOv7dVPMQVBrNqGO8sFbJMp7I753Ra+MKv34knEIBP+RrZ8sXtpZv+U28",
-	"c4WuJGk54Y8XKwkr1CyFythkagNOIorJY2Qu6Sxe6ufh8NQgFyruQXnlyxKdm3qV0GXeJUO6GJ6fDMW2",
-	"/ff1SgvPjSH5HqtQ/JcTtuBo8ZFmJC1U5pBmSBmGA2k6fNsKmkMB14102aNUKmuErhTGNq34ZFG7MHRJ",
-	"bpNwNLfJigO6GnukubBqeEXyn2+Dw/TXyCx1nW4WxjeMfIh6azGM9EwoWWVWkGiRkdzXJImFYUJxu22V",
-	"t5NuclAxNfJub9faWephEjze9wgmbY1N2Zze07Y3U09jLgWLdAs2mY/RL/WzvcJLab687p6b0MXw17cr",
-	"fulTaszits60b++SEX/zwt/Hb5/2g3NS/ABD2r/Wx8uI15mCvi/XfQu+CgPBJqNtSG+z3Xs/VQ+7/BJn",
-	"pMMlmQ+/e/uxdW/JhA4mEdNG2882ZLyS5aRLExPyuzgwntTi87jIc2VKoZqg7G7SfQgAAP//IBzQQDYP",
-	"AAA=",
+	"H4sIAAAAAAAC/+x923bcNrLor2Dx7Idkh7ra1p54rfPg2EriOb7o2PLMPhP5qCGyuhsxCTAAKKmTpe+Y",
+	"D5of26tQAAneWi1fZHuil8Rik0ChUPcqFP5IMlVWSoK0Jnn4R7IEnoN2/zw85gv8fw4m06KyQsnkYfLa",
+	"aiUXDKQVdsUsXzA1Z3YJrDagmZBzpUvu3k0Tky2h5DiGXVWQPEyM1UIukqurqzSpuOYlWD/Z41obpYfT",
+	"vaz4bzWwmYRLe5q5l2ZhxkrDuVC1YRVfQOoezYU21v3NLoRdqtoyYbeTNBE42G816FWSJpKXCA0NtxbO",
+	"NHkmSmGHcD3nl6KsSybr8gw0QiQslIYpSaDxBUxNW7gR41lzmPO6sMnD/d00KWnk5OHeLv4lpP8rDcAJ",
+	"aWEBmrBIgzgUPsoyVUv7BApAIF+BqZQ0gD9VWlWgrQD3ogZjlYbTWlpRDJf2TC0WQi6YkMy9wexSGGZF",
+	"Ccx/adwaOc2Hy6RNTx4mObewha8m6QguNfxWCw158vCXHhBvm9fV2a+Q2eQqDet5bbmtzQhlyGLFZjyz",
+	"4hxmARjDMi5ZoRD6lM1yxAXkM6YkGHbBhWVzpWmHak1bBBLR+0tCI+G+1KYCmUOepIkfIHk7WE6aPMpz",
+	"DWYEsiNlLC8Yp99TNqvcg9NM5TBjS27YA5aLhbAGcfxU5gid4AhMd6MyYVf4/5JfPgO5sEtPFANQ3Nr1",
+	"agjK09cv2b29g4OtPcaLasm39pl/lyE0OGU0+L6jt+ivwUTRSgaAjb2u1bmQGWy0CGM1gO29ur+7ex0l",
+	"+e9SQlcXxhY1oxRGO3TEbbbEeXmeC0QcL46ifZjzwkDa539AvjeNKOpsObOKZUsug1Ry1FfA3DIUSO8A",
+	"Knws9Ik850UN22wWEDVjwjANpTqHnJ2tmAGZIy/OZF0Us232dy/UOHPck4cZT+SM0DBL2QzxMOuTHZc5",
+	"m3lkzBjXwAIOt0/kn4vyEJf8rIDkodU1fCAljhBVKeSjjETwbzUYOyaBuVFyiLS/L1ckWnEMYazmVmnG",
+	"M2tSpiFTOoecWbUAuwTtNBy97mbrofSBVx/N0q+XyA6oUUY555aPaOc3r541LGB+q5GqKq3mogBWLZVV",
+	"zC7r8kxyUZihdCu4XsBwzAd7+//654O9fVaJS6DvBhtUQi7qcvjt/oODf/1z/8HBum9NyYsRrXdw/1//",
+	"PLg//WFf6LhRGkhSv5pp5L2pCsXzSYrgDYYbXXomJHd2QxeSNLncWqgt//CXt2crCwPw/HBj4DzmMoPi",
+	"aKkkPHYyahImq96BHLeK4rnotdGplJwLXW4yV+Dr9VO5t8ZmesItP7yslLbTZg9cVkKDOeUj5tzxEliu",
+	"LiTuESuEfMeMVZVhF0q/Q/nLbWsFbWrwoBwJpst/aJgnD5P/tdNa2zvedNtpYfemzlWa1HqERJ90AFRz",
+	"xpkGnq8YuK9TJiyTALlhUrFHtV0qLX53tjgjs34DsiYI1qO4tciC7VSRmkpwMJ6vRs2lw5KL4m+gxVxk",
+	"fL2BCvjqCDWk1+4hbR1Iy5zwAeaGavTyh29qD18EaQeuMdxFa4f8fdZ97r/1C/8ASOORRkHVWunHnhv7",
+	"rh9qTVbybCkkONpzDwA/Ia3OXsCF+5dhJV+xM1RlqLPOasvgUhgr5OJE0gsXoiiYhHPQ3lgio4iVwKWQ",
+	"i+0T+Z9sJtBEEvmMbeGvzNlLzsaXyuLwlVYZGOOsmP9ks7Di7vvCsFIYg3uuNIOysit6vULJdFppmItL",
+	"/MT9HXw6YdwkPDLRJfvmu4P9b/0b8RiF07KDMcraWLbk58D2dpEk9+4Fy5/PLWj23cG+H4Ubc6F0Hg/k",
+	"H0WDHOAYB/cRX5pnFrTpfY3ipYBLNAAHI2RKWi4k4yzjlUBztQBrQaeMB3DRSOTMVJAJXrSzdLbiVJMM",
+	"xwn8P9mZylcOy7xA0kS7VOadbeK62aMwTqYhB2kFL8wAb/h5A32u3DAlmuk0RG1An0plT+eqlm6zpfIB",
+	"CMM0LISxoCFvLaR48HjbLH8HcmzneUGitTZkiHOpnM1VmzCAU3unnq5iZKCDJxU7A65BM/da/EVE0fEr",
+	"OKn/yRGpEygNxixoyYtTx2n4ZS3hsoLMQs4MaGShORdFrYE+wEhDREnzGjmNlxCREmIlQ/28qBFTbRyj",
+	"pa2U7Tl620UE+BhFNH77YncOT2fGU3rqHmgV0ZPBFRLxGdJjJGaYXXLb8F1RqIuAAdotEhODjffap7uH",
+	"wf8SNkgij9IU6TKDogCH6IxslDCRslW8Qy+Pj1iuwLQESB4djY6gtoMRR1ulWMnlinFrUdJ4Dq2lqStU",
+	"npCfos3IT1HyjvEQzuQ0WEO8ucrqEiRuNqISf8OPPWY0ZEqS33qKREDCb/Z0vvUc4Z314Cfwa61xmDaK",
+	"R8vSYFStM2AX3Pg15kxQSAvFstOUw2ljqRtzgVUs41qTTxNB5K0RN5DTSzHK3YOGCJh7HhQ4fXImtF2e",
+	"ouqLv8O/kZjcrx1hlIYlzGtba8BdP4O50sD2vt/dpTEXIHPQ8Xj0JACClKXm/e1w6iXI4NZBjYehx04n",
+	"ju0EafIwtPeQaUD/RzyYfxSrpjE3O4cgcNRpoUg6kSaEy8wZiL11+JgjI5FBH5ML0ZCpKDkRvAThJOFf",
+	"jw5/YlJpdvTip84XEbz0UaQHckDo8u4MCCV6T+0XMZjOmzTid7dvuShBGqEkc1FUT0LOKu2KBXrWYtwZ",
+	"HzjCBW8DLMSxTi40BgfRI45lwOm44NB6sd41KK1izqBaxR8HE6ul5vC6MCz8GDRM/GGjjaa/GlVF9LXn",
+	"1+H3MTN7IbUEPySZ4mQ343sGpMeqi0F0WBsnZBkvChcdbkiwE6zw31I09rQJpeLn/iF+2Tyn18nrOLWa",
+	"S+MkSvx6oB7cMjbXqmTCmkaC0afB2vfCByW7hJ5ppMGA7awn/BRk1VmQ63nqQ8iML7iQJIlnEi5Owyez",
+	"weC1oUElXLQDC9MRtw1QmTmP2cTRVyPxHr/+Gz1pFMAMlespKlcX1kM15wN6DTwsU0VdSi+LROk4osNZ",
+	"DRGXSgPT6gJhw+1jaMAgAO4jxDuQ2oqC4x7YJPIp0iS2nps/SYTgn12LNn7SWqlJGoYOZmX0JDIQkzTp",
+	"2nzNfI5nkjTpmGPN3y3cXRvKZ2Na2HoWTTN83+RI0iQyERCsUcWO3w8Vc/9phMuOGkzSZKjjkjTpKqlu",
+	"qDt62lMcDhmkCJI0icR6+1f7Zl8mk2/bEa8NtF5INn8HIdU8CHvTkU84SUe04IO+vEjSZCAUYgrqMnP3",
+	"F+RExII5j7e/xxHjIQokjWn3PMSo1gVzWvcZoxX4x0iC6PAc9IosGxK/3ngPZoBnhdRZHeA0fuWM87m3",
+	"dOcCitzlFy2U1waYfsS3HWBJG7DmWvMVxVKN4WOB2Of0Q2ub5JAytx0FRxGLm6YdOF76zh450bH1jMtF",
+	"zVHqNMbghuEnn7MJII3FJ6LFfPgGOTwOV/6Cl82yO+a5n26VMthebAdRPBGi/nLQSstMN0HvT07ExLG9",
+	"OZS8cJ/R/5zxMco+P0NRqGn2iTCyHtx18D0tKciL/x0hAA2Iw2iKJlmeJrleneo6DmifKVUAl+7HuirQ",
+	"GILxb4MgGf0RdSn+shEz+gWoiyEv9tAQ4E2bZcVgprFORgDWYEtdDFHVyqaPIENEft33R/VZIbKnOb5d",
+	"CDnCF89E62JpddH4bKLwWVSidiYMWqvA9iK6jzbDseQIS6OfVYjfIe+GCdoJt9dH8LvDoQ1HmxIijcPo",
+	"U+rcDVUXOVqWSjLOcr1iuvbhoGYvwxD9GFQ7VBOOUpoVwhu4jEsGXBcCtMPIIGBqANiM9nnWNefWE9Tb",
+	"6+SJ28B0XbLgmVqIOP3Z03/kQQZLVmn084OHQ97MjAln+80FmAa/uEVKwst58vCXPzoQ0a6/vUr/GAl6",
+	"v716m05F2vtVPTZbQo6Y5zovwLjwVMYNoC4uVkzJDJiwsUu2vS5Dt2XeiWpLVVRVsFUpZ4ZS4vkqTWKX",
+	"YgjNK6gKnvn1h9fYxRLkwO1qkvnkwTgHzX4IZDFUw+R64LH3G71HTc1UawhpSqfcTPD4mqN8PHcUHE50",
+	"g33dj3OyHCfSh+h+u5RRgWBF+I00yYZZU2+XT6VOXU3KEYUeIj66QYVK5DR6X5G4LYrG+DiIL1Lxm8W4",
+	"tVqc1RYM4xpO5HQ5ipMppZAxLHt9VuNtmRQvCs+763asU5WDrNut17iKnaNBMiy5rryjmz5vabqRCM1w",
+	"IWMW14A8uP++4ze7sVY3CWkVe/HjY/L9M1UUvDKYyFgKC8xUPIM2Kh/F06MA/ZgaWzRW3WZb4K3AUeRP",
+	"MP9oLUwn8/++eXmMwSMBO2vZRdt9xoRr8OHhXp3i5hn6KWsBgzixNsbSQed4Kd0JnF1ve9MM12aIjzTM",
+	"QYPMwNyQz1+DRdBMpw73HVSW8Uwrg7LsXGRgKDdbtfN0MrTpicwKgUTQ1KdR2Zpdwipk4N5JdcG4f8Zd",
+	"smmkqoe8lE5da+LCAD17z78Y4OZV5bbZ/+mdANPJ5gcjINgxblgxXp5Zcv0OEDOnqrKnQnYAGsXkYyXD",
+	"ZBoyEOcuvVwq/Nmk6H43hObRrCpXwzmuCKSyYr46rbTK68x2pifBMeoXGsbPVG1dHHEO3NYaDGVTHRs1",
+	"24xpP5HB2qkNZLX2lXw3mdtpt9FZu7az7gaax2GppbCmSw0lWC2yaB+bB6KsQAtejNugI1zjFCRJmCEb",
+	"PwqhzRD9do5wsxb6eFiYxjM7Vor+NO+wWMldEUmbDUzZzKyMhXLmxETAXMlzILuh2TQmrIFiPiqqPag3",
+	"KPTYKIzhXknZLGzVjFHc1luXaNE3mxxtJ1xyDNUmDyP9NQIA2rEuwTQE4m/4OEo7BFyFggxnzjYQxumZ",
+	"UfSoIl8/k8/sbTgVJVvHlSalQk9FNUJYIf3TzOIBD+kHzFNsHpchguvs/dtpav9ZGKv0ak2ckihvY9++",
+	"y0Uj7n10+GKICzq5EWgHX/XnMQLa/ZGIgvuTGdfjJSxgDRKmVx/ZmxtYmclViHlf+wG9NTA/ezV6cRY6",
+	"dfTVTT6jUCXzu8lAb+3ube3uDYyW5Mam61hNY8mFqy3BYgrtXFlv7xcq4wWruHbeLBkMhgnTgWPKBr4B",
+	"YG1aYHhQQkNb6eD9/jSOBXTzmOi5nAGV5FjlU3TbJ/JxcHkj4cUw92CGic4znr0js2WoqTom+hobehPL",
+	"ea2d3JEBkVz1UYwxqg9e7FRRnqJzUhSFyhGJb549fYJ+Q7bsFNn1xPru3s/7/33v/93/x4NHBz/81+O/",
+	"PPn+cPfHvZ/2f76H8HBrQeMs//+X3a3vH239/Nf/8/zF0dbx37b+8faP/YOr/xiTm69c2ErztRXwn9Ud",
+	"ep/gxvX7lq6PZHTR8jECGsOIwti8byoUJpOxuE6QqX+Gb5sdT0QGmtMsJPcxSuAmiE63NGlvp69Tr9bx",
+	"t9Jz/NGj48c/sx1viM3G3ImbS/M76fx1RSQ+gzR9Y0A/E2bNWYFPbvFQPcPmNhqC/LouS65X1yaOaOSp",
+	"hT8Pp926KzaZ0mM136IUBUcnslkvehWuDocqmnH3zwCXzSWDS56FSt0Ox6j6rIh4hjzIgIYbLX5ksUnq",
+	"wZ9a82vgOltOb/fN94Kw+GE7EZY0YsKWYiJP6TM3N3IPfTD7Rt+sOYIxMOpuakndLGg/xfmbHq/pHiMe",
+	"DcOP6fJBSYufzVevJJ2tGO4wguejL68RENpYKvjGozntXz+G/fjr34/DqXCHTvdruzdLays6840KGr8v",
+	"RAaenAnXyfOnx44mhXXGHRIZe00xBzr6YYir97Z3t3fxTVWB5JVIHib33CNn8y0drDtunTsNayzAThzC",
+	"5udcuBgxs6qbljLbjGpd5qKwoJnk2heegctjujNXbF5ru/TZvQrIQkJTN8FX3hhfjhW1C/hlxAjWdjRG",
+	"5Xx+YSl7t1RF3palz76jxIiBihO06Ymc7f5lb39GEgwMm313sP+Xvf3t7e3ZNnuJUF4IQ9EFE8/ZVL+n",
+	"TCykQgp12pxMmrEuAL91OgBEGvxg7JjpH6NjNCTZDnQjThjdTbfhndyzq5sNEZwoxD4GUsMU+HoHss3O",
+	"LG0GUhPk2RAaev/jgoP2EKX46Uz2tOM6BV0sXPodKBphepWOb2rLDztkjyQbvEnNLDCto70udKy9v7ub",
+	"uPIpd7gA/8krKgsQSu786o8qb0ZkA9vKiaweu9ZZBsbM64ItKHvBODXtUHPCLsqm+x8Rqm5l3whIT/3R",
+	"GxJUKcu8jaep0JzguXd78LyR3B/gpGMqY7XWCNSD20US1dCGM0dUShvrOiebYy33iyu+MMHSSY6Xwp/8",
+	"c/I/OJ0mxYQHGEsdXJAV+QIlPR2nT97iJLFG2qGq1A9QTMdhapdHvNDCWpBxdssdcCRL13m4Obf8zJWC",
+	"OEMXp3eFm0i+pcgv+IqBzI2ryVlts6NIDVEupSMUQna95OYdYAazCAHlDqAurrzQXPrSQPeSYhVo41xy",
+	"BIqUTFd1EnwbKU+sN3fOHQ8lVoWQ4EQbruCvr1++YGTWsMr/uM2ekG/ngMFy9tmUkPNyNhZxTRWSOcf3",
+	"8l+7bQempe9jVZY86GvIQ8178OGVzt0BLLeZFGhI8RiZV9Jl5JM+PJEzkWM1/UhpfdrGIvsnOtJwWGGW",
+	"+hMSMzQaWlPQ11q0Jvds2gTw4K9vQDQwddD94salmAo6UNty0fadsXFnbHyIsXEzy+ByS+ZDFTOSt4VL",
+	"u4Pcvva9dWaCsRp42aH1z2ciuM1MvfTBTSGj4c5GmLQR4qysU/BuC51u84r3fa0IIgwGzsv0eXmbLQOl",
+	"0M7c3Lqg4yIuMqPMe5sXXp0iB5q1B7lO5PAkV8pcpT2ZJ86phDy4064+2oQTJJCzQrwDNtsJUguVku3Y",
+	"N0Ia0JZO856Rc5sy4NkSHwhrGDri7hQCNRNKnR6jk+KhmRWNGQCl9hkgtN9RKokcMUVEubEp4tCqXXE/",
+	"u1jSBOFcGlVS5yqIwEZEU+hxhYfrJmRfW0l/nehzOYwfVL7qMcy4BLuuVc9VN9rkC+g+mevVORuxXp4S",
+	"TjvyNJwAxx+YhaIgklW1zRTVkUCgvVsXvS8mDka2Vc/eEvymc7TyW6cymxP5LvT0zfBw5Ld3kntSchNV",
+	"eInhqaO1iYRlF6ADi0L+vlI8sHJLjeGYg5DRxm8guI2L9H+AW/jCCWsUmYhD3hR9A7NaLDQvQ5mUwMMd",
+	"OndBw7M6XzHDpZ2xuZC5YbMf6lyw11xaZdQM3SWqJ28FckigjklMWsRGEpNqvDRlCl0X0hQFcwHcWGYv",
+	"VGizsc4z6EqoNZ7C2hZ9X074qZftucaydC9/GZal9Jt5F3L6NCEn5M1IxGAvBcc4ghe44FIYU1EvFwrl",
+	"n4EJvTo2NRz/EPnVzpIq9N5fCg0kgvu9W/83lAyOvzF/07K3yNfy96Z1Jl9XCHqiTvKmgWh35tDj+nNJ",
+	"hK8lDH1/9/5tAoUHEaTj4q9bIGmwtZYxpfWK0+P6e+4WfGNX1kmk4LRtaTCwxq8l95KO13hIorYtGs7V",
+	"O98dJbQId4/nriaTiromG67Q2YluGxX83JWAcmxPMZR7c6UzQJ1+5L94BdRx4rZE31qv8P3JbKRJ8EbO",
+	"4v2RCp1WhqHr3pYf+Y27ddn1vG21aJViuLHM9xa+E1//VuLLcWdbONoEithInIjq5FyQaFOhpcH15PeV",
+	"nOMC6xhFlWHCmDoO1YNvMWWEksxYjlGlX12/xLSFt1AL0zR5Gsqedvo3VGN2J3PuZM6/icy5v/v97U1+",
+	"3LUiECFto6ev3H4LIoLxdk0NF2wo5wwYI5Q0O2RfrRF2o5ILeY9Cs3TSd0yS4bgUGqGp7qTZnTS7s6C+",
+	"BAGCnMkgcru8KROdeN9YjpAAen/vjiwkKnnCo/5Krnf1hm0+h7LH/3ZnQt0JnTsT6mOaUP6+sq9a/Hnh",
+	"4DbXLeca08lVOu1Qx+Uout6VOPTzoT8bNyZxejkwJxDXSp1+qutTRq/H7woZ2YnDqSbRt86vx9MXGHwW",
+	"Tpluf22EzKi0dU1T7S+Jp0aYhugbeaa3zlAV0bkER8S85DMjnpsoGBNZC73TNz5W8ykUaac/4Jiwda3G",
+	"m/7P/ri/kMn1unb3YwO5UfrIQRfphs+QK2olXUotFTutyOG3mvDYa0jOvhl0Mb/9epi/uztlAxwO/J5x",
+	"2jrX34yYnd/iF+HGiZEQ5DeTWYlvP4MdEdUWfwXiZsllXrRxh0741nGIlyaumnGLsG92qFHbtCvyKOxT",
+	"c/9K/54Rn3CiqkVPt6pobqpBaUdfnEiajF0ImasLuiQD2pMbYQre1jVO+lsn0q3TKDbnOkp5jVXpZP2L",
+	"9D6RrJy8sG9SE/vuZU3lEqItPhW5qRTtdWsZXq/TuQDnCxB8t87ML9rbh5T25CrOChi7i6i5epjszc9h",
+	"GPUJwXetao86dA0hMpe+cPnkmZ+vuwaqtY2I5+Oegq1MmTSUfDKeWKKA0c4i7nnnYmwnaUh+mDGJg+Qg",
+	"7DZr79s+kT5H3rkcezZ67XZKh2QuuM6N70jsrrR2457IhVJ50zWzixHqohTtOuW/RiQcLTbgYiAj9j9e",
+	"XGXi6vIR4njU2gRxk15s0Gm6l3t/1rDFrUuiCC+hWbjHz9cdIqBFxJQ/yqbpeKXdMV1q5kB1d5GFduKz",
+	"w2O+mHUaOnb6Hr0GmSNbYb8wx5l4fdkLtHD8HWZWsQVYNru3e3+GzXCK0Be8ls0lQsjgMr74TM1P5Ozo",
+	"zXHb84iOC/YaIeHgUlmmzkHTWcZMyWCz166fkxnj12qKUz96bd0Ni+r6+E3ShM7GOPhwK6bm9a/tuHfc",
+	"ZPfGoqlvehO0DRy62/YhE9+qLBm3bb5eNvZeBLLMCDGM8XMV+hT15BwuGAzjdDD5OegFMNcrnH3z6sfH",
+	"7L/ufX/wbYil96fyXfydod+23GGV70PoPRB31Ys7AUCMPN0FbX1/9NT7M3QCXM2b7qt0/34JehEabDmI",
+	"ulf6b7NH5MHHynuhnPzSql6QPWN42etwyE1fxmyfyBN5PHYFo7PfwzGH9rqxgWw8kR0kuoslfjo87rdu",
+	"60mjqIX9xq6RQ8qW+/S7G0qmkY75I1SN11j3ypVuNah0MwFKwv6jys+PabeN9XefiubF0lkYvzK6GZrK",
+	"wy4GF6BEXddPZEzkngT7VP7FeKCf1ei8RZ/ySbg9prN1Doy9/VvEQZ++BiH/WPSRz8sJW3sPbg/MH6Ib",
+	"fofqy4Gz/5fbA2dUG4QLFL9qY8Of6SlWXspsbHLUEy7EJ1Sc7KkvvvAnWZsm5R4RupbmRLopZq8O/++b",
+	"p68OT5/+ePocPYb/jcpqVPfSum+qfG941K7TWPY6VUsQvV/k8U4hXqcQt9kj6S5KEd0LM3AQ+jwNYXAm",
+	"bLAg0VB8+bqlRorh7/gXZ97ubWPuJLsoZ+o7BrmI1om0KsThopb4fhYWJhlJENxp7Tut/R5a+05NfkSf",
+	"/CY6MoqG77TXJozqTbq11DnqeFM8XRTv73fH02yWlcpY9oA9Fz/E3aKd0KHXhDmRttYSE6WVFoul7d6O",
+	"Kqxhh//99EemtABpg5K1WlQV5E3LihNZguU5aiPX79O72YaZ32qugeCzy7o8k1wUzlM/uJ+y/QcH7v0H",
+	"e/usEpdQmBOZaVVVIbeI02eAOzKuggvFc39rxDoNXNaFFWiy7CDutxDOxHVJyxQuFP/dotp/d+zvvUQ0",
+	"7fxawSIllO1UvuXShnF3N+4bB+mNCho/Hsl7BK13g7VrUh8O6XsCROll1WfTHg7fn19x7N3i5K/iu5Bd",
+	"kSj3eZavuQIbSQtGCOs6+RcVI/qFTpc7vAbbvZ2kMY06yQClh7kAd853m72sQAq5OJFNhRfNDCYeOG1a",
+	"53HLhJ28MmXkiIjb2qj4MAta4JNl/QaTrdv0+D1avzd1/1y22wvVrfpLm2xfqGZ0aaevobTx5rxqXK0w",
+	"Ox+Qgvd2OpjpnaS/np3XN5Q9dD9T754FSKAOpCFjwLN3C61q6XMFjmeba9pdp3DDlIQTiQYFl+YCtGH7",
+	"u/spq1RRRNcKUpdNpO2Z21ZKEGKbdNTR7pV/PD06ka5pzbm/iGFW62IWDr/O2psmvQcX3qX250oCe/EE",
+	"o08nkqQd3hcp6OZFDZk7suvw/Orw0ZPnh4zwcOa1bzndbfbo06cfMaRAO7G2Mtq9QWEVZI250g0Kk4/s",
+	"8d8UIroqo6GgbuDiFVi92nqEInxMh2RK5q7GEeMAYb+RgHDI5hTzoGy9uR399tOYfVn59coeInFyKKzr",
+	"FxX8CHdl5shZrXViZqchxyl5cxzUPPUIIVHeiR36oWZNXUPTxhFjMikzigl7Ii+UfmeapokI5yO/JSRA",
+	"vVNLIkhYX7g/Wl8QgPYrI6L+Ms5Y/E7XNN6wN2MX5f94etTISq88vFL44g5U3GJhkRddko6EIRtdCmPN",
+	"F14V2GhM7nUABGJdz57D1l2jJTaTPbju+mT96ftk/UlaUk02ohpnrE5qYfrQkX/hFqrqBxOtyWRhZqVf",
+	"VN/Pz3yEonrRxL+/gJwIMtPL46M/VxVtVM8/UkaeMmHDrfleHSOWeicgbjEa0KcevPRWDqr4ewdrvm75",
+	"FLKYG9b7I+eOJ0Svl1ga5qBBZjB9/xl1VKCD4e375HmS2U5FgEqC8dfwGrANeELHl2T2LY12+k9qBLTT",
+	"bKb5SRdEX93p2g/XtRFC4/qRmxSqRDvZv563CUXNVVGoi7i+xNMfhQ9SrFfJitrxVcFtw1ANUIFc19Sc",
+	"dMn242vuAcXeZvHmpszSz1j12GX39vtWtDDQuQTS9+5u5j+Xjnd1B1IhM9Qy/3fKXm0uQlDPhRNg08Z4",
+	"88anYeSxi+Kn9stl761iPZBuh+1Hr26/jv8Jzs/cUf7LPD4yXYtSaYUYJLtVR2jfjsj4VaCBt1fENjg+",
+	"hV9qXfirch/u7Lg71JcKqert1f8MANxswRDptgAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
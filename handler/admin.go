@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+	// searchThreshold is the lowest score of a name search match, low
+	// enough for a typo in a short name
+	searchThreshold = 0.3
+)
+
+func (s *Server) ListUsers(ctx echo.Context, params generated.ListUsersParams) error {
+	session, status, code := s.adminSession(ctx)
+	if status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	var errs fieldErrors
+	before, n := parsePage(params.Cursor, params.Limit, &errs)
+	input := userFilters(params.Q, params.Status, params.CreatedAfter, params.CreatedBefore, params.Verified, &errs)
+	input.Before = int(before)
+	// one more than asked tells whether there is a next page
+	input.Limit = n + 1
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	rows, err := s.Repository.FindUsers(ctx.Request().Context(), input)
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	response := generated.UserListResponse{Users: make([]generated.UserSummary, 0, n)}
+	if len(rows) > n {
+		rows = rows[:n]
+		next := strconv.Itoa(rows[n-1].Id)
+		response.NextCursor = &next
+	}
+	for _, row := range rows {
+		response.Users = append(response.Users, userSummary(row, session.PiiAccess))
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (s *Server) SearchUsers(ctx echo.Context, params generated.SearchUsersParams) error {
+	session, status, code := s.adminSession(ctx)
+	if status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	var errs fieldErrors
+	_, n := parsePage(nil, params.Limit, &errs)
+	// stored names are normalized the same way, see NameRules
+	name := strings.Join(strings.Fields(norm.NFC.String(params.Q)), " ")
+	if length := utf8.RuneCountInString(name); length < 2 || length > 60 {
+		errs.add("q", validationError{code: generated.ErrorCodeInvalid})
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	rows, err := s.Repository.SearchUsers(ctx.Request().Context(), repository.SearchUsersInput{
+		Name:      name,
+		Threshold: searchThreshold,
+		Limit:     n,
+	})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	response := generated.UserSearchResponse{Users: make([]generated.UserMatch, 0, len(rows))}
+	for _, row := range rows {
+		response.Users = append(response.Users, generated.UserMatch{
+			User:  userSummary(row.FindUsersOutput, session.PiiAccess),
+			Score: row.Score,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// userFilters narrows the users down by the filters shared by listing and
+// exporting them.
+func userFilters(q *string, status *generated.AccountStatus, createdAfter, createdBefore *time.Time, verified *bool, errs *fieldErrors) repository.FindUsersInput {
+	input := repository.FindUsersInput{
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Verified:      verified,
+	}
+	if nil != status {
+		switch *status {
+		case generated.AccountStatusActive, generated.AccountStatusSuspended, generated.AccountStatusDeleted:
+			input.Status = string(*status)
+		default:
+			errs.add("status", validationError{code: generated.ErrorCodeInvalid})
+		}
+	}
+	if nil != createdAfter && nil != createdBefore && !createdAfter.Before(*createdBefore) {
+		errs.add("created_before", validationError{code: generated.ErrorCodeInvalid})
+	}
+	if nil != q {
+		input.PhonePrefix, input.NamePrefix = searchPrefix(*q)
+	}
+
+	return input
+}
+
+// userSummary is the row as listed to an administrator, with the phone number
+// and email address masked unless piiAccess is set.
+func userSummary(row repository.FindUsersOutput, piiAccess bool) generated.UserSummary {
+	if !piiAccess {
+		row = maskUser(row)
+	}
+	summary := generated.UserSummary{
+		Id:            row.PublicId,
+		FullName:      row.FullName,
+		Phone:         row.Phone,
+		Email:         row.Email,
+		EmailVerified: nil != row.EmailVerifiedAt,
+		Status:        generated.AccountStatus(row.Status),
+		Admin:         row.Admin,
+		CreatedAt:     row.CreatedAt.UTC(),
+	}
+	if nil != row.DeletedAt {
+		deletedAt := row.DeletedAt.UTC()
+		summary.DeletedAt = &deletedAt
+	}
+
+	return summary
+}
+
+// searchPrefix tells a phone number from a name, a phone number only holds
+// digits, a leading + and separators. Both are normalized the way they are
+// stored.
+func searchPrefix(q string) (phone string, name string) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return "", ""
+	}
+
+	digits := false
+	for i, r := range q {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+		case r == '+' && i == 0, r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", strings.Join(strings.Fields(norm.NFC.String(q)), " ")
+		}
+	}
+	if !digits {
+		return "", ""
+	}
+
+	return NormalizePhone(q), ""
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// maxReasonLength keep in sync with AdminActionRequest in api.yml.
+const maxReasonLength = 500
+
+func (s *Server) SuspendUser(ctx echo.Context, id generated.PublicId) error {
+	return s.adminAction(ctx, id, func(input repository.AdminActionInput) error {
+		return s.Repository.SetStatus(ctx.Request().Context(), repository.SetStatusInput{
+			AdminActionInput: input,
+			Status:           repository.StatusSuspended,
+		})
+	})
+}
+
+func (s *Server) ReactivateUser(ctx echo.Context, id generated.PublicId) error {
+	return s.adminAction(ctx, id, func(input repository.AdminActionInput) error {
+		return s.Repository.SetStatus(ctx.Request().Context(), repository.SetStatusInput{
+			AdminActionInput: input,
+			Status:           repository.StatusActive,
+		})
+	})
+}
+
+func (s *Server) ForceUserPasswordReset(ctx echo.Context, id generated.PublicId) error {
+	return s.adminAction(ctx, id, func(input repository.AdminActionInput) error {
+		return s.Repository.RequirePasswordReset(ctx.Request().Context(), input)
+	})
+}
+
+func (s *Server) RevokeUserSessions(ctx echo.Context, id generated.PublicId) error {
+	return s.adminAction(ctx, id, func(input repository.AdminActionInput) error {
+		return s.Repository.RevokeSessions(ctx.Request().Context(), input)
+	})
+}
+
+// adminAction runs the action of the administrator calling on the account
+// and drops its cached session, so the action takes effect immediately on
+// this instance.
+func (s *Server) adminAction(ctx echo.Context, id generated.PublicId, action func(repository.AdminActionInput) error) error {
+	if status, code := s.requireAdmin(ctx); status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	var request generated.AdminActionRequest
+	if err := ctx.Bind(&request); nil != err {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	var errs fieldErrors
+	request.Reason = strings.TrimSpace(request.Reason)
+	errs.add("reason", validateReason(request.Reason))
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	admin := ctx.Get("user").(map[string]any)["sub"].(string)
+	if err := action(repository.AdminActionInput{
+		PublicId: id,
+		Admin:    requestActor(ctx, admin),
+		Reason:   request.Reason,
+	}); nil != err {
+		switch err {
+		case sql.ErrNoRows:
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeUserNotFound)
+		case repository.ErrStatusTransition:
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodeStatusTransition)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	s.sessions.forget(id)
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func validateReason(reason string) error {
+	switch {
+	case reason == "":
+		return errRequired
+	case utf8.RuneCountInString(reason) > maxReasonLength:
+		return validationError{code: generated.ErrorCodeInvalid}
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestServer_SuspendUser(t *testing.T) {
+	t.Parallel()
+
+	const target = "01H2X3Y4Z5A6B7C8D9E0F1G2H3"
+	admin := repository.Actor{PublicId: "admin", IP: "192.0.2.1"}
+
+	type Case struct {
+		name     string
+		reason   string
+		err      error
+		expected int
+		code     generated.ErrorCode
+		// rejected before the repository is called
+		invalid bool
+	}
+	var testCases = []Case{
+		{name: "suspended", reason: " fraud report ", expected: http.StatusNoContent},
+		{name: "unknown user", reason: "fraud report", err: sql.ErrNoRows, expected: http.StatusNotFound, code: generated.ErrorCodeUserNotFound},
+		{name: "not active", reason: "fraud report", err: repository.ErrStatusTransition, expected: http.StatusConflict, code: generated.ErrorCodeStatusTransition},
+		{name: "missing reason", reason: "  ", expected: http.StatusBadRequest, invalid: true},
+		{name: "too long reason", reason: strings.Repeat("a", maxReasonLength+1), expected: http.StatusBadRequest, invalid: true},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().
+				FindSession(gomock.Any(), repository.FindSessionInput{Subject: "admin"}).
+				Return(repository.FindSessionOutput{PublicId: "admin", Status: repository.StatusActive, Admin: true}, nil)
+			if !cases.invalid {
+				repo.EXPECT().SetStatus(gomock.Any(), repository.SetStatusInput{
+					AdminActionInput: repository.AdminActionInput{PublicId: target, Admin: admin, Reason: "fraud report"},
+					Status:           repository.StatusSuspended,
+				}).Return(cases.err)
+			}
+
+			ctx, rec := newAuthContext(e, http.MethodPost, generated.AdminActionRequest{Reason: cases.reason}, "admin")
+			assert.NoError(t, s.SuspendUser(ctx, target))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.code != "" {
+				var response generated.ErrorResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, cases.code, response.Code)
+			}
+		})
+	}
+}
+
+func TestServer_AdminActionsRequireAdmin(t *testing.T) {
+	t.Parallel()
+
+	actions := map[string]func(s *Server, ctx echo.Context) error{
+		"suspend":         func(s *Server, ctx echo.Context) error { return s.SuspendUser(ctx, "target") },
+		"reactivate":      func(s *Server, ctx echo.Context) error { return s.ReactivateUser(ctx, "target") },
+		"password reset":  func(s *Server, ctx echo.Context) error { return s.ForceUserPasswordReset(ctx, "target") },
+		"revoke sessions": func(s *Server, ctx echo.Context) error { return s.RevokeUserSessions(ctx, "target") },
+	}
+
+	for name, action := range actions {
+		action := action
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().
+				FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).
+				Return(repository.FindSessionOutput{PublicId: "slug", Status: repository.StatusActive}, nil)
+
+			ctx, rec := newAuthContext(e, http.MethodPost, generated.AdminActionRequest{Reason: "reason"}, "slug")
+			assert.NoError(t, action(s, ctx))
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		})
+	}
+}
+
+func TestServer_RevokeUserSessions(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+	s.sessions.put("target", cachedSession{session: repository.FindSessionOutput{PublicId: "target"}})
+
+	repo.EXPECT().
+		FindSession(gomock.Any(), repository.FindSessionInput{Subject: "admin"}).
+		Return(repository.FindSessionOutput{PublicId: "admin", Status: repository.StatusActive, Admin: true}, nil)
+	repo.EXPECT().RevokeSessions(gomock.Any(), repository.AdminActionInput{
+		PublicId: "target",
+		Admin:    repository.Actor{PublicId: "admin", IP: "192.0.2.1"},
+		Reason:   "lost phone",
+	}).Return(nil)
+
+	ctx, rec := newAuthContext(e, http.MethodPost, generated.AdminActionRequest{Reason: "lost phone"}, "admin")
+	assert.NoError(t, s.RevokeUserSessions(ctx, "target"))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, ok := s.sessions.get("target")
+	assert.False(t, ok)
+}
+
+func TestServer_LoginPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	password, _ := bcrypt.GenerateFromPassword([]byte("T3stv@lid"), bcrypt.MinCost)
+
+	type Case struct {
+		name        string
+		newPassword string
+		reset       bool
+		expected    int
+		code        generated.ErrorCode
+	}
+	var testCases = []Case{
+		{name: "without a new password", expected: http.StatusForbidden, code: generated.ErrorCodePasswordResetRequired},
+		{name: "weak new password", newPassword: "weak", expected: http.StatusBadRequest},
+		{name: "reused password", newPassword: "T3stv@lid", expected: http.StatusBadRequest, code: generated.ErrorCodePasswordReused},
+		{name: "new password", newPassword: "N3wv@lid", reset: true, expected: http.StatusOK},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().FindByPhone(gomock.Any(), gomock.Any()).Return(repository.FindByPhoneOutput{
+				Id:                    1,
+				PublicId:              "slug",
+				Phone:                 "+6281234567890",
+				Password:              string(password),
+				Status:                repository.StatusActive,
+				PasswordResetRequired: true,
+			}, nil)
+			if cases.reset {
+				repo.EXPECT().
+					ResetPassword(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, input repository.ResetPasswordInput) (repository.ResetPasswordOutput, error) {
+						assert.Equal(t, 1, input.Id)
+						assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(input.Password), []byte(cases.newPassword)))
+						return repository.ResetPasswordOutput{SessionVersion: 2}, nil
+					})
+			}
+
+			ctx, rec := newAuthContext(e, http.MethodPost, generated.LoginRequest{
+				Phone:       "+6281234567890",
+				Password:    "T3stv@lid",
+				NewPassword: cases.newPassword,
+			}, "")
+			assert.NoError(t, s.Login(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.code != "" {
+				var response generated.ErrorResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, cases.code, response.Code)
+			}
+		})
+	}
+}
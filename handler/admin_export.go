@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	mimeNDJSON = "application/x-ndjson"
+	// exportFlushRows is how many users are written between flushes, so the
+	// client receives them while the export goes on
+	exportFlushRows = 500
+)
+
+// userColumns are the columns of a user export in their default order, keep
+// them in sync with the columns parameter in api.yml.
+var userColumns = []string{"id", "full_name", "phone", "email", "email_verified", "status", "admin", "created_at", "deleted_at"}
+
+func (s *Server) ExportUsers(ctx echo.Context, params generated.ExportUsersParams) error {
+	session, status, code := s.adminSession(ctx)
+	if status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	var errs fieldErrors
+	format := generated.Csv
+	if nil != params.Format {
+		switch *params.Format {
+		case generated.Csv, generated.Ndjson:
+			format = *params.Format
+		default:
+			errs.add("format", validationError{code: generated.ErrorCodeInvalid})
+		}
+	}
+	columns := userColumns
+	if nil != params.Columns {
+		columns = parseColumns(*params.Columns, &errs)
+	}
+	input := userFilters(params.Q, params.Status, params.CreatedAfter, params.CreatedBefore, params.Verified, &errs)
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	res := ctx.Response()
+	out := bufio.NewWriter(res)
+	var w userWriter = &csvUserWriter{columns: columns, w: csv.NewWriter(out)}
+	if format == generated.Ndjson {
+		w = &ndjsonUserWriter{columns: columns, w: out}
+	}
+
+	// the response starts with the first user, a query failing before it is
+	// still answered with an error
+	var written int
+	err := s.Repository.StreamUsers(ctx.Request().Context(), input, func(row repository.FindUsersOutput) error {
+		if written == 0 {
+			startUserExport(res, format)
+			if err := w.header(); nil != err {
+				return err
+			}
+		}
+		if !session.PiiAccess {
+			row = maskUser(row)
+		}
+		if err := w.write(row); nil != err {
+			return err
+		}
+		if written++; written%exportFlushRows == 0 {
+			if err := w.flush(); nil != err {
+				return err
+			}
+			res.Flush()
+		}
+
+		return nil
+	})
+	if nil != err {
+		if written == 0 {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+
+		// too late for an error response, echo logs it
+		return err
+	}
+	if written == 0 {
+		startUserExport(res, format)
+		if err = w.header(); nil != err {
+			return err
+		}
+	}
+
+	return w.flush()
+}
+
+func startUserExport(res *echo.Response, format generated.ExportUsersParamsFormat) {
+	contentType := "text/csv; charset=utf-8"
+	if format == generated.Ndjson {
+		contentType = mimeNDJSON
+	}
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="users-`+time.Now().UTC().Format("20060102")+`.`+string(format)+`"`)
+	res.WriteHeader(http.StatusOK)
+}
+
+// parseColumns returns the known columns listed in value, in its order.
+func parseColumns(value string, errs *fieldErrors) []string {
+	var (
+		columns []string
+		seen    = map[string]bool{}
+	)
+	for _, column := range strings.Split(value, ",") {
+		column = strings.TrimSpace(column)
+		if seen[column] || !knownColumn(column) {
+			errs.add("columns", validationError{code: generated.ErrorCodeInvalid})
+			return nil
+		}
+		seen[column] = true
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+func knownColumn(column string) bool {
+	for _, c := range userColumns {
+		if c == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskUser masks the phone number and email address of the user for
+// administrators without access to personal data.
+func maskUser(row repository.FindUsersOutput) repository.FindUsersOutput {
+	row.Phone = maskPhone(row.Phone)
+	if nil != row.Email {
+		masked := maskEmail(*row.Email)
+		row.Email = &masked
+	}
+
+	return row
+}
+
+// maskPhone keeps the country code, the start of the operator prefix and the
+// last three digits, enough to tell numbers apart without reaching anyone.
+func maskPhone(phone string) string {
+	if len(phone) < 10 {
+		return strings.Repeat("*", len(phone))
+	}
+
+	return phone[:5] + strings.Repeat("*", len(phone)-8) + phone[len(phone)-3:]
+}
+
+// maskEmail keeps the first character and the domain of the address.
+func maskEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 1 {
+		return "***"
+	}
+	_, size := utf8.DecodeRuneInString(email)
+
+	return email[:size] + "***" + email[at:]
+}
+
+// userWriter writes the users of an export in one format, buffered until
+// flushed.
+type userWriter interface {
+	header() error
+	write(row repository.FindUsersOutput) error
+	flush() error
+}
+
+// userValue is the value of column for the user, nil for an empty one.
+func userValue(column string, row repository.FindUsersOutput) any {
+	switch column {
+	case "id":
+		return row.PublicId
+	case "full_name":
+		return row.FullName
+	case "phone":
+		return row.Phone
+	case "email":
+		if nil == row.Email {
+			return nil
+		}
+		return *row.Email
+	case "email_verified":
+		return nil != row.EmailVerifiedAt
+	case "status":
+		return row.Status
+	case "admin":
+		return row.Admin
+	case "created_at":
+		return row.CreatedAt.UTC()
+	case "deleted_at":
+		if nil == row.DeletedAt {
+			return nil
+		}
+		return row.DeletedAt.UTC()
+	}
+
+	return nil
+}
+
+type csvUserWriter struct {
+	columns []string
+	w       *csv.Writer
+	record  []string
+}
+
+func (w *csvUserWriter) header() error {
+	return w.w.Write(w.columns)
+}
+
+func (w *csvUserWriter) write(row repository.FindUsersOutput) error {
+	w.record = w.record[:0]
+	for _, column := range w.columns {
+		var field string
+		switch v := userValue(column, row).(type) {
+		case string:
+			field = v
+		case bool:
+			field = strconv.FormatBool(v)
+		case time.Time:
+			field = v.Format(time.RFC3339)
+		}
+		w.record = append(w.record, field)
+	}
+
+	return w.w.Write(w.record)
+}
+
+func (w *csvUserWriter) flush() error {
+	w.w.Flush()
+
+	return w.w.Error()
+}
+
+type ndjsonUserWriter struct {
+	columns []string
+	w       *bufio.Writer
+}
+
+func (w *ndjsonUserWriter) header() error {
+	return nil
+}
+
+// write keeps the keys in the order of the columns, unlike a map.
+func (w *ndjsonUserWriter) write(row repository.FindUsersOutput) error {
+	_ = w.w.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			_ = w.w.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		value, err := json.Marshal(userValue(column, row))
+		if nil != err {
+			return err
+		}
+		_, _ = w.w.Write(key)
+		_ = w.w.WriteByte(':')
+		_, _ = w.w.Write(value)
+	}
+	// a failed write sticks, it is returned by every later one
+	_, err := w.w.WriteString("}\n")
+
+	return err
+}
+
+func (w *ndjsonUserWriter) flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ExportUsers(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2023, time.June, 1, 7, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	email := "budi@example.com"
+	rows := []repository.FindUsersOutput{
+		{PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3", FullName: "Budi, S.T.", Phone: "+6281234567890", Email: &email, Status: "active", CreatedAt: createdAt},
+		{PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H4", FullName: "Siti", Phone: "+6281234567891", Status: "suspended", CreatedAt: createdAt},
+	}
+	csvFormat, ndjsonFormat := generated.Csv, generated.Ndjson
+	someColumns, unknownColumn, verified := "full_name, phone,email,created_at", "id,password", true
+
+	type Case struct {
+		name        string
+		params      generated.ExportUsersParams
+		pii         bool
+		input       repository.FindUsersInput
+		err         error
+		expected    int
+		contentType string
+		body        string
+	}
+	var testCases = []Case{
+		{
+			name:        "csv with masked contact details",
+			params:      generated.ExportUsersParams{Format: &csvFormat, Columns: &someColumns, Verified: &verified},
+			input:       repository.FindUsersInput{Verified: &verified},
+			expected:    http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			body: "full_name,phone,email,created_at\n" +
+				"\"Budi, S.T.\",+6281******890,b***@example.com,2023-06-01T00:00:00Z\n" +
+				"Siti,+6281******891,,2023-06-01T00:00:00Z\n",
+		},
+		{
+			name:        "ndjson with access to personal data",
+			params:      generated.ExportUsersParams{Format: &ndjsonFormat},
+			pii:         true,
+			expected:    http.StatusOK,
+			contentType: mimeNDJSON,
+			body: `{"id":"01H2X3Y4Z5A6B7C8D9E0F1G2H3","full_name":"Budi, S.T.","phone":"+6281234567890","email":"budi@example.com","email_verified":false,"status":"active","admin":false,"created_at":"2023-06-01T00:00:00Z","deleted_at":null}` + "\n" +
+				`{"id":"01H2X3Y4Z5A6B7C8D9E0F1G2H4","full_name":"Siti","phone":"+6281234567891","email":null,"email_verified":false,"status":"suspended","admin":false,"created_at":"2023-06-01T00:00:00Z","deleted_at":null}` + "\n",
+		},
+		{
+			name:     "unknown column",
+			params:   generated.ExportUsersParams{Columns: &unknownColumn},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "query failing before the first user",
+			err:      errors.New("connection refused"),
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().
+				FindSession(gomock.Any(), repository.FindSessionInput{Subject: "admin"}).
+				Return(repository.FindSessionOutput{PublicId: "admin", Status: repository.StatusActive, Admin: true, PiiAccess: cases.pii}, nil)
+			if cases.expected != http.StatusBadRequest {
+				repo.EXPECT().
+					StreamUsers(gomock.Any(), cases.input, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ repository.FindUsersInput, each func(repository.FindUsersOutput) error) error {
+						if nil != cases.err {
+							return cases.err
+						}
+						for _, row := range rows {
+							if err := each(row); nil != err {
+								return err
+							}
+						}
+						return nil
+					})
+			}
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "admin")
+			assert.NoError(t, s.ExportUsers(ctx, cases.params))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.expected == http.StatusOK {
+				assert.Equal(t, cases.contentType, rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, cases.body, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_ExportUsersStrict(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+	repo.EXPECT().
+		FindSession(gomock.Any(), gomock.Any()).
+		Return(repository.FindSessionOutput{PublicId: "admin", Status: repository.StatusActive, Admin: true}, nil)
+	repo.EXPECT().
+		StreamUsers(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ repository.FindUsersInput, each func(repository.FindUsersOutput) error) error {
+			for i := 0; i <= exportFlushRows; i++ {
+				if err := each(repository.FindUsersOutput{FullName: "Budi", Phone: "+6281234567890", Status: "active"}); nil != err {
+					return err
+				}
+			}
+			return nil
+		})
+
+	// strict validation buffers responses, streamed ones have to pass by it
+	e := echo.New()
+	e.Use(OpenAPIMiddleware(OpenAPIMiddlewareOptions{Strict: true}))
+	e.GET("/admin/users/export", func(c echo.Context) error {
+		c.Set("user", map[string]any{"sub": "admin"})
+		return s.ExportUsers(c, generated.ExportUsersParams{})
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/users/export", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, exportFlushRows+2, strings.Count(rec.Body.String(), "\n"), "the header and every user")
+}
+
+func TestMaskPhone(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "+6281******890", maskPhone("+6281234567890"))
+	assert.Equal(t, "+6281*****789", maskPhone("+628123456789"))
+	assert.Equal(t, "*****", maskPhone("+6281"))
+}
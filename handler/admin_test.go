@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ListUsers(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	after, before := createdAt, createdAt.Add(24*time.Hour)
+	verified, phone, name, limit := true, "0812-34", "  budi   santoso ", 2
+	suspended, unknown := generated.AccountStatusSuspended, generated.AccountStatus("banned")
+	rows := []repository.FindUsersOutput{
+		{Id: 9, PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3", FullName: "Budi", Phone: "+6281234567890", Status: repository.StatusActive, CreatedAt: createdAt, EmailVerifiedAt: &createdAt},
+		{Id: 7, PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H4", FullName: "Budi", Phone: "+6281234567891", Status: repository.StatusActive, CreatedAt: createdAt},
+		{Id: 3, PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H5", FullName: "Budi", Phone: "+6281234567892", Status: repository.StatusActive, CreatedAt: createdAt},
+	}
+
+	type Case struct {
+		name     string
+		params   generated.ListUsersParams
+		input    *repository.FindUsersInput
+		expected int
+		users    int
+		next     *string
+		// piiAccess shows the phone numbers unmasked
+		piiAccess bool
+	}
+	var testCases = []Case{
+		{
+			name:     "first page",
+			params:   generated.ListUsersParams{Limit: &limit},
+			input:    &repository.FindUsersInput{Limit: 3},
+			expected: http.StatusOK,
+			users:    2,
+			next:     func() *string { next := "7"; return &next }(),
+		},
+		{
+			name: "filters",
+			params: generated.ListUsersParams{
+				Status:        &suspended,
+				CreatedAfter:  &after,
+				CreatedBefore: &before,
+				Verified:      &verified,
+			},
+			input: &repository.FindUsersInput{
+				Status:        repository.StatusSuspended,
+				CreatedAfter:  &after,
+				CreatedBefore: &before,
+				Verified:      &verified,
+				Limit:         21,
+			},
+			expected:  http.StatusOK,
+			users:     3,
+			piiAccess: true,
+		},
+		{
+			name:     "search by the start of a phone number",
+			params:   generated.ListUsersParams{Q: &phone},
+			input:    &repository.FindUsersInput{PhonePrefix: "+6281234", Limit: 21},
+			expected: http.StatusOK,
+			users:    3,
+		},
+		{
+			name:     "search by the start of a name",
+			params:   generated.ListUsersParams{Q: &name},
+			input:    &repository.FindUsersInput{NamePrefix: "budi santoso", Limit: 21},
+			expected: http.StatusOK,
+			users:    3,
+		},
+		{
+			name:     "unknown status",
+			params:   generated.ListUsersParams{Status: &unknown},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "empty registration range",
+			params:   generated.ListUsersParams{CreatedAfter: &before, CreatedBefore: &after},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().
+				FindSession(gomock.Any(), gomock.Any()).
+				Return(repository.FindSessionOutput{PublicId: "slug", Admin: true, PiiAccess: cases.piiAccess}, nil)
+			if nil != cases.input {
+				repo.EXPECT().FindUsers(gomock.Any(), *cases.input).Return(rows, nil)
+			}
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+			assert.NoError(t, s.ListUsers(ctx, cases.params))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusOK {
+				var response generated.UserListResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Len(t, response.Users, cases.users)
+				assert.Equal(t, cases.next, response.NextCursor)
+				assert.True(t, response.Users[0].EmailVerified)
+				assert.False(t, response.Users[1].EmailVerified)
+				if cases.piiAccess {
+					assert.Equal(t, "+6281234567890", response.Users[0].Phone)
+				} else {
+					assert.Equal(t, "+6281******890", response.Users[0].Phone)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ListUsersRequiresAdmin(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+	repo.EXPECT().FindSession(gomock.Any(), gomock.Any()).Return(repository.FindSessionOutput{PublicId: "slug"}, nil)
+
+	ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+	assert.NoError(t, s.ListUsers(ctx, generated.ListUsersParams{}))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestServer_SearchUsers(t *testing.T) {
+	t.Parallel()
+
+	tooMany := maxPageLimit + 1
+	email := "budi@example.com"
+	type Case struct {
+		name     string
+		params   generated.SearchUsersParams
+		input    repository.SearchUsersInput
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name:     "normalized name",
+			params:   generated.SearchUsersParams{Q: "  budy   sant "},
+			input:    repository.SearchUsersInput{Name: "budy sant", Threshold: searchThreshold, Limit: defaultPageLimit},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "single letter",
+			params:   generated.SearchUsersParams{Q: " b "},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "limit above the maximum",
+			params:   generated.SearchUsersParams{Q: "budi", Limit: &tooMany},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().
+				FindSession(gomock.Any(), gomock.Any()).
+				Return(repository.FindSessionOutput{PublicId: "admin", Admin: true}, nil)
+			if cases.expected == http.StatusOK {
+				repo.EXPECT().SearchUsers(gomock.Any(), cases.input).Return([]repository.SearchUsersOutput{
+					{FindUsersOutput: repository.FindUsersOutput{
+						PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3",
+						FullName: "Budi Santoso",
+						Phone:    "+6281234567890",
+						Email:    &email,
+						Status:   "active",
+					}, Score: 0.58},
+				}, nil)
+			}
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "admin")
+			assert.NoError(t, s.SearchUsers(ctx, cases.params))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.expected == http.StatusOK {
+				var response generated.UserSearchResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Len(t, response.Users, 1)
+				assert.Equal(t, "Budi Santoso", response.Users[0].User.FullName)
+				assert.Equal(t, 0.58, response.Users[0].Score)
+				assert.Equal(t, "+6281******890", response.Users[0].User.Phone, "masked without access to personal data")
+				assert.Equal(t, "b***@example.com", *response.Users[0].User.Email)
+			}
+		})
+	}
+}
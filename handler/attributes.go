@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"golang.org/x/text/language"
+)
+
+const (
+	birthDateLayout = "2006-01-02"
+	maxEmailLength  = 254
+)
+
+// minBirthDate bounds the date of birth, anything earlier is a typo.
+var minBirthDate = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	postalCodeID = regexp.MustCompile(`^[0-9]{5}$`)
+	postalCode   = regexp.MustCompile(`^[0-9A-Z][0-9A-Z -]{1,8}[0-9A-Z]$`)
+)
+
+// attributesRequest holds the optional profile attributes of a write request,
+// nil fields were left out of the request.
+type attributesRequest struct {
+	Email     *string
+	BirthDate *string
+	Gender    *generated.Gender
+	Address   *generated.Address
+}
+
+// normalize validates the attributes present in the request and returns them
+// in their stored form, failures are collected in errs.
+func (r attributesRequest) normalize(errs *fieldErrors) repository.ProfileAttributes {
+	var (
+		attrs repository.ProfileAttributes
+		err   error
+	)
+	if nil != r.Email {
+		var email string
+		email, err = normalizeEmail(*r.Email)
+		errs.add("email", err)
+		attrs.Email = &email
+	}
+	if nil != r.BirthDate {
+		var date time.Time
+		date, err = parseBirthDate(*r.BirthDate, time.Now())
+		errs.add("birth_date", err)
+		attrs.BirthDate = &date
+	}
+	if nil != r.Gender {
+		gender := string(*r.Gender)
+		errs.add("gender", validateGender(*r.Gender))
+		attrs.Gender = &gender
+	}
+	if nil != r.Address {
+		address := normalizeAddress(errs, *r.Address)
+		attrs.Address = &address
+	}
+
+	return attrs
+}
+
+// mergeAttributes overrides the current attributes with the non nil ones of
+// update.
+func mergeAttributes(current, update repository.ProfileAttributes) repository.ProfileAttributes {
+	if nil != update.Email {
+		current.Email = update.Email
+	}
+	if nil != update.BirthDate {
+		current.BirthDate = update.BirthDate
+	}
+	if nil != update.Gender {
+		current.Gender = update.Gender
+	}
+	if nil != update.Address {
+		current.Address = update.Address
+	}
+
+	return current
+}
+
+// addressMemberOptional tells the address members apart from the optional
+// ones, which may be removed.
+var addressMemberOptional = map[string]bool{
+	"street":      false,
+	"city":        false,
+	"province":    true,
+	"postal_code": false,
+	"country":     false,
+}
+
+// addressPatch holds the members of the address in a merge patch, a nil value
+// removes the member.
+type addressPatch map[string]*string
+
+// decodeAddressPatch decodes the address member of a merge patch, failures
+// are collected in errs per address member.
+func decodeAddressPatch(value json.RawMessage, errs *fieldErrors) addressPatch {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(value, &members); nil != err {
+		errs.add("address", err)
+
+		return nil
+	}
+
+	patch := addressPatch{}
+	for _, member := range sortedKeys(members) {
+		var err error
+		optional, known := addressMemberOptional[member]
+		switch value := members[member]; {
+		case !known:
+			err = validationError{code: generated.ErrorCodeInvalid}
+		case string(value) == "null" && !optional:
+			err = errRequired
+		case string(value) == "null":
+			patch[member] = nil
+		default:
+			var line string
+			err = json.Unmarshal(value, &line)
+			patch[member] = &line
+		}
+		errs.add("address."+member, err)
+	}
+
+	return patch
+}
+
+// apply merges the patch into the stored address, nil when there is none,
+// and returns the result to be normalized.
+func (p addressPatch) apply(stored *repository.Address) generated.Address {
+	var address generated.Address
+	if nil != stored {
+		address = generated.Address{
+			Street:     stored.Street,
+			City:       stored.City,
+			PostalCode: stored.PostalCode,
+			Country:    stored.Country,
+		}
+		if stored.Province != "" {
+			address.Province = &stored.Province
+		}
+	}
+	for member, value := range p {
+		switch member {
+		case "street":
+			address.Street = *value
+		case "city":
+			address.City = *value
+		case "province":
+			address.Province = value
+		case "postal_code":
+			address.PostalCode = *value
+		case "country":
+			address.Country = *value
+		}
+	}
+
+	return address
+}
+
+// isAttribute reports whether the request member is an optional attribute.
+func isAttribute(member string) bool {
+	switch member {
+	case "email", "birth_date", "gender", "address":
+		return true
+	}
+
+	return false
+}
+
+// removeAttribute clears the attribute named by its request member.
+func removeAttribute(attrs *repository.ProfileAttributes, member string) {
+	switch member {
+	case "email":
+		attrs.Email = nil
+	case "birth_date":
+		attrs.BirthDate = nil
+	case "gender":
+		attrs.Gender = nil
+	case "address":
+		attrs.Address = nil
+	}
+}
+
+// normalizeEmail accepts a bare address, without display name or comments,
+// and lower cases its domain. The local part is case sensitive per RFC 5321.
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return email, errRequired
+	}
+	if len(email) > maxEmailLength {
+		return email, validationError{code: generated.ErrorCodeTooLong}
+	}
+
+	address, err := mail.ParseAddress(email)
+	if nil != err || address.Name != "" || address.Address != email {
+		return email, validationError{code: generated.ErrorCodeEmailInvalid}
+	}
+	at := strings.LastIndexByte(email, '@')
+
+	return email[:at] + strings.ToLower(email[at:]), nil
+}
+
+// parseBirthDate parses a full-date of RFC 3339 which lies between
+// minBirthDate and now.
+func parseBirthDate(date string, now time.Time) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, errRequired
+	}
+
+	t, err := time.Parse(birthDateLayout, date)
+	if nil != err || t.Before(minBirthDate) || t.After(now) {
+		return time.Time{}, validationError{code: generated.ErrorCodeBirthDateInvalid}
+	}
+
+	return t, nil
+}
+
+func validateGender(gender generated.Gender) error {
+	switch gender {
+	case generated.GenderFemale, generated.GenderMale, generated.GenderOther:
+		return nil
+	case "":
+		return errRequired
+	default:
+		return validationError{code: generated.ErrorCodeGenderInvalid}
+	}
+}
+
+// normalizeAddress trims the address lines, upper cases the country and the
+// postal code, and validates the postal code against the country.
+func normalizeAddress(errs *fieldErrors, a generated.Address) repository.Address {
+	address := repository.Address{
+		Street:     strings.TrimSpace(a.Street),
+		City:       strings.TrimSpace(a.City),
+		PostalCode: strings.ToUpper(strings.TrimSpace(a.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(a.Country)),
+	}
+	if nil != a.Province {
+		address.Province = strings.TrimSpace(*a.Province)
+	}
+
+	// the limits are the column sizes, see migrations
+	for _, line := range []struct {
+		field     string
+		value     string
+		optional  bool
+		maxLength int
+	}{
+		{field: "street", value: address.Street, maxLength: 200},
+		{field: "city", value: address.City, maxLength: 100},
+		{field: "province", value: address.Province, optional: true, maxLength: 100},
+	} {
+		switch {
+		case line.value == "" && !line.optional:
+			errs.add("address."+line.field, errRequired)
+		case utf8.RuneCountInString(line.value) > line.maxLength:
+			errs.add("address."+line.field, validationError{code: generated.ErrorCodeTooLong})
+		}
+	}
+
+	// ParseRegion also accepts UN M.49 numeric codes, such as "360"
+	region, err := language.ParseRegion(address.Country)
+	switch {
+	case address.Country == "":
+		errs.add("address.country", errRequired)
+	case nil != err || !region.IsCountry() || region.String() != address.Country:
+		errs.add("address.country", validationError{code: generated.ErrorCodeCountryInvalid})
+	}
+
+	pattern := postalCode
+	if address.Country == "ID" {
+		pattern = postalCodeID
+	}
+	switch {
+	case address.PostalCode == "":
+		errs.add("address.postal_code", errRequired)
+	case !pattern.MatchString(address.PostalCode):
+		errs.add("address.postal_code", validationError{code: generated.ErrorCodePostalCodeInvalid})
+	}
+
+	return address
+}
+
+// profileResponse renders the stored profile, attributes that are not known
+// are left out.
+func (s *Server) profileResponse(profile repository.FindByPublicIdOutput) generated.ProfileResponse {
+	response := generated.ProfileResponse{
+		FullName: profile.FullName,
+		Phone:    profile.Phone,
+		Email:    profile.Email,
+	}
+	if nil != profile.Email {
+		verified := nil != profile.EmailVerifiedAt
+		response.EmailVerified = &verified
+	}
+	if nil != profile.BirthDate {
+		date := profile.BirthDate.Format(birthDateLayout)
+		response.BirthDate = &date
+	}
+	if nil != profile.Gender {
+		gender := generated.Gender(*profile.Gender)
+		response.Gender = &gender
+	}
+	if nil != profile.Avatar {
+		avatar := s.avatarResponse(*profile.Avatar)
+		response.Avatar = &avatar
+	}
+	if nil != profile.Address {
+		response.Address = &generated.Address{
+			Street:     profile.Address.Street,
+			City:       profile.Address.City,
+			PostalCode: profile.Address.PostalCode,
+			Country:    profile.Address.Country,
+		}
+		if profile.Address.Province != "" {
+			response.Address.Province = &profile.Address.Province
+		}
+	}
+
+	return response
+}
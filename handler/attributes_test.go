@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	t.Parallel()
+
+	for email, expected := range map[string]string{
+		" Budi.Santoso@Example.CO.ID ": "Budi.Santoso@example.co.id",
+		"budi@example.com":             "budi@example.com",
+	} {
+		normalized, err := normalizeEmail(email)
+		assert.NoError(t, err, email)
+		assert.Equal(t, expected, normalized)
+	}
+
+	for _, email := range []string{"budi", "budi@", "Budi <budi@example.com>", "budi@example.com, ani@example.com"} {
+		_, err := normalizeEmail(email)
+		assert.Equal(t, validationError{code: generated.ErrorCodeEmailInvalid}, err, email)
+	}
+}
+
+func TestParseBirthDate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	date, err := parseBirthDate("1990-02-28", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(1990, time.February, 28, 0, 0, 0, 0, time.UTC), date)
+
+	for _, date := range []string{"1990-02-30", "28-02-1990", "1899-12-31", "2023-06-02"} {
+		_, err = parseBirthDate(date, now)
+		assert.Equal(t, validationError{code: generated.ErrorCodeBirthDateInvalid}, err, date)
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	t.Parallel()
+
+	province := " DKI Jakarta "
+
+	var errs fieldErrors
+	address := normalizeAddress(&errs, generated.Address{
+		Street:     " Jl. Sudirman No. 1 ",
+		City:       "Jakarta Pusat",
+		Province:   &province,
+		PostalCode: "10220",
+		Country:    "id",
+	})
+	assert.Empty(t, errs)
+	assert.Equal(t, repository.Address{
+		Street:     "Jl. Sudirman No. 1",
+		City:       "Jakarta Pusat",
+		Province:   "DKI Jakarta",
+		PostalCode: "10220",
+		Country:    "ID",
+	}, address)
+
+	errs = nil
+	normalizeAddress(&errs, generated.Address{
+		Street:     "Jl. Sudirman No. 1",
+		PostalCode: "SW1A 1AA",
+		Country:    "ID",
+	})
+	normalizeAddress(&errs, generated.Address{
+		Street:     "10 Downing Street",
+		City:       "London",
+		PostalCode: "SW1A 2AA",
+		Country:    "826",
+	})
+	var codes []string
+	for _, e := range errs {
+		codes = append(codes, e.Field+":"+string(e.Code))
+	}
+	assert.Equal(t, []string{
+		"address.city:required",
+		"address.postal_code:postal_code_invalid",
+		"address.country:country_invalid",
+	}, codes)
+}
+
+func TestServer_ProfileAttributes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	var (
+		email     = "budi@example.com"
+		birthDate = time.Date(1990, time.February, 28, 0, 0, 0, 0, time.UTC)
+		gender    = "male"
+	)
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+	repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(repository.FindByPublicIdOutput{
+		PublicId: "slug",
+		FullName: "Budi Santoso",
+		Phone:    "+6281234567890",
+		ProfileAttributes: repository.ProfileAttributes{
+			Email:     &email,
+			BirthDate: &birthDate,
+			Gender:    &gender,
+			Address: &repository.Address{
+				Street:     "Jl. Sudirman No. 1",
+				City:       "Jakarta Pusat",
+				PostalCode: "10220",
+				Country:    "ID",
+			},
+		},
+	}, nil)
+
+	ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+	assert.NoError(t, s.Profile(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"full_name": "Budi Santoso",
+		"phone": "+6281234567890",
+		"email": "budi@example.com",
+		"email_verified": false,
+		"birth_date": "1990-02-28",
+		"gender": "male",
+		"address": {"street": "Jl. Sudirman No. 1", "city": "Jakarta Pusat", "postal_code": "10220", "country": "ID"}
+	}`, rec.Body.String())
+}
+
+func TestServer_UpdateProfileKeepsAttributes(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	email := "budi@example.com"
+	gender := "male"
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+	repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(repository.FindByPublicIdOutput{
+		PublicId:          "slug",
+		FullName:          "old name",
+		Phone:             "+6281234567890",
+		ProfileAttributes: repository.ProfileAttributes{Email: &email},
+	}, nil)
+	repo.EXPECT().Put(gomock.Any(), repository.UpdateUserInput{
+		PublicId:          "slug",
+		FullName:          "new name",
+		Phone:             "+6281234567890",
+		ProfileAttributes: repository.ProfileAttributes{Email: &email, Gender: &gender},
+		Actor:             authActor,
+	}).Return(repository.UpdateUserOutput{Version: 4}, nil)
+
+	ctx, rec := newAuthContext(e, http.MethodPut, map[string]string{
+		"full_name": "new name",
+		"phone":     "+6281234567890",
+		"gender":    "male",
+	}, "slug")
+	assert.NoError(t, s.UpdateProfile(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_PatchProfileAttributes(t *testing.T) {
+	t.Parallel()
+
+	email := "budi@example.com"
+	birthDate := time.Date(1990, time.February, 28, 0, 0, 0, 0, time.UTC)
+	current := repository.FindByPublicIdOutput{
+		PublicId:          "slug",
+		FullName:          "old name",
+		Phone:             "+6281234567890",
+		ProfileAttributes: repository.ProfileAttributes{Email: &email},
+	}
+	withAddress := current
+	withAddress.Address = &repository.Address{
+		Street:     "Jl. Sudirman 1",
+		City:       "Jakarta",
+		Province:   "DKI Jakarta",
+		PostalCode: "10210",
+		Country:    "ID",
+	}
+
+	type Case struct {
+		name     string
+		body     string
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+		fields   []string
+	}
+	var testCases = []Case{
+		{
+			name: "request setting and removing attributes",
+			body: `{"birth_date":"1990-02-28","email":null}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+				repo.EXPECT().Patch(gomock.Any(), repository.PatchUserInput{
+					PublicId:   "slug",
+					Attributes: &repository.ProfileAttributes{BirthDate: &birthDate},
+					Remove:     []string{"email"},
+					Actor:      authActor,
+				}).Return(repository.UpdateUserOutput{Version: 4}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "request changing address members",
+			body: `{"address":{"city":" Bandung ","postal_code":"40111","province":null}}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(withAddress, nil)
+				repo.EXPECT().Patch(gomock.Any(), repository.PatchUserInput{
+					PublicId: "slug",
+					Attributes: &repository.ProfileAttributes{Address: &repository.Address{
+						Street:     "Jl. Sudirman 1",
+						City:       "Bandung",
+						PostalCode: "40111",
+						Country:    "ID",
+					}},
+					AddressMembers: []string{"city", "postal_code", "province"},
+					Actor:          authActor,
+				}).Return(repository.UpdateUserOutput{Version: 4}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "request with invalid attributes",
+			body: `{"email":"budi","gender":"unknown","address":{"street":"Jl. Sudirman","city":"Jakarta","postal_code":"1022","country":"ID"}}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+			},
+			expected: http.StatusBadRequest,
+			fields:   []string{"email", "gender", "address.postal_code"},
+		},
+		{
+			name:     "request removing a required address member",
+			body:     `{"address":{"city":null,"postal_code":"40111"}}`,
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusBadRequest,
+			fields:   []string{"address.city"},
+		},
+		{
+			name: "request with part of an address the user does not have",
+			body: `{"address":{"city":"Bandung"}}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+			},
+			expected: http.StatusBadRequest,
+			fields:   []string{"address.street", "address.country", "address.postal_code"},
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			cases.mock(repo)
+
+			req := httptest.NewRequest(http.MethodPatch, "/profile", bytes.NewBufferString(cases.body))
+			req.Header.Set(echo.HeaderContentType, mimeMergePatchJSON)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user", map[string]any{"sub": "slug"})
+
+			assert.NoError(t, s.PatchProfile(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusBadRequest {
+				var response generated.ErrorResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				if assert.NotNil(t, response.Errors) {
+					var fields []string
+					for _, e := range *response.Errors {
+						fields = append(fields, e.Field)
+					}
+					assert.Equal(t, cases.fields, fields)
+				}
+			}
+		})
+	}
+}
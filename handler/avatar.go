@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// avatarMaxPixels bounds the decoded image to about 100 MB of memory
+	avatarMaxPixels = 25_000_000
+	// multipartOverhead is allowed on top of the file for the form framing
+	multipartOverhead = 64 << 10
+)
+
+// avatarSizes are the edge lengths of the square thumbnails, see Avatar in
+// api.yml.
+var avatarSizes = []struct {
+	name string
+	size int
+}{
+	{name: "small", size: 64},
+	{name: "medium", size: 256},
+	{name: "large", size: 512},
+}
+
+func (s *Server) UploadAvatar(ctx echo.Context) error {
+	var (
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		c        = ctx.Request().Context()
+		errs     fieldErrors
+	)
+
+	req := ctx.Request()
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, s.AvatarMaxBytes+multipartOverhead)
+	header, err := ctx.FormFile("avatar")
+	if nil != err {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			return errorResponse(ctx, http.StatusRequestEntityTooLarge, generated.ErrorCodeAvatarTooLarge)
+		case err == http.ErrMissingFile:
+			errs.add("avatar", errRequired)
+			return errs.write(ctx)
+		default:
+			return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+		}
+	}
+	if header.Size > s.AvatarMaxBytes {
+		errs.add("avatar", validationError{code: generated.ErrorCodeAvatarTooLarge})
+		return errs.write(ctx)
+	}
+
+	data, err := readFormFile(header)
+	if nil != err {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+	// sniff the content, the declared content type is up to the client
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png":
+	default:
+		errs.add("avatar", validationError{code: generated.ErrorCodeAvatarType})
+		return errs.write(ctx)
+	}
+
+	// re-encoding the decoded pixels is what strips EXIF and any payload
+	// smuggled into the original file
+	img, err := decodeImage(data, avatarMaxPixels)
+	if nil != err {
+		code := generated.ErrorCodeAvatarInvalid
+		if err == errTooManyPixels {
+			code = generated.ErrorCodeAvatarTooLarge
+		}
+		errs.add("avatar", validationError{code: code})
+		return errs.write(ctx)
+	}
+
+	current, err := s.Repository.FindByPublicId(c, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	// every upload gets new keys, so caches never serve the previous photo
+	id, err := randomToken()
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	for _, size := range avatarSizes {
+		var thumb []byte
+		if thumb, err = encodeJPEG(thumbnail(img, size.size)); nil == err {
+			err = s.BlobStore.Put(c, avatarKey(id, size.name), bytes.NewReader(thumb), "image/jpeg")
+		}
+		if nil != err {
+			s.discardAvatar(ctx, id)
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+	}
+
+	if err = s.Repository.PutAvatar(c, repository.UpdateAvatarInput{
+		PublicId: publicId,
+		Avatar:   &id,
+		Actor:    requestActor(ctx, publicId),
+	}); nil != err {
+		s.discardAvatar(ctx, id)
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	if nil != current.Avatar {
+		s.discardAvatar(ctx, *current.Avatar)
+	}
+
+	return ctx.JSON(http.StatusOK, s.avatarResponse(id))
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	f, err := header.Open()
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	return io.ReadAll(f)
+}
+
+func avatarKey(id, size string) string {
+	return id + "/" + size + ".jpg"
+}
+
+func (s *Server) avatarResponse(id string) generated.Avatar {
+	return generated.Avatar{
+		Small:  s.BlobStore.URL(avatarKey(id, "small")),
+		Medium: s.BlobStore.URL(avatarKey(id, "medium")),
+		Large:  s.BlobStore.URL(avatarKey(id, "large")),
+	}
+}
+
+// deleteAvatar removes the thumbnails of an avatar that is no longer
+// referenced, it keeps going after a failure and returns the first one.
+func (s *Server) deleteAvatar(ctx context.Context, id string) error {
+	var first error
+	for _, size := range avatarSizes {
+		if err := s.BlobStore.Delete(ctx, avatarKey(id, size.name)); nil != err && nil == first {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// discardAvatar deletes the avatar while answering a request, failures only
+// leave orphans behind and are logged.
+func (s *Server) discardAvatar(ctx echo.Context, id string) {
+	if err := s.deleteAvatar(ctx.Request().Context(), id); nil != err {
+		ctx.Logger().Errorf("deleting avatar %s: %s", id, err)
+	}
+}
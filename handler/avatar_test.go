@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/storage"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// blobRecorder is an in memory storage.BlobStore.
+type blobRecorder struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (b *blobRecorder) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	data, err := io.ReadAll(r)
+	if nil != err {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if nil == b.blobs {
+		b.blobs = map[string][]byte{}
+	}
+	b.blobs[key] = data
+
+	return nil
+}
+
+func (b *blobRecorder) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.blobs[key]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *blobRecorder) Delete(_ context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.blobs, key)
+
+	return nil
+}
+
+func (b *blobRecorder) URL(key string) string {
+	return "/avatars/" + key
+}
+
+// testJPEG encodes a 40×20 image, red on the left half and blue on the right
+// half, tagged with the EXIF orientation.
+func testJPEG(t *testing.T, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	tiff := []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 1, 0x01, 0x12, 0, 3, 0, 0, 0, 1, byte(orientation >> 8), byte(orientation), 0, 0, 0, 0, 0, 0}
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(app1) + 2) >> 8), byte(len(app1) + 2)}, app1...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func newAvatarRequest(t *testing.T, data []byte) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("avatar", "avatar.jpg")
+	assert.NoError(t, err)
+	_, err = part.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPut, "/profile/avatar", &body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+
+	return req
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 6, jpegOrientation(testJPEG(t, 6)))
+	assert.Equal(t, 1, jpegOrientation(testJPEG(t, 9)))
+	assert.Equal(t, 1, jpegOrientation([]byte("not an image")))
+}
+
+func TestServer_UploadAvatar(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	old := "old"
+	blobs := &blobRecorder{blobs: map[string][]byte{"old/small.jpg": nil, "old/medium.jpg": nil, "old/large.jpg": nil}}
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo, BlobStore: blobs})
+
+	repo.EXPECT().
+		FindByPublicId(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).
+		Return(repository.FindByPublicIdOutput{PublicId: "slug", Avatar: &old}, nil)
+	var id string
+	repo.EXPECT().
+		PutAvatar(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.UpdateAvatarInput) error {
+			id = *input.Avatar
+			return nil
+		})
+
+	e.Use(OpenAPIMiddleware(OpenAPIMiddlewareOptions{Strict: true}))
+	e.PUT("/profile/avatar", func(c echo.Context) error {
+		c.Set("user", map[string]any{"sub": "slug"})
+		return s.UploadAvatar(c)
+	})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, newAvatarRequest(t, testJPEG(t, 6)))
+
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response generated.Avatar
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "/avatars/"+id+"/large.jpg", response.Large)
+
+	// the previous thumbnails are gone, the new ones carry no EXIF
+	assert.Len(t, blobs.blobs, len(avatarSizes))
+	for _, size := range avatarSizes {
+		data := blobs.blobs[avatarKey(id, size.name)]
+		assert.NotContains(t, string(data), "Exif")
+
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if assert.NoError(t, err) {
+			assert.Equal(t, image.Rect(0, 0, size.size, size.size), img.Bounds())
+
+			// rotated clockwise the red half ends up on top
+			r, _, b, _ := img.At(size.size/2, size.size/8).RGBA()
+			assert.Greater(t, r, b, size.name)
+			r, _, b, _ = img.At(size.size/2, size.size*7/8).RGBA()
+			assert.Greater(t, b, r, size.name)
+		}
+	}
+}
+
+func TestServer_UploadAvatarInvalid(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		maxBytes int64
+		data     []byte
+		expected int
+		code     generated.ErrorCode
+	}
+	var testCases = []Case{
+		{
+			name:     "file which is not an image",
+			data:     []byte("GIF89a is not accepted either"),
+			expected: http.StatusBadRequest,
+			code:     generated.ErrorCodeAvatarType,
+		},
+		{
+			name:     "truncated image",
+			data:     testJPEG(t, 1)[:200],
+			expected: http.StatusBadRequest,
+			code:     generated.ErrorCodeAvatarInvalid,
+		},
+		{
+			name:     "file above the size limit",
+			maxBytes: 100,
+			data:     testJPEG(t, 1),
+			expected: http.StatusBadRequest,
+			code:     generated.ErrorCodeAvatarTooLarge,
+		},
+		{
+			name:     "body far above the size limit",
+			maxBytes: 100,
+			data:     make([]byte, 2*multipartOverhead),
+			expected: http.StatusRequestEntityTooLarge,
+			code:     generated.ErrorCodeAvatarTooLarge,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			blobs := &blobRecorder{}
+			s := NewServer(NewServerOptions{Repository: repo, BlobStore: blobs, AvatarMaxBytes: cases.maxBytes})
+
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(newAvatarRequest(t, cases.data), rec)
+			ctx.Set("user", map[string]any{"sub": "slug"})
+
+			assert.NoError(t, s.UploadAvatar(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			var response generated.ErrorResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, cases.code, response.Code)
+			assert.Empty(t, blobs.blobs)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) DeleteProfile(ctx echo.Context) error {
+	publicId := ctx.Get("user").(map[string]any)["sub"].(string)
+
+	out, err := s.Repository.SoftDelete(ctx.Request().Context(), repository.DeleteUserInput{PublicId: publicId})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeUserNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	s.sessions.forget(publicId)
+
+	return ctx.JSON(http.StatusAccepted, generated.AccountDeletionResponse{
+		RestoreUntil: out.DeletedAt.Add(s.DeletionGrace).UTC(),
+	})
+}
+
+// ValidSession accepts the tokens carrying the current session version of an
+// active account, which were issued after its last suspension. It implements
+// SessionValidator, the sessions are cached for SessionCacheTTL.
+func (s *Server) ValidSession(ctx context.Context, sub string, version int, issuedAt time.Time) (string, bool, error) {
+	entry, ok := s.sessions.get(sub)
+	if !ok {
+		session, err := s.Repository.FindSession(ctx, repository.FindSessionInput{Subject: sub})
+		switch {
+		case err == sql.ErrNoRows:
+			// purged
+			entry = cachedSession{missing: true}
+		case nil != err:
+			return "", false, err
+		default:
+			entry = cachedSession{session: session}
+		}
+		s.sessions.put(sub, entry)
+	}
+	if entry.missing {
+		return "", false, nil
+	}
+
+	session := entry.session
+	// iat only has seconds, a token of the same second is rejected as well
+	suspended := nil != session.SuspendedAt && !issuedAt.After(session.SuspendedAt.Truncate(time.Second))
+
+	return session.PublicId, session.Status == repository.StatusActive && session.SessionVersion == version && !suspended, nil
+}
+
+// PurgeDeletedAccounts removes the accounts deleted longer than the grace
+// period ago together with their avatars and exports, it returns how many were
+// purged.
+func (s *Server) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	out, err := s.Repository.PurgeDeleted(ctx, repository.PurgeDeletedUsersInput{
+		DeletedBefore: time.Now().Add(-s.DeletionGrace),
+	})
+	if nil != err {
+		return 0, err
+	}
+
+	// the rows are gone already, keep deleting the remaining blobs
+	var first error
+	for _, avatar := range out.Avatars {
+		if err = s.deleteAvatar(ctx, avatar); nil != err && nil == first {
+			first = fmt.Errorf("deleting avatar %s: %w", avatar, err)
+		}
+	}
+	if err = s.deleteExports(ctx, out.Exports); nil != err && nil == first {
+		first = err
+	}
+
+	return out.Count, first
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestServer_DeleteProfile(t *testing.T) {
+	t.Parallel()
+
+	deletedAt := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	type Case struct {
+		name     string
+		err      error
+		expected int
+	}
+	var testCases = []Case{
+		{name: "deleting the account", expected: http.StatusAccepted},
+		{name: "deleting a deleted account", err: sql.ErrNoRows, expected: http.StatusNotFound},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, DeletionGrace: 24 * time.Hour})
+			repo.EXPECT().
+				SoftDelete(gomock.Any(), repository.DeleteUserInput{PublicId: "slug"}).
+				Return(repository.DeleteUserOutput{DeletedAt: deletedAt}, cases.err)
+
+			ctx, rec := newAuthContext(e, http.MethodDelete, nil, "slug")
+			assert.NoError(t, s.DeleteProfile(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusAccepted {
+				var response generated.AccountDeletionResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, deletedAt.Add(24*time.Hour), response.RestoreUntil)
+			}
+		})
+	}
+}
+
+func TestServer_LoginDeletedAccount(t *testing.T) {
+	t.Parallel()
+
+	password, _ := bcrypt.GenerateFromPassword([]byte("T3stv@lid"), bcrypt.MinCost)
+
+	type Case struct {
+		name      string
+		deletedAt time.Time
+		mock      func(repo *repository.MockRepositoryInterface)
+		expected  int
+	}
+	var testCases = []Case{
+		{
+			name:      "login within the grace period restores the account",
+			deletedAt: time.Now().Add(-time.Hour),
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().Restore(gomock.Any(), repository.RestoreUserInput{Id: 1}).Return(nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:      "login after the grace period",
+			deletedAt: time.Now().Add(-48 * time.Hour),
+			mock:      func(repo *repository.MockRepositoryInterface) {},
+			expected:  http.StatusNotFound,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, DeletionGrace: 24 * time.Hour})
+			repo.EXPECT().FindByPhone(gomock.Any(), gomock.Any()).Return(repository.FindByPhoneOutput{
+				Id:             1,
+				PublicId:       "slug",
+				Phone:          "+6281234567890",
+				Password:       string(password),
+				SessionVersion: 2,
+				DeletedAt:      &cases.deletedAt,
+			}, nil)
+			cases.mock(repo)
+
+			b, _ := json.Marshal(generated.LoginRequest{Phone: "+6281234567890", Password: "T3stv@lid"})
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(b))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			assert.NoError(t, s.Login(e.NewContext(req, rec)))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusOK {
+				var response generated.LoginResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				if assert.NotNil(t, response.Restored) {
+					assert.True(t, *response.Restored)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_ValidSession(t *testing.T) {
+	t.Parallel()
+
+	deletedAt := time.Now()
+	issuedAt := time.Now().Add(-time.Hour)
+	suspendedBefore := issuedAt.Add(-time.Hour)
+	suspendedAfter := issuedAt.Add(time.Minute)
+	publicId := "01H2X3Y4Z5A6B7C8D9E0F1G2H3"
+
+	type Case struct {
+		name     string
+		session  repository.FindSessionOutput
+		err      error
+		expected bool
+	}
+	var testCases = []Case{
+		{
+			name:     "current session version",
+			session:  repository.FindSessionOutput{PublicId: publicId, SessionVersion: 1, Status: repository.StatusActive},
+			expected: true,
+		},
+		{
+			name:     "token issued after a reactivation",
+			session:  repository.FindSessionOutput{PublicId: publicId, SessionVersion: 1, Status: repository.StatusActive, SuspendedAt: &suspendedBefore},
+			expected: true,
+		},
+		{
+			name:    "token issued before a reactivation",
+			session: repository.FindSessionOutput{PublicId: publicId, SessionVersion: 1, Status: repository.StatusActive, SuspendedAt: &suspendedAfter},
+		},
+		{name: "revoked session version", session: repository.FindSessionOutput{SessionVersion: 2, Status: repository.StatusActive}},
+		{name: "suspended account", session: repository.FindSessionOutput{SessionVersion: 1, Status: repository.StatusSuspended, SuspendedAt: &suspendedAfter}},
+		{name: "deleted account", session: repository.FindSessionOutput{SessionVersion: 1, Status: repository.StatusDeleted, DeletedAt: &deletedAt}},
+		{name: "purged account", err: sql.ErrNoRows},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).Return(cases.session, cases.err)
+
+			// the second call is answered by the cache
+			for i := 0; i < 2; i++ {
+				id, valid, err := s.ValidSession(context.Background(), "slug", 1, issuedAt)
+				assert.NoError(t, err)
+				assert.Equal(t, cases.expected, valid)
+				if valid {
+					assert.Equal(t, publicId, id)
+				}
+			}
+		})
+	}
+}
+
+// sessionVersions is a SessionValidator backed by a map of public id to version.
+type sessionVersions map[string]int
+
+func (v sessionVersions) ValidSession(_ context.Context, sub string, version int, _ time.Time) (string, bool, error) {
+	current, ok := v[sub]
+
+	return sub, ok && current == version, nil
+}
+
+func TestMiddlewareRevokedSession(t *testing.T) {
+	t.Parallel()
+
+	token, err := Create(map[string]any{"sub": "slug", "ver": 1})
+	assert.NoError(t, err)
+
+	for _, cases := range []struct {
+		versions sessionVersions
+		expected int
+	}{
+		{versions: sessionVersions{"slug": 1}, expected: http.StatusOK},
+		{versions: sessionVersions{"slug": 2}, expected: http.StatusForbidden},
+		{versions: sessionVersions{}, expected: http.StatusForbidden},
+	} {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+
+		handler := Middleware(MiddlewareOptions{Sessions: cases.versions})(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+		assert.NoError(t, handler(e.NewContext(req, rec)))
+		assert.Equal(t, cases.expected, rec.Code, cases.versions)
+	}
+}
+
+func TestServer_PurgeDeletedAccounts(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blobs := &blobRecorder{blobs: map[string][]byte{"abc/small.jpg": nil, "abc/medium.jpg": nil, "abc/large.jpg": nil, "def/small.jpg": nil}}
+	exports := &blobRecorder{blobs: map[string][]byte{"abc.zip": nil}}
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo, BlobStore: blobs, Exports: exports, DeletionGrace: time.Hour})
+
+	repo.EXPECT().
+		PurgeDeleted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.PurgeDeletedUsersInput) (repository.PurgeDeletedUsersOutput, error) {
+			assert.WithinDuration(t, time.Now().Add(-time.Hour), input.DeletedBefore, time.Minute)
+			return repository.PurgeDeletedUsersOutput{Count: 2, Avatars: []string{"abc"}, Exports: []string{"abc.zip"}}, nil
+		})
+
+	n, err := s.PurgeDeletedAccounts(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, map[string][]byte{"def/small.jpg": nil}, blobs.blobs)
+	assert.Empty(t, exports.blobs)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const emailVerifyPath = "/email/verify"
+
+func (s *Server) RequestEmailVerification(ctx echo.Context) error {
+	var (
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		c        = ctx.Request().Context()
+	)
+
+	profile, err := s.Repository.FindByPublicId(c, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	switch {
+	case nil == profile.Email:
+		return errorResponse(ctx, http.StatusConflict, generated.ErrorCodeEmailNotSet)
+	case nil != profile.EmailVerifiedAt:
+		return errorResponse(ctx, http.StatusConflict, generated.ErrorCodeEmailVerified)
+	}
+
+	// fail early, the link would not verify anything
+	owner, err := s.Repository.FindByEmail(c, repository.FindByEmailInput{Email: *profile.Email})
+	switch {
+	case err == sql.ErrNoRows:
+	case nil != err:
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	case owner.PublicId != publicId:
+		return errorResponse(ctx, http.StatusConflict, generated.ErrorCodeEmailTaken)
+	}
+
+	expiresAt := time.Now().Add(s.EmailVerificationTTL).UTC().Truncate(time.Second)
+	token, err := signLink(jwt.MapClaims{"sub": publicId, "verify_email": *profile.Email}, expiresAt)
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	link := strings.TrimSuffix(s.PublicURL, "/") + emailVerifyPath + "?" + url.Values{"token": {token}}.Encode()
+	if err = s.Mailer.Send(c, *profile.Email, "Verify your email address", fmt.Sprintf(
+		"Hi %s,\n\nopen the link below to verify your email address, it works until %s.\n\n%s\n\nIf this was not you, ignore this mail.\n",
+		profile.FullName,
+		expiresAt.Format(time.RFC3339),
+		link,
+	)); nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	return ctx.JSON(http.StatusAccepted, generated.EmailVerificationResponse{
+		Email:     *profile.Email,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (s *Server) VerifyEmail(ctx echo.Context, params generated.VerifyEmailParams) error {
+	claims, err := parseLink(params.Token)
+	if nil != err {
+		return errorResponse(ctx, http.StatusForbidden, generated.ErrorCodeTokenInvalid)
+	}
+	publicId, _ := claims["sub"].(string)
+	email, _ := claims["verify_email"].(string)
+	if publicId == "" || email == "" {
+		return errorResponse(ctx, http.StatusForbidden, generated.ErrorCodeTokenInvalid)
+	}
+
+	out, err := s.Repository.VerifyEmail(ctx.Request().Context(), repository.VerifyEmailInput{
+		PublicId: publicId,
+		Email:    email,
+		Actor:    requestActor(ctx, publicId),
+	})
+	if nil != err {
+		switch err {
+		case sql.ErrNoRows:
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodeEmailChanged)
+		case repository.ErrEmailTaken:
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodeEmailTaken)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	return ctx.JSON(http.StatusOK, generated.EmailVerifiedResponse{
+		Email:      email,
+		VerifiedAt: out.VerifiedAt.UTC(),
+	})
+}
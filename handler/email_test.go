@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// mailRecorder keeps every sent mail body per address.
+type mailRecorder struct {
+	mu    sync.Mutex
+	mails map[string][]string
+}
+
+func (r *mailRecorder) Send(_ context.Context, to string, _ string, body string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.mails == nil {
+		r.mails = make(map[string][]string)
+	}
+	r.mails[to] = append(r.mails[to], body)
+
+	return nil
+}
+
+func TestServer_RequestEmailVerification(t *testing.T) {
+	t.Parallel()
+
+	email := "budi@example.com"
+	verifiedAt := time.Now()
+
+	type Case struct {
+		name     string
+		profile  repository.FindByPublicIdOutput
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+		code     generated.ErrorCode
+	}
+	var testCases = []Case{
+		{
+			name:    "request for an unverified email address",
+			profile: repository.FindByPublicIdOutput{ProfileAttributes: repository.ProfileAttributes{Email: &email}},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByEmail(gomock.Any(), repository.FindByEmailInput{Email: email}).Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+			},
+			expected: http.StatusAccepted,
+		},
+		{
+			name:     "request without email address",
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusConflict,
+			code:     generated.ErrorCodeEmailNotSet,
+		},
+		{
+			name:     "request for a verified email address",
+			profile:  repository.FindByPublicIdOutput{ProfileAttributes: repository.ProfileAttributes{Email: &email}, EmailVerifiedAt: &verifiedAt},
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusConflict,
+			code:     generated.ErrorCodeEmailVerified,
+		},
+		{
+			name:    "request for an email address verified by another user",
+			profile: repository.FindByPublicIdOutput{ProfileAttributes: repository.ProfileAttributes{Email: &email}},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByEmail(gomock.Any(), gomock.Any()).Return(repository.FindByPhoneOutput{PublicId: "other"}, nil)
+			},
+			expected: http.StatusConflict,
+			code:     generated.ErrorCodeEmailTaken,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			mails := &mailRecorder{}
+			s := NewServer(NewServerOptions{Repository: repo, Mailer: mails, PublicURL: "https://api.example.com/"})
+			cases.profile.PublicId = "slug"
+			repo.EXPECT().FindByPublicId(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).Return(cases.profile, nil)
+			cases.mock(repo)
+
+			ctx, rec := newAuthContext(e, http.MethodPost, nil, "slug")
+			assert.NoError(t, s.RequestEmailVerification(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected != http.StatusAccepted {
+				var response generated.ErrorResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, cases.code, response.Code)
+				assert.Empty(t, mails.mails)
+				return
+			}
+
+			if assert.Len(t, mails.mails[email], 1) {
+				link := regexp.MustCompile(`https://api\.example\.com/email/verify\?token=\S+`).FindString(mails.mails[email][0])
+				if assert.NotEmpty(t, link) {
+					u, err := url.Parse(link)
+					assert.NoError(t, err)
+					claims, err := parseLink(u.Query().Get("token"))
+					assert.NoError(t, err)
+					assert.Equal(t, "slug", claims["sub"])
+					assert.Equal(t, email, claims["verify_email"])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	verifiedAt := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	valid, err := signLink(map[string]any{"sub": "slug", "verify_email": "budi@example.com"}, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	expired, err := signLink(map[string]any{"sub": "slug", "verify_email": "budi@example.com"}, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	export, err := signLink(map[string]any{"export": 7}, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+
+	type Case struct {
+		name     string
+		token    string
+		err      error
+		expected int
+		code     generated.ErrorCode
+	}
+	var testCases = []Case{
+		{name: "valid link", token: valid, expected: http.StatusOK},
+		{name: "link to a changed email address", token: valid, err: sql.ErrNoRows, expected: http.StatusConflict, code: generated.ErrorCodeEmailChanged},
+		{name: "link to an email address verified by another user", token: valid, err: repository.ErrEmailTaken, expected: http.StatusConflict, code: generated.ErrorCodeEmailTaken},
+		{name: "expired link", token: expired, expected: http.StatusForbidden, code: generated.ErrorCodeTokenInvalid},
+		{name: "export link", token: export, expected: http.StatusForbidden, code: generated.ErrorCodeTokenInvalid},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			if cases.token == valid {
+				repo.EXPECT().
+					VerifyEmail(gomock.Any(), repository.VerifyEmailInput{PublicId: "slug", Email: "budi@example.com", Actor: authActor}).
+					Return(repository.VerifyEmailOutput{VerifiedAt: verifiedAt}, cases.err)
+			}
+
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(httptest.NewRequest(http.MethodGet, emailVerifyPath, nil), rec)
+			assert.NoError(t, s.VerifyEmail(ctx, generated.VerifyEmailParams{Token: cases.token}))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusOK {
+				assert.JSONEq(t, `{"email":"budi@example.com","verified_at":"2023-06-01T00:00:00Z"}`, rec.Body.String())
+				return
+			}
+			var response generated.ErrorResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, cases.code, response.Code)
+		})
+	}
+}
@@ -1,137 +1,462 @@
 package handler
 
 import (
+	"context"
 	"database/sql"
-	"encoding/base64"
-	"fmt"
+	"encoding/json"
 	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/SawitProRecruitment/UserService/repository"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
+	"mime"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
+	"time"
 	"unicode"
 )
 
+// mimeMergePatchJSON is the media type of JSON Merge Patch documents, RFC 7396.
+const mimeMergePatchJSON = "application/merge-patch+json"
+
 func (s *Server) Login(ctx echo.Context) error {
 	var request generated.LoginRequest
 	if err := ctx.Bind(&request); nil != err {
-		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: "invalid parameter request"})
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
 	}
 
-	if err := validatePhone(request.Phone); nil != err {
-		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	var (
+		errs fieldErrors
+		err  error
+	)
+	if request.Email != "" {
+		if request.Phone != "" {
+			// one identifier only, otherwise which one failed would leak
+			return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+		}
+		request.Email, err = normalizeEmail(request.Email)
+		errs.add("email", err)
+	} else {
+		request.Phone = NormalizePhone(request.Phone)
+		errs.add("phone", validatePhone(request.Phone))
+	}
+	if request.Password == "" {
+		errs.add("password", errRequired)
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
 	}
 
-	users, err := s.Repository.FindByPhone(ctx.Request().Context(), repository.FindByPhoneInput{Phone: request.Phone})
+	var users repository.FindByPhoneOutput
+	if request.Email != "" {
+		users, err = s.Repository.FindByEmail(ctx.Request().Context(), repository.FindByEmailInput{Email: request.Email})
+	} else {
+		users, err = s.Repository.FindByPhone(ctx.Request().Context(), repository.FindByPhoneInput{Phone: request.Phone})
+	}
 	if nil != err {
 		if err == sql.ErrNoRows {
-			return ctx.JSON(http.StatusNotFound, generated.ErrorResponse{Message: "user not found"})
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeUserNotFound)
 		}
 
-		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: http.StatusText(http.StatusInternalServerError)})
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	// past the grace period the account only waits for the purge
+	if nil != users.DeletedAt && time.Since(*users.DeletedAt) > s.DeletionGrace {
+		return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeUserNotFound)
 	}
 
 	if err = bcrypt.CompareHashAndPassword([]byte(users.Password), []byte(request.Password)); nil != err {
-		return ctx.JSON(http.StatusForbidden, generated.ErrorResponse{Message: "unauthorized"})
+		return errorResponse(ctx, http.StatusForbidden, generated.ErrorCodeInvalidCredentials)
+	}
+
+	// only told after the password matched, the status is nobody else's business
+	switch users.Status {
+	case repository.StatusSuspended:
+		return errorResponse(ctx, http.StatusForbidden, generated.ErrorCodeAccountSuspended)
 	}
 
-	token, err := Create(map[string]string{
-		"sub": users.Slug,
+	if users.PasswordResetRequired {
+		if request.NewPassword == "" {
+			return errorResponse(ctx, http.StatusForbidden, generated.ErrorCodePasswordResetRequired)
+		}
+		if err = validatePassword(request.NewPassword); nil != err {
+			errs.add("new_password", err)
+			return errs.write(ctx)
+		}
+		if request.NewPassword == request.Password {
+			return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodePasswordReused)
+		}
+		p, _ := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+		reset, err := s.Repository.ResetPassword(ctx.Request().Context(), repository.ResetPasswordInput{
+			Id:       users.Id,
+			Password: string(p),
+		})
+		if nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+		users.SessionVersion = reset.SessionVersion
+		s.sessions.forget(users.PublicId)
+	}
+
+	restored := nil != users.DeletedAt
+	if restored {
+		if err = s.Repository.Restore(ctx.Request().Context(), repository.RestoreUserInput{Id: users.Id}); nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+		s.sessions.forget(users.PublicId)
+	}
+
+	token, err := Create(map[string]any{
+		"sub": users.PublicId,
+		"ver": users.SessionVersion,
 	})
 	if nil != err {
-		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: http.StatusText(http.StatusInternalServerError)})
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
 	}
 
-	return ctx.JSON(
-		http.StatusOK,
-		generated.LoginResponse{
-			Id:    users.Id,
-			Token: token,
-		},
-	)
+	response := generated.LoginResponse{
+		Id:    users.PublicId,
+		Token: token,
+	}
+	if restored {
+		response.Restored = &restored
+	}
+
+	return ctx.JSON(http.StatusOK, response)
 }
 
 func (s *Server) Profile(ctx echo.Context) error {
 	f := ctx.Get("user").(map[string]any)
-	out, err := s.Repository.FindBySlug(ctx.Request().Context(), repository.FindBySlugInput{Slug: f["sub"].(string)})
+	out, err := s.Repository.FindByPublicId(ctx.Request().Context(), repository.FindByPublicIdInput{PublicId: f["sub"].(string)})
 	if nil != err {
-		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: http.StatusText(http.StatusInternalServerError)})
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
 	}
 
-	return ctx.JSON(http.StatusOK, generated.ProfileResponse{
-		FullName: out.FullName,
-		Phone:    out.Phone,
-	})
+	etag := profileETag(out.Version)
+	ctx.Response().Header().Set("ETag", etag)
+	if matchETag(ctx.Request().Header.Get("If-None-Match"), etag, true) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.JSON(http.StatusOK, s.profileResponse(out))
 }
 
 func (s *Server) UpdateProfile(ctx echo.Context) error {
 	var (
-		slug    = ctx.Get("user").(map[string]any)["sub"].(string)
-		request generated.UpdateRequest
-		c       = ctx.Request().Context()
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		request  generated.UpdateRequest
+		c        = ctx.Request().Context()
+	)
+	if err := ctx.Bind(&request); nil != err {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	var (
+		errs fieldErrors
+		err  error
 	)
+	request.FullName, err = s.NameRules.normalize(request.FullName)
+	errs.add("full_name", err)
+	request.Phone = NormalizePhone(request.Phone)
+	errs.add("phone", validatePhone(request.Phone))
+	attrs := attributesRequest{
+		Email:     request.Email,
+		BirthDate: request.BirthDate,
+		Gender:    request.Gender,
+		Address:   request.Address,
+	}.normalize(&errs)
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
 
-	existUser, err := s.Repository.FindByPhone(c, repository.FindByPhoneInput{Phone: request.Phone})
+	current, err := s.Repository.FindByPublicId(c, repository.FindByPublicIdInput{PublicId: publicId})
 	if nil != err {
-		if err != sql.ErrNoRows {
-			return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: http.StatusText(http.StatusInternalServerError)})
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	version, status, code := s.ifMatch(ctx, current.Version)
+	if status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	var change generated.PhoneChangeResponse
+	changePhone := request.Phone != current.Phone
+	if changePhone {
+		taken, err := s.phoneTaken(c, request.Phone)
+		if nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+		if taken {
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodePhoneTaken)
+		}
+
+		// the phone number only changes once the new number is verified, the
+		// change is requested first so its failure leaves the profile as is
+		if change, err = s.requestPhoneChange(ctx, publicId, current.Phone, request.Phone); nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
 		}
 	}
-	if existUser.Phone != "" {
-		return ctx.JSON(http.StatusConflict, generated.ErrorResponse{Message: "phone number already exists"})
+
+	// attributes left out keep their value, old clients don't know them
+	updated, err := s.Repository.Put(c, repository.UpdateUserInput{
+		PublicId:          publicId,
+		FullName:          request.FullName,
+		Phone:             current.Phone,
+		ProfileAttributes: mergeAttributes(current.ProfileAttributes, attrs),
+		Version:           version,
+		Actor:             requestActor(ctx, publicId),
+	})
+	if nil != err {
+		if changePhone {
+			s.withdrawPhoneChange(ctx, publicId)
+		}
+		if err == repository.ErrVersionConflict {
+			return errorResponse(ctx, http.StatusPreconditionFailed, generated.ErrorCodePreconditionFailed)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
 	}
+	ctx.Response().Header().Set("ETag", profileETag(updated.Version))
 
-	if err = s.Repository.Put(c, repository.UpdateUserInput{
-		Slug:     slug,
-		FullName: request.FullName,
-		Phone:    request.Phone,
-	}); nil != err {
-		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: http.StatusText(http.StatusInternalServerError)})
+	if changePhone {
+		return ctx.JSON(http.StatusAccepted, change)
 	}
 
 	return ctx.NoContent(http.StatusOK)
 }
 
+func (s *Server) PatchProfile(ctx echo.Context) error {
+	var (
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		c        = ctx.Request().Context()
+	)
+	if mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType)); mediaType != mimeMergePatchJSON {
+		return errorResponse(ctx, http.StatusUnsupportedMediaType, generated.ErrorCodeUnsupportedMediaType)
+	}
+
+	// decode member by member first, merge patch tells removal apart from
+	// absence by an explicit null
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(ctx.Request().Body).Decode(&members); nil != err || nil == members {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	var (
+		request          generated.PatchProfileRequest
+		address          addressPatch
+		removed          []string
+		changeAttributes bool
+		errs             fieldErrors
+	)
+	for _, field := range sortedKeys(members) {
+		var err error
+		changeAttributes = changeAttributes || isAttribute(field)
+		switch value := members[field]; {
+		case string(value) == "null" && isAttribute(field):
+			removed = append(removed, field)
+		case string(value) == "null":
+			// full name and phone are mandatory, they can not be removed
+			err = errRequired
+		case field == "full_name":
+			err = json.Unmarshal(value, &request.FullName)
+		case field == "phone":
+			err = json.Unmarshal(value, &request.Phone)
+		case field == "email":
+			err = json.Unmarshal(value, &request.Email)
+		case field == "birth_date":
+			err = json.Unmarshal(value, &request.BirthDate)
+		case field == "gender":
+			err = json.Unmarshal(value, &request.Gender)
+		case field == "address":
+			// the members are merged into the stored address below
+			address = decodeAddressPatch(value, &errs)
+		default:
+			err = validationError{code: generated.ErrorCodeInvalid}
+		}
+		errs.add(field, err)
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	// the address members are validated merged into the stored address
+	current, err := s.Repository.FindByPublicId(c, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	if nil != request.FullName {
+		name, err := s.NameRules.normalize(*request.FullName)
+		errs.add("full_name", err)
+		request.FullName = &name
+	}
+	if nil != request.Phone {
+		phone := NormalizePhone(*request.Phone)
+		errs.add("phone", validatePhone(phone))
+		request.Phone = &phone
+	}
+	attrs := attributesRequest{
+		Email:     request.Email,
+		BirthDate: request.BirthDate,
+		Gender:    request.Gender,
+	}.normalize(&errs)
+	// only the members in the patch are written, unless there was no address
+	// before
+	var addressMembers []string
+	if len(address) > 0 {
+		merged := normalizeAddress(&errs, address.apply(current.Address))
+		attrs.Address = &merged
+		if nil != current.Address {
+			addressMembers = sortedKeys(address)
+		}
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	version, status, code := s.ifMatch(ctx, current.Version)
+	if status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	var change generated.PhoneChangeResponse
+	changePhone := nil != request.Phone && *request.Phone != current.Phone
+	if changePhone {
+		taken, err := s.phoneTaken(c, *request.Phone)
+		if nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+		if taken {
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodePhoneTaken)
+		}
+
+		// the phone number only changes once the new number is verified, the
+		// change is requested first so its failure leaves the profile as is
+		if change, err = s.requestPhoneChange(ctx, publicId, current.Phone, *request.Phone); nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+	}
+
+	if nil != request.FullName || changeAttributes {
+		// only the attributes in the patch are written, the ones read above
+		// may be outdated by now
+		input := repository.PatchUserInput{
+			PublicId:       publicId,
+			FullName:       request.FullName,
+			Remove:         removed,
+			AddressMembers: addressMembers,
+			Version:        version,
+			Actor:          requestActor(ctx, publicId),
+		}
+		if changeAttributes {
+			input.Attributes = &attrs
+		}
+
+		updated, err := s.Repository.Patch(c, input)
+		if nil != err {
+			if changePhone {
+				s.withdrawPhoneChange(ctx, publicId)
+			}
+			if err == repository.ErrVersionConflict {
+				return errorResponse(ctx, http.StatusPreconditionFailed, generated.ErrorCodePreconditionFailed)
+			}
+
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+		if nil != request.FullName {
+			current.FullName = *request.FullName
+		}
+		if changeAttributes {
+			current.ProfileAttributes = mergeAttributes(current.ProfileAttributes, attrs)
+			for _, field := range removed {
+				removeAttribute(&current.ProfileAttributes, field)
+			}
+		}
+		current.Version = updated.Version
+	}
+	ctx.Response().Header().Set("ETag", profileETag(current.Version))
+
+	if changePhone {
+		return ctx.JSON(http.StatusAccepted, change)
+	}
+
+	return ctx.JSON(http.StatusOK, s.profileResponse(current))
+}
+
 func (s *Server) Register(ctx echo.Context) error {
 	var request generated.RegistrationRequest
 	if err := ctx.Bind(&request); nil != err {
-		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: "invalid parameter request"})
-	}
-
-	if err := validatePhone(request.Phone); nil != err {
-		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
 	}
 
-	if err := validatePassword(request.Password); nil != err {
-		return ctx.JSON(http.StatusBadRequest, generated.ErrorResponse{Message: err.Error()})
+	var (
+		errs fieldErrors
+		err  error
+	)
+	request.FullName, err = s.NameRules.normalize(request.FullName)
+	errs.add("full_name", err)
+	request.Phone = NormalizePhone(request.Phone)
+	errs.add("phone", validatePhone(request.Phone))
+	errs.add("password", validatePassword(request.Password))
+	if len(errs) > 0 {
+		return errs.write(ctx)
 	}
 
 	var c = ctx.Request().Context()
 	users, err := s.Repository.FindByPhone(c, repository.FindByPhoneInput{Phone: request.Phone})
 	if nil == err && !reflect.ValueOf(users).IsZero() {
-		return ctx.JSON(http.StatusConflict, generated.ErrorResponse{Message: "user with phone number already exists"})
+		return errorResponse(ctx, http.StatusConflict, generated.ErrorCodePhoneTaken)
 	}
 
-	slug := base64.RawStdEncoding.EncodeToString([]byte(request.Phone))
+	publicId, err := NewPublicId(time.Now())
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
 	p, _ := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
-	out, err := s.Repository.Store(c, repository.RegistrationInput{
-		Slug:     slug,
+	_, err = s.Repository.Store(c, repository.RegistrationInput{
+		PublicId: publicId,
 		FullName: request.FullName,
 		Phone:    request.Phone,
 		Password: string(p),
 	})
 	if nil != err {
-		return ctx.JSON(http.StatusInternalServerError, generated.ErrorResponse{Message: http.StatusText(http.StatusInternalServerError)})
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
 	}
 
-	return ctx.JSON(http.StatusOK, generated.RegistrationResponse{Id: out.Id})
+	return ctx.JSON(http.StatusOK, generated.RegistrationResponse{Id: publicId})
+}
+
+// phoneTaken reports whether phone already belongs to a registered user.
+func (s *Server) phoneTaken(ctx context.Context, phone string) (bool, error) {
+	user, err := s.Repository.FindByPhone(ctx, repository.FindByPhoneInput{Phone: phone})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return user.Phone != "", nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
 }
 
 func validatePassword(password string) error {
-	if len(password) < 6 && len(password) > 64 {
-		return fmt.Errorf("password must be greater than 6 and lower than 64")
+	if password == "" {
+		return errRequired
+	}
+	if len(password) < 6 || len(password) > 64 {
+		return validationError{code: generated.ErrorCodePasswordLength}
 	}
 
 	var (
@@ -159,20 +484,58 @@ func validatePassword(password string) error {
 	if !hasCapital ||
 		!hasNumber ||
 		!hasSpecial {
-		return fmt.Errorf("password must had minimum 1 capital letter, 1 number and 1 special character")
+		return validationError{code: generated.ErrorCodePasswordComplexity}
 	}
 
 	return nil
 }
 
 func validatePhone(phone string) error {
+	if phone == "" {
+		return errRequired
+	}
 	if !strings.HasPrefix(phone, "+62") {
-		return fmt.Errorf("phone number must have prefix +62")
+		return validationError{code: generated.ErrorCodePhonePrefix}
 	}
 	phoneLen := len(strings.TrimPrefix(phone, "+62"))
 	if phoneLen < 10 || phoneLen > 13 {
-		return fmt.Errorf("phone number len must be greater than 10 and lower than 13")
+		return validationError{code: generated.ErrorCodePhoneLength}
 	}
 
 	return nil
 }
+
+// NormalizePhone canonicalizes user input phone number into +62 format, so
+// "081234567890", "6281234567890" and "+62 812-3456-7890" are all stored and
+// looked up as "+6281234567890". Unknown characters are kept untouched so the
+// result will still be rejected by validatePhone.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			// visual separator, drop it
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	p := b.String()
+	switch {
+	case strings.HasPrefix(p, "+620"):
+		// written as +62 (0)812...
+		return "+62" + p[4:]
+	case strings.HasPrefix(p, "+"):
+		return p
+	case strings.HasPrefix(p, "62"):
+		return "+" + p
+	case strings.HasPrefix(p, "0"):
+		return "+62" + p[1:]
+	}
+
+	return p
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
@@ -13,9 +15,20 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 )
 
+func TestMain(m *testing.M) {
+	// the signing keys are only loaded by the server, sign with a throwaway
+	// key so the suite can run without any certificate
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k, _ := newSigningKey(key)
+	signingKeys = keySet{k}
+
+	os.Exit(m.Run())
+}
+
 func TestServer_Register(t *testing.T) {
 	t.Parallel()
 
@@ -97,7 +110,7 @@ func TestServer_Register(t *testing.T) {
 					FindByPhone(gomock.Any(), gomock.Any()).
 					Return(repository.FindByPhoneOutput{
 						Id:       1,
-						Slug:     base64.RawStdEncoding.EncodeToString([]byte(input.Phone)),
+						PublicId: base64.RawStdEncoding.EncodeToString([]byte(input.Phone)),
 						FullName: input.FullName,
 						Phone:    input.Phone,
 						Password: input.Password,
@@ -105,6 +118,21 @@ func TestServer_Register(t *testing.T) {
 			},
 			expected: 409,
 		},
+		{
+			name: "request with local phone number format",
+			request: generated.RegistrationRequest{
+				FullName: "test case",
+				Password: "T3stv@lid",
+				Phone:    "0812-3456-7890",
+			},
+			mock: func(repo *repository.MockRepositoryInterface, input generated.RegistrationRequest) {
+				repo.EXPECT().
+					FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6281234567890"}).
+					Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+				repo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(repository.RegistrationOutput{Id: 1}, nil)
+			},
+			expected: 200,
+		},
 	}
 
 	ctrl := gomock.NewController(t)
@@ -152,7 +180,7 @@ func TestServer_Login(t *testing.T) {
 					FindByPhone(gomock.Any(), gomock.Any()).
 					Return(repository.FindByPhoneOutput{
 						Id:       1,
-						Slug:     "any",
+						PublicId: "any",
 						FullName: "any",
 						Phone:    input.Phone,
 						Password: string(p),
@@ -183,6 +211,57 @@ func TestServer_Login(t *testing.T) {
 			},
 			expected: 400,
 		},
+		{
+			name: "request with spaced international phone number format",
+			request: generated.LoginRequest{
+				Password: "secret",
+				Phone:    "+62 822-1377-0600",
+			},
+			mock: func(repo *repository.MockRepositoryInterface, input generated.LoginRequest) {
+				p, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+				repo.EXPECT().
+					FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6282213770600"}).
+					Return(repository.FindByPhoneOutput{
+						Id:       1,
+						PublicId: "any",
+						FullName: "any",
+						Phone:    "+6282213770600",
+						Password: string(p),
+					}, nil)
+			},
+			expected: 200,
+		},
+		{
+			name: "request with verified email address",
+			request: generated.LoginRequest{
+				Password: "secret",
+				Email:    " Budi@Example.COM ",
+			},
+			mock: func(repo *repository.MockRepositoryInterface, input generated.LoginRequest) {
+				p, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.DefaultCost)
+				repo.EXPECT().
+					FindByEmail(gomock.Any(), repository.FindByEmailInput{Email: "Budi@example.com"}).
+					Return(repository.FindByPhoneOutput{
+						Id:       1,
+						PublicId: "any",
+						FullName: "any",
+						Phone:    "+6282213770600",
+						Password: string(p),
+					}, nil)
+			},
+			expected: 200,
+		},
+		{
+			name: "request with both phone number and email address",
+			request: generated.LoginRequest{
+				Password: "secret",
+				Phone:    "+6282213770600",
+				Email:    "budi@example.com",
+			},
+			mock: func(repo *repository.MockRepositoryInterface, input generated.LoginRequest) {
+			},
+			expected: 400,
+		},
 	}
 
 	ctrl := gomock.NewController(t)
@@ -230,15 +309,15 @@ func TestServer_Profile(t *testing.T) {
 					FindByPhone(gomock.Any(), gomock.Any()).
 					Return(repository.FindByPhoneOutput{
 						Id:       1,
-						Slug:     "slug",
+						PublicId: "slug",
 						FullName: "full name",
 						Phone:    input.Phone,
 						Password: string(p),
 					}, nil)
 				repo.EXPECT().
-					FindBySlug(gomock.Any(), gomock.Any()).
-					Return(repository.FindBySlugOutput{
-						Slug:     "slug",
+					FindByPublicId(gomock.Any(), gomock.Any()).
+					Return(repository.FindByPublicIdOutput{
+						PublicId: "slug",
 						FullName: "full name",
 						Phone:    input.Phone,
 						Password: string(p),
@@ -298,10 +377,166 @@ func TestServer_Profile(t *testing.T) {
 
 			profileRec := httptest.NewRecorder()
 			ctx := e.NewContext(profileReq, profileRec)
-			profile := Middleware()(s.Profile)
+			profile := Middleware(MiddlewareOptions{})(s.Profile)
 
 			assert.NoError(t, profile(ctx))
 			assert.Equal(t, cases.expected, profileRec.Code)
 		})
 	}
 }
+
+func TestNormalizePhone(t *testing.T) {
+	t.Parallel()
+
+	var testCases = map[string]string{
+		"+6281234567890":       "+6281234567890",
+		"081234567890":         "+6281234567890",
+		"6281234567890":        "+6281234567890",
+		"+62 812-3456-7890":    "+6281234567890",
+		"+62 (0)812 3456 7890": "+6281234567890",
+		" 0812.3456.7890 ":     "+6281234567890",
+		"81234567890":          "81234567890",
+		"+62812abc":            "+62812abc",
+	}
+
+	for input, expected := range testCases {
+		assert.Equal(t, expected, NormalizePhone(input), input)
+	}
+}
+
+func TestServer_RegisterFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	s := NewServer(NewServerOptions{Repository: repository.NewMockRepositoryInterface(ctrl)})
+
+	b, _ := json.Marshal(generated.RegistrationRequest{
+		FullName: "test case",
+		Password: "testvalid",
+		Phone:    "81234567890",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	assert.NoError(t, s.Register(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var response generated.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "phone number must have prefix +62", response.Message)
+	if assert.NotNil(t, response.Errors) {
+		assert.Equal(t, []generated.FieldError{
+			{Field: "phone", Code: generated.ErrorCodePhonePrefix, Message: "phone number must have prefix +62"},
+			{Field: "password", Code: generated.ErrorCodePasswordComplexity, Message: "password must had minimum 1 capital letter, 1 number and 1 special character"},
+		}, *response.Errors)
+	}
+}
+
+func TestServer_PatchProfile(t *testing.T) {
+	t.Parallel()
+
+	current := repository.FindByPublicIdOutput{PublicId: "slug", FullName: "old name", Phone: "+6281234567890"}
+	name := "new name"
+
+	type Case struct {
+		name        string
+		contentType string
+		body        string
+		mock        func(repo *repository.MockRepositoryInterface)
+		expected    int
+	}
+	var testCases = []Case{
+		{
+			name:        "request changing full name only",
+			contentType: mimeMergePatchJSON,
+			body:        `{"full_name":"  new   name "}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+				repo.EXPECT().Patch(gomock.Any(), repository.PatchUserInput{PublicId: "slug", FullName: &name, Actor: authActor}).Return(repository.UpdateUserOutput{Version: 4}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:        "request changing phone only",
+			contentType: mimeMergePatchJSON,
+			body:        `{"phone":"089876543210"}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6289876543210"}).Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+				repo.EXPECT().StorePhoneChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expected: http.StatusAccepted,
+		},
+		{
+			name:        "request with the current phone",
+			contentType: mimeMergePatchJSON,
+			body:        `{"phone":"+62 812 3456 7890"}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:        "request removing a property",
+			contentType: mimeMergePatchJSON,
+			body:        `{"full_name":null}`,
+			mock:        func(repo *repository.MockRepositoryInterface) {},
+			expected:    http.StatusBadRequest,
+		},
+		{
+			name:        "request with unknown property",
+			contentType: mimeMergePatchJSON,
+			body:        `{"password":"T3stv@lid"}`,
+			mock:        func(repo *repository.MockRepositoryInterface) {},
+			expected:    http.StatusBadRequest,
+		},
+		{
+			name:        "request with invalid phone",
+			contentType: mimeMergePatchJSON,
+			body:        `{"phone":"123"}`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+			},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:        "request with malformed document",
+			contentType: mimeMergePatchJSON,
+			body:        `["full_name"]`,
+			mock:        func(repo *repository.MockRepositoryInterface) {},
+			expected:    http.StatusBadRequest,
+		},
+		{
+			name:        "request with plain json",
+			contentType: echo.MIMEApplicationJSON,
+			body:        `{"full_name":"new name"}`,
+			mock:        func(repo *repository.MockRepositoryInterface) {},
+			expected:    http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, SMSSender: &smsRecorder{}})
+			cases.mock(repo)
+
+			req := httptest.NewRequest(http.MethodPatch, "/profile", bytes.NewReader([]byte(cases.body)))
+			req.Header.Set(echo.HeaderContentType, cases.contentType)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.Set("user", map[string]any{"sub": "slug"})
+
+			assert.NoError(t, s.PatchProfile(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+		})
+	}
+}
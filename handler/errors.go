@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+)
+
+// validationError is a single input validation failure, identified by a
+// stable code so clients can localize it instead of parsing the message.
+type validationError struct {
+	code generated.ErrorCode
+}
+
+func (e validationError) Error() string {
+	return translate(defaultLanguage, e.code)
+}
+
+var errRequired = validationError{code: generated.ErrorCodeRequired}
+
+// fieldErrors collects validation failures of a request per field, so every
+// offending input is reported at once instead of stopping at the first one.
+type fieldErrors []generated.FieldError
+
+// add records err against field, nil err is ignored so validators can be
+// chained without checking each result.
+func (f *fieldErrors) add(field string, err error) {
+	if nil == err {
+		return
+	}
+
+	code := generated.ErrorCodeInvalid
+	var v validationError
+	if errors.As(err, &v) {
+		code = v.code
+	}
+
+	*f = append(*f, generated.FieldError{
+		Field:   field,
+		Code:    code,
+		Message: err.Error(),
+	})
+}
+
+// response builds the error body translated into lang, message keeps the
+// first failure for clients that don't read the field errors yet.
+func (f fieldErrors) response(lang string) generated.ErrorResponse {
+	errs := make([]generated.FieldError, 0, len(f))
+	for _, e := range f {
+		e.Message = translate(lang, e.Code)
+		errs = append(errs, e)
+	}
+
+	return generated.ErrorResponse{
+		Code:    errs[0].Code,
+		Message: errs[0].Message,
+		Errors:  &errs,
+	}
+}
+
+// write sends the collected failures as 400 response.
+func (f fieldErrors) write(ctx echo.Context) error {
+	lang := requestLanguage(ctx)
+	ctx.Response().Header().Set("Content-Language", lang)
+
+	return ctx.JSON(http.StatusBadRequest, f.response(lang))
+}
+
+// Error lists the failures in the default language, for callers outside of a
+// request such as the operator commands.
+func (f fieldErrors) Error() string {
+	messages := make([]string, 0, len(f))
+	for _, e := range f {
+		messages = append(messages, e.Field+": "+translate(defaultLanguage, e.Code))
+	}
+
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+)
+
+// profileETag is the strong entity tag of a profile, derived from the version
+// of the users row.
+func profileETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// matchETag reports whether the If-Match or If-None-Match header value lists
+// etag or the "*" wildcard. The weak comparison of If-None-Match compares weak
+// tags by their opaque value, the strong one of If-Match never matches them.
+func matchETag(header, etag string, weak bool) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatch evaluates the If-Match precondition of a profile write against the
+// current version. It returns the version the write has to be guarded with,
+// zero when the client sent no precondition, or the status and code to answer
+// with when the precondition fails.
+func (s *Server) ifMatch(ctx echo.Context, version int) (int, int, generated.ErrorCode) {
+	header := ctx.Request().Header.Get("If-Match")
+	if header == "" {
+		if s.RequireIfMatch {
+			return 0, http.StatusPreconditionRequired, generated.ErrorCodePreconditionRequired
+		}
+
+		return 0, 0, ""
+	}
+
+	if !matchETag(header, profileETag(version), false) {
+		return 0, http.StatusPreconditionFailed, generated.ErrorCodePreconditionFailed
+	}
+
+	return version, 0, ""
+}
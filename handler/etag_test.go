@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchETag(t *testing.T) {
+	t.Parallel()
+
+	for _, weak := range []bool{true, false} {
+		assert.True(t, matchETag(`"3"`, `"3"`, weak))
+		assert.True(t, matchETag(`"1", "3"`, `"3"`, weak))
+		assert.True(t, matchETag(`*`, `"3"`, weak))
+		assert.False(t, matchETag(`"2"`, `"3"`, weak))
+		assert.False(t, matchETag(``, `"3"`, weak))
+	}
+	assert.True(t, matchETag(`W/"3"`, `"3"`, true), "If-None-Match compares weakly")
+	assert.False(t, matchETag(`W/"3"`, `"3"`, false), "If-Match compares strongly")
+}
+
+func TestServer_ProfileETag(t *testing.T) {
+	t.Parallel()
+
+	for ifNoneMatch, expected := range map[string]int{
+		``:    http.StatusOK,
+		`"2"`: http.StatusOK,
+		`"3"`: http.StatusNotModified,
+	} {
+		ctrl := gomock.NewController(t)
+		e := echo.New()
+
+		repo := repository.NewMockRepositoryInterface(ctrl)
+		s := NewServer(NewServerOptions{Repository: repo})
+		repo.EXPECT().
+			FindByPublicId(gomock.Any(), gomock.Any()).
+			Return(repository.FindByPublicIdOutput{PublicId: "slug", FullName: "test case", Phone: "+6281234567890", Version: 3}, nil)
+
+		ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+		ctx.Request().Header.Set("If-None-Match", ifNoneMatch)
+
+		assert.NoError(t, s.Profile(ctx))
+		assert.Equal(t, expected, rec.Code, ifNoneMatch)
+		assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+		ctrl.Finish()
+	}
+}
+
+func TestServer_UpdateProfileIfMatch(t *testing.T) {
+	t.Parallel()
+
+	current := repository.FindByPublicIdOutput{PublicId: "slug", FullName: "old name", Phone: "+6281234567890", Version: 3}
+	request := generated.UpdateRequest{FullName: "new name", Phone: "+6281234567890"}
+
+	type Case struct {
+		name           string
+		ifMatch        string
+		requireIfMatch bool
+		mock           func(repo *repository.MockRepositoryInterface)
+		expected       int
+		etag           string
+	}
+	var testCases = []Case{
+		{
+			name:    "request matching the current version",
+			ifMatch: `"3"`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+				repo.EXPECT().Put(gomock.Any(), repository.UpdateUserInput{
+					PublicId: "slug",
+					FullName: "new name",
+					Phone:    "+6281234567890",
+					Version:  3,
+					Actor:    authActor,
+				}).Return(repository.UpdateUserOutput{Version: 4}, nil)
+			},
+			expected: http.StatusOK,
+			etag:     `"4"`,
+		},
+		{
+			name:    "request with stale version",
+			ifMatch: `"2"`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+			},
+			expected: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "request losing the race against another update",
+			ifMatch: `"3"`,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+				repo.EXPECT().Put(gomock.Any(), gomock.Any()).Return(repository.UpdateUserOutput{}, repository.ErrVersionConflict)
+			},
+			expected: http.StatusPreconditionFailed,
+		},
+		{
+			name: "request without If-Match",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+				repo.EXPECT().Put(gomock.Any(), repository.UpdateUserInput{
+					PublicId: "slug",
+					FullName: "new name",
+					Phone:    "+6281234567890",
+					Actor:    authActor,
+				}).Return(repository.UpdateUserOutput{Version: 5}, nil)
+			},
+			expected: http.StatusOK,
+			// another update landed between the read and this one
+			etag: `"5"`,
+		},
+		{
+			name:           "request without required If-Match",
+			requireIfMatch: true,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(current, nil)
+			},
+			expected: http.StatusPreconditionRequired,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, RequireIfMatch: cases.requireIfMatch})
+			cases.mock(repo)
+
+			ctx, rec := newAuthContext(e, http.MethodPut, request, "slug")
+			if cases.ifMatch != "" {
+				ctx.Request().Header.Set("If-Match", cases.ifMatch)
+			}
+
+			assert.NoError(t, s.UpdateProfile(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+			assert.Equal(t, cases.etag, rec.Header().Get("ETag"))
+		})
+	}
+}
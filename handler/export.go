@@ -0,0 +1,368 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/storage"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// exportTimeout bounds the generation of an export, a pending export
+	// older than that was lost with its instance and is started over
+	exportTimeout = 10 * time.Minute
+	// exportRetryAfter is the polling interval suggested in seconds
+	exportRetryAfter   = "5"
+	exportDownloadPath = "/profile/export/download"
+	exportHistoryPage  = 1000
+)
+
+// exportReadme describes the archive to its reader, keep it in sync with
+// writeExport.
+const exportReadme = `Personal data export
+
+Every .ndjson file holds one JSON object per line.
+
+profile.ndjson        the profile, its optional attributes, the avatar links
+                      and the account state. The password is only stored as
+                      a salted hash and is not exported.
+phone_changes.ndjson  every requested change of the phone number, whether it
+                      was confirmed or cancelled. The one time passwords and
+                      cancel tokens are only stored as hashes and are not
+                      exported.
+profile_history.ndjson
+                      every change of the profile with the values before and
+                      after, who made it and the address it came from.
+preferences.ndjson    the preferences of the user, the ones never set with
+                      their default.
+admin_actions.ndjson  every action of an administrator on the account, such as
+                      a suspension, with the reason given and who took it. The
+                      address of the administrator is not exported.
+
+Sessions, login history and consents are not stored by this service. Access
+tokens are stateless, only a counter revoking all of them at once is kept and
+it is part of the account state.
+`
+
+// exportProfile is the line of profile.ndjson.
+type exportProfile struct {
+	Id string `json:"id"`
+	generated.ProfileResponse
+	Status         string     `json:"status"`
+	SessionVersion int        `json:"session_version"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+}
+
+// exportAdminAction is a line of admin_actions.ndjson.
+type exportAdminAction struct {
+	Action    string    `json:"action"`
+	Admin     string    `json:"admin"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// exportPhoneChange is a line of phone_changes.ndjson.
+type exportPhoneChange struct {
+	Phone       string     `json:"phone"`
+	Attempts    int        `json:"attempts"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+func (s *Server) ExportProfile(ctx echo.Context) error {
+	var (
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		c        = ctx.Request().Context()
+	)
+
+	latest, err := s.Repository.FindLatestDataExport(c, repository.FindByPublicIdInput{PublicId: publicId})
+	switch {
+	case err == sql.ErrNoRows:
+	case nil != err:
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	case latest.Status == repository.DataExportReady && nil != latest.ExpiresAt && time.Now().Before(*latest.ExpiresAt):
+		link, err := exportURL(latest.Id, *latest.ExpiresAt)
+		if nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+
+		return ctx.JSON(http.StatusOK, generated.DataExportResponse{
+			Status:    generated.DataExportStatusReady,
+			Url:       &link,
+			ExpiresAt: latest.ExpiresAt,
+		})
+	case latest.Status == repository.DataExportPending && time.Since(latest.CreatedAt) < exportTimeout:
+		return exportPending(ctx)
+	}
+
+	export, err := s.Repository.StoreDataExport(c, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	// the request is over before the archive is, so it gets its own context
+	logger := ctx.Logger()
+	go func() {
+		if err := s.generateExport(publicId, export.Id); nil != err {
+			logger.Errorf("generating export %d: %s", export.Id, err)
+		}
+	}()
+
+	return exportPending(ctx)
+}
+
+func exportPending(ctx echo.Context) error {
+	ctx.Response().Header().Set(echo.HeaderRetryAfter, exportRetryAfter)
+
+	return ctx.JSON(http.StatusAccepted, generated.DataExportResponse{Status: generated.DataExportStatusPending})
+}
+
+func (s *Server) DownloadProfileExport(ctx echo.Context, params generated.DownloadProfileExportParams) error {
+	c := ctx.Request().Context()
+
+	id, err := parseExportToken(params.Token)
+	if nil != err {
+		return errorResponse(ctx, http.StatusForbidden, generated.ErrorCodeTokenInvalid)
+	}
+
+	export, err := s.Repository.FindDataExport(c, repository.DataExportInput{Id: id})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeExportNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	if export.Status != repository.DataExportReady || nil == export.BlobKey {
+		return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeExportNotFound)
+	}
+
+	r, err := s.Exports.Get(c, *export.BlobKey)
+	if nil != err {
+		if errors.Is(err, storage.ErrNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeExportNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+
+	ctx.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="profile-export.zip"`)
+
+	return ctx.Stream(http.StatusOK, "application/zip", r)
+}
+
+// generateExport writes the archive of the user into the export store and
+// marks the export ready, or failed when anything goes wrong.
+func (s *Server) generateExport(publicId string, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	err := s.storeExport(ctx, publicId, id)
+	if nil != err {
+		if failErr := s.Repository.FailDataExport(ctx, repository.DataExportInput{Id: id}); nil != failErr {
+			return fmt.Errorf("%w, marking it failed: %s", err, failErr)
+		}
+	}
+
+	return err
+}
+
+func (s *Server) storeExport(ctx context.Context, publicId string, id int) error {
+	var archive bytes.Buffer
+	if err := s.writeExport(ctx, &archive, publicId); nil != err {
+		return err
+	}
+
+	key, err := randomToken()
+	if nil != err {
+		return err
+	}
+	key += ".zip"
+	if err = s.Exports.Put(ctx, key, &archive, "application/zip"); nil != err {
+		return err
+	}
+
+	if err = s.Repository.CompleteDataExport(ctx, repository.CompleteDataExportInput{
+		Id:        id,
+		BlobKey:   key,
+		ExpiresAt: time.Now().Add(s.ExportTTL),
+	}); nil != err {
+		_ = s.Exports.Delete(ctx, key)
+		return err
+	}
+
+	return nil
+}
+
+// writeExport writes the ZIP archive of everything stored about the user.
+func (s *Server) writeExport(ctx context.Context, w io.Writer, publicId string) error {
+	profile, err := s.Repository.FindByPublicId(ctx, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return err
+	}
+	session, err := s.Repository.FindSession(ctx, repository.FindSessionInput{Subject: publicId})
+	if nil != err {
+		return err
+	}
+	changes, err := s.Repository.FindPhoneChanges(ctx, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return err
+	}
+
+	stored, err := s.Repository.FindPreferences(ctx, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return err
+	}
+	preferences, err := withPreferenceDefaults(stored)
+	if nil != err {
+		return err
+	}
+
+	var history []any
+	for before := int64(0); ; {
+		rows, err := s.Repository.FindProfileHistory(ctx, repository.FindProfileHistoryInput{
+			PublicId: publicId,
+			Before:   before,
+			Limit:    exportHistoryPage,
+		})
+		if nil != err {
+			return err
+		}
+		for _, row := range rows {
+			history = append(history, profileChange(row))
+		}
+		if len(rows) < exportHistoryPage {
+			break
+		}
+		before = rows[len(rows)-1].Id
+	}
+
+	phoneChanges := make([]any, 0, len(changes))
+	for _, change := range changes {
+		phoneChanges = append(phoneChanges, exportPhoneChange(change))
+	}
+
+	actions, err := s.Repository.FindAdminActions(ctx, repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return err
+	}
+	adminActions := make([]any, 0, len(actions))
+	for _, action := range actions {
+		adminActions = append(adminActions, exportAdminAction{
+			Action:    action.Action,
+			Admin:     action.Admin,
+			Reason:    action.Reason,
+			CreatedAt: action.CreatedAt,
+		})
+	}
+
+	z := zip.NewWriter(w)
+	for _, file := range []struct {
+		name  string
+		lines []any
+	}{
+		{name: "profile.ndjson", lines: []any{exportProfile{
+			Id:              profile.PublicId,
+			ProfileResponse: s.profileResponse(profile),
+			Status:          session.Status,
+			SessionVersion:  session.SessionVersion,
+			DeletedAt:       session.DeletedAt,
+		}}},
+		{name: "phone_changes.ndjson", lines: phoneChanges},
+		{name: "profile_history.ndjson", lines: history},
+		{name: "preferences.ndjson", lines: []any{preferences}},
+		{name: "admin_actions.ndjson", lines: adminActions},
+	} {
+		f, err := z.Create(file.name)
+		if nil != err {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		for _, line := range file.lines {
+			if err = enc.Encode(line); nil != err {
+				return err
+			}
+		}
+	}
+
+	f, err := z.Create("README.txt")
+	if nil != err {
+		return err
+	}
+	if _, err = io.WriteString(f, exportReadme); nil != err {
+		return err
+	}
+
+	return z.Close()
+}
+
+// PurgeExpiredExports deletes the exports whose download link expired
+// together with their archives, it returns how many were purged.
+func (s *Server) PurgeExpiredExports(ctx context.Context) (int, error) {
+	keys, err := s.Repository.PurgeExpiredDataExports(ctx, repository.PurgeExpiredDataExportsInput{
+		ExpiredBefore: time.Now(),
+	})
+	if nil != err {
+		return 0, err
+	}
+
+	return len(keys), s.deleteExports(ctx, keys)
+}
+
+// deleteExports removes the archives, it keeps going after a failure and
+// returns the first one.
+func (s *Server) deleteExports(ctx context.Context, keys []string) error {
+	var first error
+	for _, key := range keys {
+		if err := s.Exports.Delete(ctx, key); nil != err && nil == first {
+			first = fmt.Errorf("deleting export %s: %w", key, err)
+		}
+	}
+
+	return first
+}
+
+// exportURL is the download link of an export, it carries a token of its own
+// which expires with the export.
+func exportURL(id int, expiresAt time.Time) (string, error) {
+	token, err := signLink(jwt.MapClaims{"export": id}, expiresAt)
+	if nil != err {
+		return "", err
+	}
+
+	return exportDownloadPath + "?" + url.Values{"token": {token}}.Encode(), nil
+}
+
+// parseExportToken returns the export id of a download token. Access tokens
+// carry no export claim and are rejected.
+func parseExportToken(token string) (int, error) {
+	claims, err := parseLink(token)
+	if nil != err {
+		return 0, err
+	}
+
+	id, ok := claims["export"].(float64)
+	if !ok {
+		return 0, errors.New("not an export token")
+	}
+
+	return int(id), nil
+}
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ExportProfile(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	key := "0123456789abcdef0123456789abcdef.zip"
+
+	type Case struct {
+		name     string
+		latest   repository.DataExportOutput
+		err      error
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name:     "ready export",
+			latest:   repository.DataExportOutput{Id: 7, Status: repository.DataExportReady, BlobKey: &key, ExpiresAt: &expiresAt},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "export being generated",
+			latest:   repository.DataExportOutput{Id: 7, Status: repository.DataExportPending, CreatedAt: time.Now()},
+			expected: http.StatusAccepted,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, Exports: &blobRecorder{}})
+			repo.EXPECT().
+				FindLatestDataExport(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).
+				Return(cases.latest, cases.err)
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+			assert.NoError(t, s.ExportProfile(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			var response generated.DataExportResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			if cases.expected == http.StatusOK {
+				assert.Equal(t, generated.DataExportStatusReady, response.Status)
+				assert.Equal(t, &expiresAt, response.ExpiresAt)
+				if assert.NotNil(t, response.Url) {
+					link, err := url.Parse(*response.Url)
+					assert.NoError(t, err)
+					assert.Equal(t, exportDownloadPath, link.Path)
+					id, err := parseExportToken(link.Query().Get("token"))
+					assert.NoError(t, err)
+					assert.Equal(t, 7, id)
+				}
+			} else {
+				assert.Equal(t, generated.DataExportStatusPending, response.Status)
+				assert.Equal(t, exportRetryAfter, rec.Header().Get(echo.HeaderRetryAfter))
+			}
+		})
+	}
+}
+
+func TestServer_ExportProfileGenerates(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	confirmedAt := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	email := "budi@example.com"
+	exports := &blobRecorder{}
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo, Exports: exports, ExportTTL: time.Hour})
+
+	repo.EXPECT().FindLatestDataExport(gomock.Any(), gomock.Any()).Return(repository.DataExportOutput{}, sql.ErrNoRows)
+	repo.EXPECT().
+		StoreDataExport(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).
+		Return(repository.DataExportOutput{Id: 7, Status: repository.DataExportPending}, nil)
+	repo.EXPECT().FindByPublicId(gomock.Any(), gomock.Any()).Return(repository.FindByPublicIdOutput{
+		PublicId:          "slug",
+		FullName:          "Budi Santoso",
+		Phone:             "+6281234567890",
+		Password:          "$2a$10$hash",
+		ProfileAttributes: repository.ProfileAttributes{Email: &email},
+	}, nil)
+	repo.EXPECT().FindSession(gomock.Any(), gomock.Any()).Return(repository.FindSessionOutput{SessionVersion: 3, Status: repository.StatusActive}, nil)
+	repo.EXPECT().FindPhoneChanges(gomock.Any(), gomock.Any()).Return([]repository.FindPhoneChangesOutput{
+		{Phone: "+6281234567891", Attempts: 1, CreatedAt: confirmedAt, ExpiresAt: confirmedAt, ConfirmedAt: &confirmedAt},
+	}, nil)
+	repo.EXPECT().FindProfileHistory(gomock.Any(), gomock.Any()).Return([]repository.ProfileHistoryOutput{
+		{Id: 1, Field: "full_name", NewValue: &email, Actor: "slug", ChangedAt: confirmedAt},
+	}, nil)
+	repo.EXPECT().FindPreferences(gomock.Any(), gomock.Any()).Return(map[string]string{"units": `"imperial"`}, nil)
+	adminIP := "203.0.113.7"
+	repo.EXPECT().FindAdminActions(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).Return([]repository.AdminActionOutput{
+		{Action: repository.ActionSuspend, Admin: "admin", Reason: "reported for spam", SourceIP: &adminIP, CreatedAt: confirmedAt},
+	}, nil)
+
+	// the archive is generated after the response, wait for it
+	completed := make(chan repository.CompleteDataExportInput, 1)
+	repo.EXPECT().
+		CompleteDataExport(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.CompleteDataExportInput) error {
+			completed <- input
+			return nil
+		})
+
+	ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+	assert.NoError(t, s.ExportProfile(ctx))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	var input repository.CompleteDataExportInput
+	select {
+	case input = <-completed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("export was not completed")
+	}
+	assert.Equal(t, 7, input.Id)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), input.ExpiresAt, time.Minute)
+
+	exports.mu.Lock()
+	archive := exports.blobs[input.BlobKey]
+	exports.mu.Unlock()
+	z, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	files := map[string]string{}
+	for _, f := range z.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(r)
+		files[f.Name] = string(data)
+	}
+	assert.Contains(t, files, "README.txt")
+	assert.JSONEq(t, `{
+		"id": "slug",
+		"full_name": "Budi Santoso",
+		"phone": "+6281234567890",
+		"email": "budi@example.com",
+		"email_verified": false,
+		"status": "active",
+		"session_version": 3
+	}`, files["profile.ndjson"])
+	assert.NotContains(t, files["profile.ndjson"], "hash")
+	assert.JSONEq(t, `{
+		"phone": "+6281234567891",
+		"attempts": 1,
+		"created_at": "2023-06-01T00:00:00Z",
+		"expires_at": "2023-06-01T00:00:00Z",
+		"confirmed_at": "2023-06-01T00:00:00Z"
+	}`, files["phone_changes.ndjson"])
+	assert.JSONEq(t, `{
+		"field": "full_name",
+		"new_value": "budi@example.com",
+		"actor": "slug",
+		"changed_at": "2023-06-01T00:00:00Z"
+	}`, files["profile_history.ndjson"])
+	assert.JSONEq(t, `{
+		"language": "en",
+		"units": "imperial",
+		"notify_security": true,
+		"notify_product": true,
+		"marketing_opt_in": false
+	}`, files["preferences.ndjson"])
+	assert.JSONEq(t, `{
+		"action": "suspend",
+		"admin": "admin",
+		"reason": "reported for spam",
+		"created_at": "2023-06-01T00:00:00Z"
+	}`, files["admin_actions.ndjson"])
+	assert.Contains(t, files["README.txt"], "admin_actions.ndjson")
+}
+
+func TestServer_DownloadProfileExport(t *testing.T) {
+	t.Parallel()
+
+	key := "0123456789abcdef0123456789abcdef.zip"
+	link, err := exportURL(7, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	valid := strings.TrimPrefix(link, exportDownloadPath+"?token=")
+	expired, err := exportURL(7, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	access, err := Create(map[string]any{"sub": "slug"})
+	assert.NoError(t, err)
+
+	type Case struct {
+		name     string
+		token    string
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name:  "download of a ready export",
+			token: valid,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().
+					FindDataExport(gomock.Any(), repository.DataExportInput{Id: 7}).
+					Return(repository.DataExportOutput{Id: 7, Status: repository.DataExportReady, BlobKey: &key}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name:  "download of a purged export",
+			token: valid,
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindDataExport(gomock.Any(), gomock.Any()).Return(repository.DataExportOutput{}, sql.ErrNoRows)
+			},
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "download with an expired link",
+			token:    strings.TrimPrefix(expired, exportDownloadPath+"?token="),
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "download with an access token",
+			token:    access,
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusForbidden,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			exports := &blobRecorder{blobs: map[string][]byte{key: []byte("PK archive")}}
+			s := NewServer(NewServerOptions{Repository: repo, Exports: exports})
+			cases.mock(repo)
+
+			token, err := url.QueryUnescape(cases.token)
+			assert.NoError(t, err)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(httptest.NewRequest(http.MethodGet, exportDownloadPath, nil), rec)
+			assert.NoError(t, s.DownloadProfileExport(ctx, generated.DownloadProfileExportParams{Token: token}))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusOK {
+				assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, "PK archive", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMiddlewareRejectsExportToken(t *testing.T) {
+	t.Parallel()
+
+	link, err := exportURL(7, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	token, err := url.QueryUnescape(strings.TrimPrefix(link, exportDownloadPath+"?token="))
+	assert.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	handler := Middleware(MiddlewareOptions{})(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	assert.NoError(t, handler(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
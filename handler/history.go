@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"database/sql"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+func (s *Server) ProfileHistory(ctx echo.Context, params generated.ProfileHistoryParams) error {
+	publicId := ctx.Get("user").(map[string]any)["sub"].(string)
+
+	return s.profileHistory(ctx, publicId, params.Cursor, params.Limit)
+}
+
+func (s *Server) AdminProfileHistory(ctx echo.Context, id generated.PublicId, params generated.AdminProfileHistoryParams) error {
+	if status, code := s.requireAdmin(ctx); status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	user, err := s.Repository.FindSession(ctx.Request().Context(), repository.FindSessionInput{Subject: id})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeUserNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	return s.profileHistory(ctx, user.PublicId, params.Cursor, params.Limit)
+}
+
+// profileHistory answers a page of the history of the user.
+func (s *Server) profileHistory(ctx echo.Context, publicId string, cursor *string, limit *int) error {
+	var errs fieldErrors
+	before, n := parsePage(cursor, limit, &errs)
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	// one more than asked tells whether there is a next page
+	rows, err := s.Repository.FindProfileHistory(ctx.Request().Context(), repository.FindProfileHistoryInput{
+		PublicId: publicId,
+		Before:   before,
+		Limit:    n + 1,
+	})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	response := generated.ProfileHistoryResponse{Changes: make([]generated.ProfileChange, 0, n)}
+	if len(rows) > n {
+		rows = rows[:n]
+		next := strconv.FormatInt(rows[n-1].Id, 10)
+		response.NextCursor = &next
+	}
+	for _, row := range rows {
+		response.Changes = append(response.Changes, profileChange(row))
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// parsePage returns the keyset of a page, the id of the last item on the
+// previous page or zero, and the number of items on it.
+func parsePage(cursor *string, limit *int, errs *fieldErrors) (int64, int) {
+	var (
+		before int64
+		n      = defaultPageLimit
+	)
+	if nil != cursor {
+		var err error
+		if before, err = strconv.ParseInt(*cursor, 10, 64); nil != err || before <= 0 {
+			errs.add("cursor", validationError{code: generated.ErrorCodeInvalid})
+		}
+	}
+	if nil != limit {
+		if *limit < 1 || *limit > maxPageLimit {
+			errs.add("limit", validationError{code: generated.ErrorCodeInvalid})
+		}
+		n = *limit
+	}
+
+	return before, n
+}
+
+func profileChange(row repository.ProfileHistoryOutput) generated.ProfileChange {
+	return generated.ProfileChange{
+		Field:     row.Field,
+		OldValue:  row.OldValue,
+		NewValue:  row.NewValue,
+		Actor:     row.Actor,
+		SourceIp:  row.SourceIP,
+		ChangedAt: row.ChangedAt.UTC(),
+	}
+}
+
+// requireAdmin returns the status and code to answer with unless the user
+// calling is an administrator. It is looked up on every request, so taking
+// the rights away takes effect immediately.
+func (s *Server) requireAdmin(ctx echo.Context) (int, generated.ErrorCode) {
+	_, status, code := s.adminSession(ctx)
+
+	return status, code
+}
+
+// adminSession is requireAdmin also returning the session of the
+// administrator, for the rights beyond being one.
+func (s *Server) adminSession(ctx echo.Context) (repository.FindSessionOutput, int, generated.ErrorCode) {
+	publicId := ctx.Get("user").(map[string]any)["sub"].(string)
+
+	session, err := s.Repository.FindSession(ctx.Request().Context(), repository.FindSessionInput{Subject: publicId})
+	switch {
+	case err == sql.ErrNoRows:
+		return session, http.StatusForbidden, generated.ErrorCodeAdminRequired
+	case nil != err:
+		return session, http.StatusInternalServerError, generated.ErrorCodeInternalError
+	case !session.Admin:
+		return session, http.StatusForbidden, generated.ErrorCodeAdminRequired
+	}
+
+	return session, 0, ""
+}
+
+// requestActor records the user behind the request in the profile history.
+// The address comes from the IPExtractor of the server, one which does not
+// parse is left out rather than failing the change.
+func requestActor(ctx echo.Context, publicId string) repository.Actor {
+	actor := repository.Actor{PublicId: publicId}
+	if ip := net.ParseIP(ctx.RealIP()); nil != ip {
+		actor.IP = ip.String()
+	}
+
+	return actor
+}
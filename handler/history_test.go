@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ProfileHistory(t *testing.T) {
+	t.Parallel()
+
+	changedAt := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	oldName, newName, ip := "old name", "new name", "192.0.2.1"
+	rows := []repository.ProfileHistoryOutput{
+		{Id: 9, Field: "full_name", OldValue: &oldName, NewValue: &newName, Actor: "slug", SourceIP: &ip, ChangedAt: changedAt},
+		{Id: 5, Field: "gender", NewValue: &newName, Actor: "system", ChangedAt: changedAt},
+		{Id: 2, Field: "email", OldValue: &oldName, Actor: "slug", ChangedAt: changedAt},
+	}
+	cursor, limit, invalid, tooMany := "9", 2, "abc", 101
+
+	type Case struct {
+		name     string
+		params   generated.ProfileHistoryParams
+		input    repository.FindProfileHistoryInput
+		rows     []repository.ProfileHistoryOutput
+		expected int
+		changes  int
+		next     *string
+	}
+	var testCases = []Case{
+		{
+			name:     "first page with a next page",
+			params:   generated.ProfileHistoryParams{Limit: &limit},
+			input:    repository.FindProfileHistoryInput{PublicId: "slug", Limit: 3},
+			rows:     rows,
+			expected: http.StatusOK,
+			changes:  2,
+			next:     func() *string { next := "5"; return &next }(),
+		},
+		{
+			name:     "last page",
+			params:   generated.ProfileHistoryParams{Cursor: &cursor},
+			input:    repository.FindProfileHistoryInput{PublicId: "slug", Before: 9, Limit: 21},
+			rows:     rows[1:],
+			expected: http.StatusOK,
+			changes:  2,
+		},
+		{
+			name:     "empty history",
+			input:    repository.FindProfileHistoryInput{PublicId: "slug", Limit: 21},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "invalid cursor",
+			params:   generated.ProfileHistoryParams{Cursor: &invalid},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "limit above the maximum",
+			params:   generated.ProfileHistoryParams{Limit: &tooMany},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			if cases.expected == http.StatusOK {
+				repo.EXPECT().FindProfileHistory(gomock.Any(), cases.input).Return(cases.rows, nil)
+			}
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+			assert.NoError(t, s.ProfileHistory(ctx, cases.params))
+			assert.Equal(t, cases.expected, rec.Code)
+
+			if cases.expected == http.StatusOK {
+				var response generated.ProfileHistoryResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Len(t, response.Changes, cases.changes)
+				assert.Equal(t, cases.next, response.NextCursor)
+				assert.NotNil(t, response.Changes)
+			}
+		})
+	}
+}
+
+func TestServer_AdminProfileHistory(t *testing.T) {
+	t.Parallel()
+
+	target := "01H2X3Y4Z5A6B7C8D9E0F1G2H3"
+
+	type Case struct {
+		name     string
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name: "administrator",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).Return(repository.FindSessionOutput{PublicId: "slug", Admin: true}, nil)
+				repo.EXPECT().FindSession(gomock.Any(), repository.FindSessionInput{Subject: target}).Return(repository.FindSessionOutput{PublicId: target}, nil)
+				repo.EXPECT().
+					FindProfileHistory(gomock.Any(), repository.FindProfileHistoryInput{PublicId: target, Limit: 21}).
+					Return([]repository.ProfileHistoryOutput{{Id: 1, Field: "phone", Actor: target}}, nil)
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "user who is not an administrator",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).Return(repository.FindSessionOutput{PublicId: "slug"}, nil)
+			},
+			expected: http.StatusForbidden,
+		},
+		{
+			name: "unknown user",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).Return(repository.FindSessionOutput{PublicId: "slug", Admin: true}, nil)
+				repo.EXPECT().FindSession(gomock.Any(), repository.FindSessionInput{Subject: target}).Return(repository.FindSessionOutput{}, sql.ErrNoRows)
+			},
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			cases.mock(repo)
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+			assert.NoError(t, s.AdminProfileHistory(ctx, target, generated.AdminProfileHistoryParams{}))
+			assert.Equal(t, cases.expected, rec.Code)
+		})
+	}
+}
+
+func TestRequestActor(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	ctx, _ := newAuthContext(e, http.MethodGet, nil, "slug")
+	assert.Equal(t, authActor, requestActor(ctx, "slug"))
+
+	ctx.Request().Header.Set(echo.HeaderXForwardedFor, "not an address")
+	assert.Equal(t, repository.Actor{PublicId: "slug"}, requestActor(ctx, "slug"))
+}
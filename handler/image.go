@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+)
+
+// errTooManyPixels rejects images whose dimensions exceed the limit.
+var errTooManyPixels = errors.New("image has too many pixels")
+
+// decodeImage decodes a JPEG or PNG image into RGBA, upright according to its
+// EXIF orientation and flattened on white, dropping every metadata of the
+// original. Images above maxPixels are rejected before decoding, so a small
+// file can not make the server allocate gigabytes.
+func decodeImage(data []byte, maxPixels int) (*image.RGBA, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if nil != err {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, image.ErrFormat
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, errTooManyPixels
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if nil != err {
+		return nil, err
+	}
+
+	b := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	return orient(flat, jpegOrientation(data)), nil
+}
+
+// jpegOrientation returns the orientation tag of the EXIF segment of a JPEG,
+// 1 (upright) when there is none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// fill byte
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			// markers without length
+			i += 2
+			continue
+		case marker == 0xDA:
+			// start of scan, metadata always precedes it
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// tiffOrientation reads tag 0x0112 from the first IFD of a TIFF structure.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int64(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > int64(len(tiff)) {
+		return 1
+	}
+	entries := int64(order.Uint16(tiff[ifd:]))
+	for e := int64(0); e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > int64(len(tiff)) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+
+			return 1
+		}
+	}
+
+	return 1
+}
+
+// orient transforms src so that it displays upright for the EXIF orientation,
+// orientations 5 to 8 swap width and height.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if orientation >= 5 {
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° counter clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+
+	return dst
+}
+
+// thumbnail crops the centered square of src and scales it to size×size,
+// every target pixel averages the source pixels it covers.
+func thumbnail(src *image.RGBA, size int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	side := w
+	if h < side {
+		side = h
+	}
+	x0, y0 := (w-side)/2, (h-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		sy0, sy1 := span(y0, side, size, dy)
+		for dx := 0; dx < size; dx++ {
+			sx0, sx1 := span(x0, side, size, dx)
+
+			var sum [4]int
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					p := src.Pix[src.PixOffset(sx, sy):]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+
+			n := (sy1 - sy0) * (sx1 - sx0)
+			p := dst.Pix[dst.PixOffset(dx, dy):]
+			for c := range sum {
+				p[c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
+
+// span returns the source pixels [from, to) covered by target pixel i when
+// side source pixels starting at offset are scaled to size, at least one.
+func span(offset, side, size, i int) (int, int) {
+	from := offset + i*side/size
+	to := offset + (i+1)*side/size
+	if to <= from {
+		to = from + 1
+	}
+
+	return from, to
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); nil != err {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
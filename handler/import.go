@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// importBatchSize users are inserted per transaction
+	importBatchSize = 100
+	// maxImportRows bounds the rows of a single import, the file is read
+	// into memory by the request validation anyway
+	maxImportRows = 10000
+)
+
+// importColumns have to be named in the header of an import, in any order.
+var importColumns = []string{"full_name", "phone", "password"}
+
+func (s *Server) ImportUsers(ctx echo.Context, params generated.ImportUsersParams) error {
+	if status, code := s.requireAdmin(ctx); status != 0 {
+		return errorResponse(ctx, status, code)
+	}
+
+	lang := requestLanguage(ctx)
+	report, err := s.Import(ctx.Request().Context(), ctx.Request().Body, nil != params.DryRun && *params.DryRun, lang)
+	if nil != err {
+		var v validationError
+		if errors.As(err, &v) {
+			return errorResponse(ctx, http.StatusBadRequest, v.code)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	ctx.Response().Header().Set("Content-Language", lang)
+
+	return ctx.JSON(http.StatusOK, report)
+}
+
+// Import registers the users listed in the CSV file r, validated like
+// Register, with the messages of the report in lang. The whole file is
+// validated before the first batch is inserted, a file which is not CSV or
+// lacks a column fails without importing anything. When inserting a batch
+// fails the report covers the batches imported before it.
+func (s *Server) Import(ctx context.Context, r io.Reader, dryRun bool, lang string) (generated.ImportReport, error) {
+	rows, users, err := s.parseImport(r, lang)
+	if nil != err {
+		return generated.ImportReport{}, err
+	}
+
+	// users[i] belongs to the row at pending[i]
+	var pending []int
+	for i, row := range rows {
+		if row.Status == generated.ImportRowStatusCreated {
+			pending = append(pending, i)
+		}
+	}
+
+	for start := 0; start < len(users); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(users) {
+			end = len(users)
+		}
+
+		batch := users[start:end]
+		if !dryRun {
+			// hashing is what makes an import slow, a dry run skips it
+			for i := range batch {
+				p, _ := bcrypt.GenerateFromPassword([]byte(batch[i].Password), bcrypt.DefaultCost)
+				batch[i].Password = string(p)
+			}
+		}
+		out, err := s.Repository.ImportUsers(ctx, repository.ImportUsersInput{Users: batch, DryRun: dryRun})
+		if nil != err {
+			return importReport(rows[:pending[start]], dryRun), err
+		}
+
+		for i, created := range out.Created {
+			row := &rows[pending[start+i]]
+			switch {
+			case !created:
+				row.Status = generated.ImportRowStatusDuplicate
+			case !dryRun:
+				id := batch[i].PublicId
+				row.Id = &id
+			}
+		}
+	}
+
+	return importReport(rows, dryRun), nil
+}
+
+// parseImport validates every row of the file. The rows to register are
+// reported as created, with their users in the same order.
+func (s *Server) parseImport(r io.Reader, lang string) ([]generated.ImportRow, []repository.RegistrationInput, error) {
+	reader := csv.NewReader(r)
+	// short rows are reported as invalid instead of failing the file
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if nil != err {
+		return nil, nil, validationError{code: generated.ErrorCodeCsvInvalid}
+	}
+	columns, ok := importHeader(header)
+	if !ok {
+		return nil, nil, validationError{code: generated.ErrorCodeCsvInvalid}
+	}
+
+	var (
+		rows  []generated.ImportRow
+		users []repository.RegistrationInput
+		seen  = map[string]bool{}
+		now   = time.Now()
+	)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if nil != err {
+			return nil, nil, validationError{code: generated.ErrorCodeCsvInvalid}
+		}
+		if blankRecord(record) {
+			// spreadsheets export the empty rows below the data as well
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, nil, validationError{code: generated.ErrorCodeImportTooLarge}
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return record[i]
+			}
+
+			return ""
+		}
+		var errs fieldErrors
+		name, err := s.NameRules.normalize(field("full_name"))
+		errs.add("full_name", err)
+		phone := NormalizePhone(field("phone"))
+		errs.add("phone", validatePhone(phone))
+		errs.add("password", validatePassword(field("password")))
+
+		line, _ := reader.FieldPos(0)
+		row := generated.ImportRow{Line: line, Status: generated.ImportRowStatusCreated}
+		if phone != "" {
+			row.Phone = &phone
+		}
+		switch {
+		case len(errs) > 0:
+			row.Status = generated.ImportRowStatusInvalid
+			row.Errors = errs.response(lang).Errors
+		case seen[phone]:
+			row.Status = generated.ImportRowStatusDuplicate
+		default:
+			seen[phone] = true
+			publicId, err := NewPublicId(now)
+			if nil != err {
+				return nil, nil, err
+			}
+			users = append(users, repository.RegistrationInput{
+				PublicId: publicId,
+				FullName: name,
+				Phone:    phone,
+				Password: field("password"),
+			})
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, users, nil
+}
+
+// importHeader finds the importColumns in the header, ignoring case and a
+// byte order mark written by spreadsheets.
+func importHeader(header []string) (map[string]int, bool) {
+	columns := make(map[string]int, len(importColumns))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, name := range importColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, false
+		}
+	}
+
+	return columns, true
+}
+
+func blankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func importReport(rows []generated.ImportRow, dryRun bool) generated.ImportReport {
+	report := generated.ImportReport{DryRun: dryRun, Rows: rows}
+	if nil == report.Rows {
+		report.Rows = []generated.ImportRow{}
+	}
+	for _, row := range rows {
+		switch row.Status {
+		case generated.ImportRowStatusCreated:
+			report.Created++
+		case generated.ImportRowStatusDuplicate:
+			report.Duplicate++
+		case generated.ImportRowStatusInvalid:
+			report.Invalid++
+		}
+	}
+
+	return report
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestServer_Import(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+
+	file := "\ufeffPhone,Full_Name,password,village\n" +
+		"081234567890,  Budi   Santoso ,T3stv@lid,Sukamaju\n" +
+		"+6281234567891,Siti,weak,Sukamaju\n" +
+		"\n" +
+		",,,\n" +
+		"+62 812-3456-7890,Budi Santoso,T3stv@lid,Sukamaju\n" +
+		"081234567892,Ani,T3stv@lid\n"
+
+	repo.EXPECT().
+		ImportUsers(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.ImportUsersInput) (repository.ImportUsersOutput, error) {
+			assert.False(t, input.DryRun)
+			assert.Len(t, input.Users, 2)
+			assert.Equal(t, "Budi Santoso", input.Users[0].FullName)
+			assert.Equal(t, "+6281234567890", input.Users[0].Phone)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(input.Users[0].Password), []byte("T3stv@lid")))
+			assert.Equal(t, "+6281234567892", input.Users[1].Phone)
+			// the second one registered in the meantime
+			return repository.ImportUsersOutput{Created: []bool{true, false}}, nil
+		})
+
+	report, err := s.Import(context.Background(), strings.NewReader(file), false, "en")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 2, report.Duplicate)
+	assert.Equal(t, 1, report.Invalid)
+
+	assert.Len(t, report.Rows, 4)
+	assert.Equal(t, 2, report.Rows[0].Line)
+	assert.Equal(t, generated.ImportRowStatusCreated, report.Rows[0].Status)
+	assert.NotNil(t, report.Rows[0].Id)
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Equal(t, generated.ImportRowStatusInvalid, report.Rows[1].Status)
+	assert.Equal(t, []generated.FieldError{{Field: "password", Code: generated.ErrorCodePasswordLength, Message: translate("en", generated.ErrorCodePasswordLength)}}, *report.Rows[1].Errors)
+	assert.Equal(t, 6, report.Rows[2].Line)
+	assert.Equal(t, generated.ImportRowStatusDuplicate, report.Rows[2].Status)
+	assert.Equal(t, 7, report.Rows[3].Line)
+	assert.Equal(t, generated.ImportRowStatusDuplicate, report.Rows[3].Status)
+	assert.Nil(t, report.Rows[3].Id)
+}
+
+func TestServer_ImportBatches(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+
+	var file strings.Builder
+	file.WriteString("full_name,phone,password\n")
+	for i := 0; i < importBatchSize+1; i++ {
+		fmt.Fprintf(&file, "Budi,+62812340%05d,T3stv@lid\n", i)
+	}
+
+	// a dry run inserts the same batches, rolled back
+	gomock.InOrder(
+		repo.EXPECT().
+			ImportUsers(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input repository.ImportUsersInput) (repository.ImportUsersOutput, error) {
+				assert.True(t, input.DryRun)
+				assert.Len(t, input.Users, importBatchSize)
+				return repository.ImportUsersOutput{Created: make([]bool, importBatchSize)}, nil
+			}),
+		repo.EXPECT().
+			ImportUsers(gomock.Any(), gomock.Any()).
+			Return(repository.ImportUsersOutput{}, errors.New("connection reset")),
+	)
+
+	report, err := s.Import(context.Background(), strings.NewReader(file.String()), true, "en")
+	assert.Error(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Rows, importBatchSize)
+	assert.Equal(t, importBatchSize, report.Duplicate)
+}
+
+func TestServer_ImportInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	var testCases = map[string]struct {
+		file string
+		code generated.ErrorCode
+	}{
+		"empty":          {file: "", code: generated.ErrorCodeCsvInvalid},
+		"missing column": {file: "full_name,phone\nBudi,081234567890\n", code: generated.ErrorCodeCsvInvalid},
+		"bare quote":     {file: "full_name,phone,password\nBu\"di,081234567890,T3stv@lid\n", code: generated.ErrorCodeCsvInvalid},
+		"too many rows": {
+			file: "full_name,phone,password\n" + strings.Repeat("Budi,081234567890,T3stv@lid\n", maxImportRows+1),
+			code: generated.ErrorCodeImportTooLarge,
+		},
+	}
+
+	for name, cases := range testCases {
+		cases := cases
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := NewServer(NewServerOptions{Repository: repository.NewMockRepositoryInterface(ctrl)})
+			_, err := s.Import(context.Background(), strings.NewReader(cases.file), false, "en")
+			assert.Equal(t, validationError{code: cases.code}, err)
+		})
+	}
+}
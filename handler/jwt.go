@@ -1,8 +1,8 @@
 package handler
 
 import (
-	"crypto/rsa"
-	"fmt"
+	"context"
+	"errors"
 	"github.com/SawitProRecruitment/UserService/generated"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
@@ -12,30 +12,42 @@ import (
 	"time"
 )
 
-var (
-	defaultPrivateKey = "../cert/id_rsa"
-	privKey           *rsa.PrivateKey
-)
+const defaultPrivateKey = "../cert/id_rsa"
 
-func init() {
-	if os.Getenv("PRIVATE_KEY") != "" {
-		defaultPrivateKey = os.Getenv("PRIVATE_KEY")
-	}
-	p, err := os.ReadFile(defaultPrivateKey)
-	if nil != err {
-		fmt.Printf("failed reading private key. stack trace: %s\n", err)
+// PrivateKeyPath is the file the signing keys are loaded from by LoadKeys,
+// PRIVATE_KEY or ../cert/id_rsa.
+func PrivateKeyPath() string {
+	if path := os.Getenv("PRIVATE_KEY"); path != "" {
+		return path
 	}
 
-	privKey, err = jwt.ParseRSAPrivateKeyFromPEM(p)
-	if nil != err {
-		fmt.Printf("failed parsing private key. stack trace: %s\n", err)
-	}
+	return defaultPrivateKey
+}
+
+// SessionValidator decides whether a token with a valid signature is still
+// accepted for the user, see Server.ValidSession. It also returns the public
+// id of the user, tokens issued before public ids carry the legacy slug as
+// subject.
+type SessionValidator interface {
+	ValidSession(ctx context.Context, sub string, version int, issuedAt time.Time) (string, bool, error)
+}
+
+type MiddlewareOptions struct {
+	// Sessions rejects revoked tokens, nil accepts every token until it
+	// expires
+	Sessions SessionValidator
 }
 
-func Middleware() echo.MiddlewareFunc {
+func Middleware(opts MiddlewareOptions) echo.MiddlewareFunc {
 	var skippers = []string{
 		"/login",
 		"/register",
+		"/phone-changes/cancel",
+		// avatar keys are unguessable, the thumbnails are public
+		"/avatars/*",
+		// the download link carries a token of its own
+		"/profile/export/download",
+		"/email/verify",
 	}
 
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -47,28 +59,75 @@ func Middleware() echo.MiddlewareFunc {
 				}
 			}
 
-			token := strings.Trim(c.Request().Header.Get("Authorization"), "Bearer ")
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
 			if token == "" {
-				return c.JSON(http.StatusForbidden, generated.ErrorResponse{
-					Message: "missing or malformed jwt",
-				})
+				return errorResponse(c, http.StatusForbidden, generated.ErrorCodeTokenMissing)
 			}
 
-			parser, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-				return &privKey.PublicKey, nil
-			})
+			parser, err := signingKeys.parse(token)
 			if nil != err {
-				return c.JSON(http.StatusForbidden, "invalid or expired jwt")
+				return errorResponse(c, http.StatusForbidden, generated.ErrorCodeTokenInvalid)
 			}
 
+			// tokens signed for anything else, such as export downloads, carry
+			// no user
 			claims := parser.Claims.(jwt.MapClaims)
-			c.Set("user", claims["dat"])
+			dat, _ := claims["dat"].(map[string]any)
+			sub, _ := dat["sub"].(string)
+			if sub == "" {
+				return errorResponse(c, http.StatusForbidden, generated.ErrorCodeTokenInvalid)
+			}
+			if nil != opts.Sessions {
+				// tokens issued before session versions existed carry none
+				version, _ := dat["ver"].(float64)
+				var issuedAt time.Time
+				if iat, _ := claims.GetIssuedAt(); nil != iat {
+					issuedAt = iat.Time
+				}
+				publicId, valid, err := opts.Sessions.ValidSession(c.Request().Context(), sub, int(version), issuedAt)
+				if nil != err {
+					return errorResponse(c, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+				}
+				if !valid {
+					return errorResponse(c, http.StatusForbidden, generated.ErrorCodeTokenInvalid)
+				}
+				// the handlers only deal with public ids
+				dat["sub"] = publicId
+			}
+			c.Set("user", dat)
 
 			return next(c)
 		}
 	}
 }
 
+// signLink signs the claims of a link sent to the user, such as a download or
+// verification link. Links carry no dat claim, so Middleware never accepts
+// them as access tokens.
+func signLink(claims jwt.MapClaims, expiresAt time.Time) (string, error) {
+	now := time.Now().UTC()
+	claims["exp"] = expiresAt.Unix()
+	claims["iat"] = now.Unix()
+	claims["nbf"] = now.Unix()
+
+	return signingKeys.sign(claims)
+}
+
+// parseLink verifies a token of signLink and returns its claims.
+func parseLink(token string) (jwt.MapClaims, error) {
+	parsed, err := signingKeys.parse(token)
+	if nil != err {
+		return nil, err
+	}
+
+	claims := parsed.Claims.(jwt.MapClaims)
+	if exp, err := claims.GetExpirationTime(); nil != err || nil == exp {
+		return nil, errors.New("link without expiry")
+	}
+
+	return claims, nil
+}
+
 func Create(content any) (string, error) {
 	// get token expiry from environment
 	// default we will set token for one hour
@@ -85,12 +144,5 @@ func Create(content any) (string, error) {
 	claims["iat"] = now.Unix()
 	claims["nbf"] = now.Unix()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-
-	t, err := token.SignedString(privKey)
-	if nil != err {
-		return "", err
-	}
-
-	return t, nil
+	return signingKeys.sign(claims)
 }
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key types accepted by GenerateKey and RotateKeys.
+const (
+	KeyRSA     = "rsa"
+	KeyEd25519 = "ed25519"
+)
+
+// rsaKeyBits is the size of a generated RSA key
+const rsaKeyBits = 2048
+
+// signingKey signs tokens and links, named in their kid header by its id.
+type signingKey struct {
+	id     string
+	signer crypto.Signer
+	method jwt.SigningMethod
+}
+
+// keySet holds the current key, which signs, followed by the previous one,
+// which only verifies what was signed before the last rotation.
+type keySet []signingKey
+
+var signingKeys keySet
+
+func (k keySet) current() *signingKey {
+	if len(k) == 0 {
+		return nil
+	}
+
+	return &k[0]
+}
+
+// errKeyMismatch tells parse to try the next key.
+var errKeyMismatch = errors.New("token signed by another key")
+
+// parse verifies token with the key named by its kid header. Tokens
+// signed before keys were named carry none, every key is tried for them. The
+// algorithm always has to be the one of the key.
+func (k keySet) parse(token string) (*jwt.Token, error) {
+	if len(k) == 0 {
+		return nil, errors.New("no signing key loaded")
+	}
+
+	var (
+		parsed *jwt.Token
+		err    error
+	)
+	for _, key := range k {
+		key := key
+		parsed, err = jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+			if kid, ok := t.Header["kid"].(string); ok && kid != key.id {
+				return nil, errKeyMismatch
+			}
+			if t.Method.Alg() != key.method.Alg() {
+				return nil, errKeyMismatch
+			}
+
+			return key.signer.Public(), nil
+		})
+		if !errors.Is(err, errKeyMismatch) && !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			break
+		}
+	}
+
+	return parsed, err
+}
+
+// sign signs claims with the current key.
+func (k keySet) sign(claims jwt.MapClaims) (string, error) {
+	key := k.current()
+	if nil == key {
+		return "", errors.New("no signing key loaded")
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+
+	return token.SignedString(key.signer)
+}
+
+func newSigningKey(signer crypto.Signer) (signingKey, error) {
+	var method jwt.SigningMethod
+	switch signer.(type) {
+	case *rsa.PrivateKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return signingKey{}, fmt.Errorf("unsupported key type %T", signer)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if nil != err {
+		return signingKey{}, err
+	}
+	sum := sha256.Sum256(der)
+
+	return signingKey{id: base64.RawURLEncoding.EncodeToString(sum[:8]), signer: signer, method: method}, nil
+}
+
+// parseKeys reads every private key of a PEM file, either PKCS #1 RSA keys as
+// written by openssl genrsa or PKCS #8 RSA and Ed25519 keys.
+func parseKeys(data []byte) ([]signingKey, error) {
+	var keys []signingKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if nil == block {
+			break
+		}
+
+		var (
+			key any
+			err error
+		)
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		default:
+			err = fmt.Errorf("unsupported PEM block %q", block.Type)
+		}
+		if nil != err {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key type %T", key)
+		}
+		k, err := newSigningKey(signer)
+		if nil != err {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no private key found")
+	}
+
+	return keys, nil
+}
+
+// LoadKeys replaces the signing keys with the key at path, and the key which
+// RotateKeys kept next to it when there is one.
+func LoadKeys(path string) error {
+	keys, err := loadKeys(path)
+	if nil != err {
+		return err
+	}
+	signingKeys = keys
+
+	return nil
+}
+
+func loadKeys(path string) (keySet, error) {
+	data, err := os.ReadFile(path)
+	if nil != err {
+		return nil, err
+	}
+	keys, err := parseKeys(data)
+	if nil != err {
+		return nil, err
+	}
+	keys = keys[:1]
+
+	data, err = os.ReadFile(path + ".previous")
+	switch {
+	case nil == err:
+		previous, err := parseKeys(data)
+		if nil != err {
+			return nil, err
+		}
+		keys = append(keys, previous...)
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GenerateKey returns a new PKCS #8 private key of the type, KeyRSA or
+// KeyEd25519, PEM encoded for LoadKeys.
+func GenerateKey(keyType string) ([]byte, error) {
+	var (
+		key any
+		err error
+	)
+	switch keyType {
+	case KeyRSA:
+		key, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case KeyEd25519:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("unknown key type %q", keyType)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if nil != err {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// RotateKeys replaces the key at path with a new one of the type, the type of
+// the replaced key when empty. The replaced key is kept at path.previous so
+// the tokens and links it signed stay valid, the key kept before is dropped.
+// Keys must not be rotated more often than the longest token or link
+// lifetime. Running servers keep their keys until restarted.
+func RotateKeys(path, keyType string) error {
+	current, err := os.ReadFile(path)
+	if nil != err {
+		return err
+	}
+	keys, err := parseKeys(current)
+	if nil != err {
+		return fmt.Errorf("current key: %w", err)
+	}
+	if keyType == "" {
+		keyType = KeyRSA
+		if keys[0].method == jwt.SigningMethodEdDSA {
+			keyType = KeyEd25519
+		}
+	}
+	key, err := GenerateKey(keyType)
+	if nil != err {
+		return err
+	}
+
+	// the current key is kept first, a rotation failing halfway leaves it
+	// in place and still valid
+	if err = WriteKey(path+".previous", current); nil != err {
+		return err
+	}
+
+	return WriteKey(path, key)
+}
+
+// WriteKey replaces the file at path with key, readable by its owner only.
+// The file is swapped in whole, a server starting meanwhile never reads half
+// of a key.
+func WriteKey(path string, key []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+
+	if err = f.Chmod(0o600); nil == err {
+		_, err = f.Write(key)
+	}
+	if closeErr := f.Close(); nil == err {
+		err = closeErr
+	}
+	if nil != err {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
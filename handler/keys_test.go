@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateKeys(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "id_rsa")
+	// keys written by openssl genrsa before this service generated its own
+	legacy, _ := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(legacy)}), 0o600))
+
+	before, err := loadKeys(path)
+	assert.NoError(t, err)
+	assert.Len(t, before, 1)
+	claims := jwt.MapClaims{"sub": "slug", "exp": time.Now().Add(time.Hour).Unix()}
+	signed, err := before.sign(claims)
+	assert.NoError(t, err)
+	unnamed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(legacy)
+	assert.NoError(t, err)
+
+	assert.NoError(t, RotateKeys(path, KeyEd25519))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	after, err := loadKeys(path)
+	assert.NoError(t, err)
+	assert.Len(t, after, 2)
+	assert.Equal(t, jwt.SigningMethodEdDSA, after.current().method)
+	assert.Equal(t, before.current().id, after[1].id)
+
+	// tokens signed before the rotation, named or not, stay valid
+	for _, token := range []string{signed, unnamed} {
+		parsed, err := after.parse(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "slug", parsed.Claims.(jwt.MapClaims)["sub"])
+	}
+	fresh, err := after.sign(claims)
+	assert.NoError(t, err)
+	_, err = after.parse(fresh)
+	assert.NoError(t, err)
+
+	// the key before the previous one is dropped by the next rotation
+	assert.NoError(t, RotateKeys(path, KeyRSA))
+	again, err := loadKeys(path)
+	assert.NoError(t, err)
+	_, err = again.parse(fresh)
+	assert.NoError(t, err)
+	_, err = again.parse(signed)
+	assert.Error(t, err)
+}
+
+func TestKeySet_ParseWrongAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k, err := newSigningKey(key)
+	assert.NoError(t, err)
+	keys := keySet{k}
+
+	// a token signed with the public key as HMAC secret
+	public, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "slug"})
+	token.Header["kid"] = k.id
+	forged, err := token.SignedString(public)
+	assert.NoError(t, err)
+
+	_, err = keys.parse(forged)
+	assert.Error(t, err)
+}
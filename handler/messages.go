@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/language"
+)
+
+const defaultLanguage = "en"
+
+// messages is the catalog of every ErrorCode translation per language, a
+// language missing a code falls back into the default language.
+var messages = map[string]map[generated.ErrorCode]string{
+	"en": {
+		generated.ErrorCodeInvalid:               "value is invalid",
+		generated.ErrorCodeRequired:              "value is required",
+		generated.ErrorCodePhonePrefix:           "phone number must have prefix +62",
+		generated.ErrorCodePhoneLength:           "phone number len must be greater than 10 and lower than 13",
+		generated.ErrorCodePasswordLength:        "password must be greater than 6 and lower than 64",
+		generated.ErrorCodePasswordComplexity:    "password must had minimum 1 capital letter, 1 number and 1 special character",
+		generated.ErrorCodeInvalidRequest:        "invalid parameter request",
+		generated.ErrorCodeInvalidCredentials:    "unauthorized",
+		generated.ErrorCodeUserNotFound:          "user not found",
+		generated.ErrorCodePhoneTaken:            "phone number already exists",
+		generated.ErrorCodeTokenMissing:          "missing or malformed jwt",
+		generated.ErrorCodeTokenInvalid:          "invalid or expired jwt",
+		generated.ErrorCodeInternalError:         "Internal Server Error",
+		generated.ErrorCodeNameLength:            "full name must be between 1 and 60 characters",
+		generated.ErrorCodeNameCharacters:        "full name contains characters that are not allowed",
+		generated.ErrorCodePhoneChangeNotFound:   "no pending phone number change",
+		generated.ErrorCodeOtpInvalid:            "invalid verification code",
+		generated.ErrorCodeUnsupportedMediaType:  "unsupported media type",
+		generated.ErrorCodePreconditionFailed:    "resource was changed in the meantime, reload it and try again",
+		generated.ErrorCodePreconditionRequired:  "If-Match header is required",
+		generated.ErrorCodeEmailInvalid:          "email is not a valid address",
+		generated.ErrorCodeBirthDateInvalid:      "date of birth must be a date between 1900-01-01 and today",
+		generated.ErrorCodeGenderInvalid:         "gender must be female, male or other",
+		generated.ErrorCodePostalCodeInvalid:     "postal code is not valid for the country",
+		generated.ErrorCodeCountryInvalid:        "country must be an ISO 3166-1 alpha-2 code",
+		generated.ErrorCodeTooLong:               "value is too long",
+		generated.ErrorCodeAvatarType:            "image must be a JPEG or PNG",
+		generated.ErrorCodeAvatarInvalid:         "image is damaged or can not be read",
+		generated.ErrorCodeAvatarTooLarge:        "image is too large",
+		generated.ErrorCodeExportNotFound:        "export no longer exists",
+		generated.ErrorCodeEmailNotSet:           "email address is not set",
+		generated.ErrorCodeEmailVerified:         "email address is already verified",
+		generated.ErrorCodeEmailTaken:            "email address is already used",
+		generated.ErrorCodeEmailChanged:          "email address was changed",
+		generated.ErrorCodeAdminRequired:         "administrator rights required",
+		generated.ErrorCodeAccountSuspended:      "account is suspended",
+		generated.ErrorCodeStatusTransition:      "account can not change to this status",
+		generated.ErrorCodePasswordResetRequired: "password has to be changed",
+		generated.ErrorCodePasswordReused:        "new password must differ from the current one",
+		generated.ErrorCodeCsvInvalid:            "file must be a CSV file with the full_name, phone and password columns",
+		generated.ErrorCodeImportTooLarge:        "file has too many rows",
+	},
+	"id": {
+		generated.ErrorCodeInvalid:               "nilai tidak valid",
+		generated.ErrorCodeRequired:              "wajib diisi",
+		generated.ErrorCodePhonePrefix:           "nomor telepon harus diawali +62",
+		generated.ErrorCodePhoneLength:           "nomor telepon harus terdiri dari 10 sampai 13 digit setelah +62",
+		generated.ErrorCodePasswordLength:        "kata sandi harus terdiri dari 6 sampai 64 karakter",
+		generated.ErrorCodePasswordComplexity:    "kata sandi minimal memiliki 1 huruf kapital, 1 angka dan 1 karakter khusus",
+		generated.ErrorCodeInvalidRequest:        "parameter permintaan tidak valid",
+		generated.ErrorCodeInvalidCredentials:    "nomor telepon atau kata sandi salah",
+		generated.ErrorCodeUserNotFound:          "pengguna tidak ditemukan",
+		generated.ErrorCodePhoneTaken:            "nomor telepon sudah terdaftar",
+		generated.ErrorCodeTokenMissing:          "jwt tidak ada atau formatnya salah",
+		generated.ErrorCodeTokenInvalid:          "jwt tidak valid atau sudah kedaluwarsa",
+		generated.ErrorCodeInternalError:         "terjadi kesalahan pada server",
+		generated.ErrorCodeNameLength:            "nama lengkap harus terdiri dari 1 sampai 60 karakter",
+		generated.ErrorCodeNameCharacters:        "nama lengkap mengandung karakter yang tidak diperbolehkan",
+		generated.ErrorCodePhoneChangeNotFound:   "tidak ada perubahan nomor telepon yang menunggu verifikasi",
+		generated.ErrorCodeOtpInvalid:            "kode verifikasi salah",
+		generated.ErrorCodeUnsupportedMediaType:  "tipe konten tidak didukung",
+		generated.ErrorCodePreconditionFailed:    "data telah diubah, muat ulang lalu coba lagi",
+		generated.ErrorCodePreconditionRequired:  "header If-Match wajib diisi",
+		generated.ErrorCodeEmailInvalid:          "email tidak valid",
+		generated.ErrorCodeBirthDateInvalid:      "tanggal lahir harus di antara 1900-01-01 dan hari ini",
+		generated.ErrorCodeGenderInvalid:         "jenis kelamin harus female, male atau other",
+		generated.ErrorCodePostalCodeInvalid:     "kode pos tidak valid untuk negara tersebut",
+		generated.ErrorCodeCountryInvalid:        "negara harus berupa kode ISO 3166-1 alpha-2",
+		generated.ErrorCodeTooLong:               "nilai terlalu panjang",
+		generated.ErrorCodeAvatarType:            "gambar harus berformat JPEG atau PNG",
+		generated.ErrorCodeAvatarInvalid:         "gambar rusak atau tidak dapat dibaca",
+		generated.ErrorCodeAvatarTooLarge:        "ukuran gambar terlalu besar",
+		generated.ErrorCodeExportNotFound:        "ekspor sudah tidak tersedia",
+		generated.ErrorCodeEmailNotSet:           "alamat email belum diisi",
+		generated.ErrorCodeEmailVerified:         "alamat email sudah terverifikasi",
+		generated.ErrorCodeEmailTaken:            "alamat email sudah digunakan",
+		generated.ErrorCodeEmailChanged:          "alamat email sudah diubah",
+		generated.ErrorCodeAdminRequired:         "memerlukan hak administrator",
+		generated.ErrorCodeAccountSuspended:      "akun ditangguhkan",
+		generated.ErrorCodeStatusTransition:      "status akun tidak dapat diubah ke status ini",
+		generated.ErrorCodePasswordResetRequired: "kata sandi harus diganti",
+		generated.ErrorCodePasswordReused:        "kata sandi baru harus berbeda dari yang sekarang",
+		generated.ErrorCodeCsvInvalid:            "berkas harus berupa CSV dengan kolom full_name, phone dan password",
+		generated.ErrorCodeImportTooLarge:        "berkas memiliki terlalu banyak baris",
+	},
+}
+
+// languageMatcher lists the supported languages, the first one is the
+// fallback for clients asking for anything else.
+var languageMatcher = language.NewMatcher([]language.Tag{
+	language.English,
+	language.Indonesian,
+})
+
+// requestLanguage picks the catalog language from the Accept-Language header.
+func requestLanguage(ctx echo.Context) string {
+	tag, _ := language.MatchStrings(languageMatcher, ctx.Request().Header.Get("Accept-Language"))
+	base, _ := tag.Base()
+	if _, ok := messages[base.String()]; !ok {
+		return defaultLanguage
+	}
+
+	return base.String()
+}
+
+// translate returns the message of code in lang.
+func translate(lang string, code generated.ErrorCode) string {
+	if m, ok := messages[lang][code]; ok {
+		return m
+	}
+
+	return messages[defaultLanguage][code]
+}
+
+// errorResponse writes ErrorResponse of code, translated into the language
+// requested by the client.
+func errorResponse(ctx echo.Context, status int, code generated.ErrorCode) error {
+	lang := requestLanguage(ctx)
+	ctx.Response().Header().Set("Content-Language", lang)
+
+	return ctx.JSON(status, generated.ErrorResponse{
+		Code:    code,
+		Message: translate(lang, code),
+	})
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessagesCoverEveryErrorCode(t *testing.T) {
+	t.Parallel()
+
+	swagger, err := generated.GetSwagger()
+	assert.NoError(t, err)
+
+	var codes []generated.ErrorCode
+	for _, v := range swagger.Components.Schemas["ErrorCode"].Value.Enum {
+		codes = append(codes, generated.ErrorCode(v.(string)))
+	}
+	assert.NotEmpty(t, codes)
+
+	for lang, catalog := range messages {
+		for _, code := range codes {
+			assert.NotEmpty(t, catalog[code], "%s catalog is missing %s", lang, code)
+		}
+		assert.Len(t, catalog, len(codes), "%s catalog has undocumented codes", lang)
+	}
+}
+
+func TestRequestLanguage(t *testing.T) {
+	t.Parallel()
+
+	var testCases = map[string]string{
+		"":                          "en",
+		"id":                        "id",
+		"id-ID,id;q=0.9,en;q=0.8":   "id",
+		"en-US,en;q=0.9,id;q=0.8":   "en",
+		"fr-FR":                     "en",
+		"fr-FR,id;q=0.5":            "id",
+		"invalid header;;q=garbage": "en",
+	}
+
+	e := echo.New()
+	for header, expected := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", header)
+
+		assert.Equal(t, expected, requestLanguage(e.NewContext(req, httptest.NewRecorder())), header)
+	}
+}
+
+func TestErrorResponseTranslated(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"phone":"81234567890","password":"secret"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Accept-Language", "id-ID")
+	rec := httptest.NewRecorder()
+
+	s := NewServer(NewServerOptions{})
+	assert.NoError(t, s.Login(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "id", rec.Header().Get("Content-Language"))
+
+	var response generated.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, generated.ErrorCodePhonePrefix, response.Code)
+	assert.Equal(t, "nomor telepon harus diawali +62", response.Message)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NameRules configures how full names are normalized and validated.
+type NameRules struct {
+	// MinLength and MaxLength bound the name length counted in runes after
+	// normalization, MaxLength must not exceed the users.full_name column.
+	MinLength int
+	MaxLength int
+	// Scripts lists the unicode script names, see unicode.Scripts, the
+	// letters of a name may be written in. Empty allows every script.
+	Scripts []string
+}
+
+// DefaultNameRules is used when the server is created without NameRules.
+var DefaultNameRules = NameRules{
+	MinLength: 1,
+	MaxLength: 60,
+	Scripts:   []string{"Latin"},
+}
+
+// nameSymbols are the non letter characters commonly found in names, such as
+// "Abdul-Rahman", "O'Neil" or "Ir. Soekarno, S.T.".
+const nameSymbols = "'-.,"
+
+// maxCombiningMarks bounds the combining marks stacked on a single letter,
+// enough for the scripts that need them but not for zalgo text.
+const maxCombiningMarks = 3
+
+// normalize canonicalizes name into NFC and collapses every run of white
+// space into a single space, then validates the result against the rules.
+func (r NameRules) normalize(name string) (string, error) {
+	name = strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+
+	length := utf8.RuneCountInString(name)
+	if length == 0 {
+		return name, errRequired
+	}
+	if length < r.MinLength || length > r.MaxLength {
+		return name, validationError{code: generated.ErrorCodeNameLength}
+	}
+
+	// combining marks without precomposed form are kept by NFC, they are
+	// only accepted on a letter of an allowed script
+	var letter bool
+	var marks int
+	for _, c := range name {
+		if unicode.Is(unicode.Mn, c) {
+			marks++
+			if !letter || marks > maxCombiningMarks {
+				return name, validationError{code: generated.ErrorCodeNameCharacters}
+			}
+			continue
+		}
+
+		switch {
+		case c == ' ' || strings.ContainsRune(nameSymbols, c):
+			letter = false
+		case unicode.IsLetter(c) && r.allowScript(c):
+			letter = true
+		default:
+			return name, validationError{code: generated.ErrorCodeNameCharacters}
+		}
+		marks = 0
+	}
+
+	return name, nil
+}
+
+// Validate reports the first of Scripts which is not a unicode script name.
+func (r NameRules) Validate() error {
+	for _, script := range r.Scripts {
+		if _, ok := unicode.Scripts[script]; !ok {
+			return fmt.Errorf("unknown unicode script %q", script)
+		}
+	}
+
+	return nil
+}
+
+func (r NameRules) allowScript(c rune) bool {
+	if len(r.Scripts) == 0 {
+		return true
+	}
+
+	for _, script := range r.Scripts {
+		if table, ok := unicode.Scripts[script]; ok && unicode.Is(table, c) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameRules_normalize(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		rules    NameRules
+		input    string
+		expected string
+		code     generated.ErrorCode
+	}
+	var testCases = []Case{
+		{
+			name:     "collapse white space",
+			rules:    DefaultNameRules,
+			input:    "  Budi \t  Santoso\n",
+			expected: "Budi Santoso",
+		},
+		{
+			name:     "compose decomposed characters",
+			rules:    DefaultNameRules,
+			input:    "José Pérez",
+			expected: "José Pérez",
+		},
+		{
+			name:     "allow name punctuation",
+			rules:    DefaultNameRules,
+			input:    "Ir. Abdul-Rahman O'Neil, S.T.",
+			expected: "Ir. Abdul-Rahman O'Neil, S.T.",
+		},
+		{
+			name:  "reject empty name",
+			rules: DefaultNameRules,
+			input: " \t ",
+			code:  generated.ErrorCodeRequired,
+		},
+		{
+			name:     "count length in runes",
+			rules:    DefaultNameRules,
+			input:    strings.Repeat("é", 60),
+			expected: strings.Repeat("é", 60),
+		},
+		{
+			name:  "reject too long name",
+			rules: DefaultNameRules,
+			input: strings.Repeat("a", 61),
+			code:  generated.ErrorCodeNameLength,
+		},
+		{
+			name:  "reject control character",
+			rules: DefaultNameRules,
+			input: "Budi\x00Santoso",
+			code:  generated.ErrorCodeNameCharacters,
+		},
+		{
+			name:  "reject digits",
+			rules: DefaultNameRules,
+			input: "Budi 2",
+			code:  generated.ErrorCodeNameCharacters,
+		},
+		{
+			name:  "reject script outside the rules",
+			rules: DefaultNameRules,
+			input: "王小明",
+			code:  generated.ErrorCodeNameCharacters,
+		},
+		{
+			name:     "allow configured script",
+			rules:    NameRules{MinLength: 1, MaxLength: 60, Scripts: []string{"Latin", "Han"}},
+			input:    "Wang 王小明",
+			expected: "Wang 王小明",
+		},
+		{
+			name:     "allow combining marks on a letter",
+			rules:    DefaultNameRules,
+			input:    "Nguyễn Thị Ngọc Ánh Spın\u0308al Tap",
+			expected: "Nguyễn Thị Ngọc Ánh Spın\u0308al Tap",
+		},
+		{
+			name:  "reject combining mark without a letter",
+			rules: DefaultNameRules,
+			input: "Budi \u0301Santoso",
+			code:  generated.ErrorCodeNameCharacters,
+		},
+		{
+			name:  "reject combining mark on a symbol",
+			rules: DefaultNameRules,
+			input: "O'\u0301Neil",
+			code:  generated.ErrorCodeNameCharacters,
+		},
+		{
+			name:  "reject stacked combining marks",
+			rules: DefaultNameRules,
+			input: "Bud\u0316\u0317\u0318\u0319i",
+			code:  generated.ErrorCodeNameCharacters,
+		},
+		{
+			name:  "reject too short name",
+			rules: NameRules{MinLength: 3, MaxLength: 60},
+			input: "Al",
+			code:  generated.ErrorCodeNameLength,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			name, err := cases.rules.normalize(cases.input)
+			if cases.code != "" {
+				var v validationError
+				if assert.ErrorAs(t, err, &v) {
+					assert.Equal(t, cases.code, v.code)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, cases.expected, name)
+		})
+	}
+}
+
+func TestNameRules_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, NameRules{Scripts: []string{"Latin", "Han"}}.Validate())
+	assert.NoError(t, NameRules{}.Validate())
+	assert.Error(t, NameRules{Scripts: []string{"Latin", "Klingon"}}.Validate())
+}
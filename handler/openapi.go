@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	// export archives are streamed by the handler, see DownloadProfileExport
+	openapi3filter.RegisterBodyDecoder("application/zip", openapi3filter.FileBodyDecoder)
+	// merge patch documents are plain json, see RFC 7396
+	openapi3filter.RegisterBodyDecoder(mimeMergePatchJSON, openapi3filter.RegisteredBodyDecoder(echo.MIMEApplicationJSON))
+	// avatar parts are validated by the handler, see UploadAvatar
+	openapi3filter.RegisterBodyDecoder("image/jpeg", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("image/png", openapi3filter.FileBodyDecoder)
+	// imports are parsed and exports streamed by the handlers, see
+	// ImportUsers and ExportUsers
+	openapi3filter.RegisterBodyDecoder("text/csv", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder(mimeNDJSON, openapi3filter.FileBodyDecoder)
+}
+
+type OpenAPIMiddlewareOptions struct {
+	// Strict also validates every response against the documented schema and
+	// replaces a drifting response with 500, streamed responses are passed
+	// through. It is meant for tests and development, never enable it in
+	// production.
+	Strict bool
+}
+
+// OpenAPIMiddleware validates incoming requests against the specification
+// embedded in the generated package before they reach the ServerInterface.
+// Requests to paths that are not documented are passed through untouched so
+// echo can answer them with its own 404 or 405.
+func OpenAPIMiddleware(opts OpenAPIMiddlewareOptions) echo.MiddlewareFunc {
+	swagger, err := generated.GetSwagger()
+	if nil != err {
+		panic(err)
+	}
+	// servers only describe where the spec is hosted, matching on them will
+	// reject requests coming through any other host name
+	swagger.Servers = nil
+
+	router, err := legacy.NewRouter(swagger)
+	if nil != err {
+		panic(err)
+	}
+
+	options := &openapi3filter.Options{
+		MultiError: true,
+		// authentication is handled by the jwt middleware
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, pathParams, err := router.FindRoute(req)
+			if nil != err {
+				return next(c)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err = openapi3filter.ValidateRequest(req.Context(), input); nil != err {
+				return requestError(c, err)
+			}
+
+			if !opts.Strict || streamed(route.Operation) {
+				return next(c)
+			}
+
+			return validateResponse(c, next, input)
+		}
+	}
+}
+
+// streamedTypes are the media types written while they are produced, holding
+// them back for validation would break flushing and keep them in memory.
+var streamedTypes = map[string]bool{
+	"application/zip": true,
+	"text/csv":        true,
+	mimeNDJSON:        true,
+}
+
+// streamed tells whether the operation answers with a stream.
+func streamed(operation *openapi3.Operation) bool {
+	response := operation.Responses.Get(http.StatusOK)
+	if nil == response || nil == response.Value {
+		return false
+	}
+	for mime := range response.Value.Content {
+		if streamedTypes[mime] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateResponse buffers the handler response and only sends it when it
+// matches the specification.
+func validateResponse(c echo.Context, next echo.HandlerFunc, input *openapi3filter.RequestValidationInput) error {
+	res := c.Response()
+	writer := res.Writer
+	buf := &bufferedWriter{header: writer.Header()}
+	res.Writer = buf
+	defer func() {
+		res.Writer = writer
+	}()
+
+	if err := next(c); nil != err {
+		buf.flush(writer)
+		return err
+	}
+
+	err := openapi3filter.ValidateResponse(c.Request().Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 buf.status,
+		Header:                 buf.header,
+		Body:                   io.NopCloser(bytes.NewReader(buf.body.Bytes())),
+		Options: &openapi3filter.Options{
+			MultiError:            true,
+			IncludeResponseStatus: true,
+		},
+	})
+	if nil != err {
+		c.Logger().Errorf("response of %s %s does not match the specification: %s", c.Request().Method, c.Path(), err)
+		res.Status = http.StatusInternalServerError
+
+		writer.Header().Del(echo.HeaderContentLength)
+		writer.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+		writer.WriteHeader(http.StatusInternalServerError)
+
+		return c.Echo().JSONSerializer.Serialize(c, generated.ErrorResponse{
+			Code:    generated.ErrorCodeInternalError,
+			Message: "response does not match the specification: " + err.Error(),
+		}, "")
+	}
+
+	buf.flush(writer)
+
+	return nil
+}
+
+// requestError answers a request validation failure, schema violations are
+// reported per field.
+func requestError(c echo.Context, err error) error {
+	var errs fieldErrors
+	collectSchemaErrors(err, &errs)
+	if len(errs) == 0 {
+		return errorResponse(c, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	return errs.write(c)
+}
+
+func collectSchemaErrors(err error, errs *fieldErrors) {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		for _, e := range multi {
+			collectSchemaErrors(e, errs)
+		}
+		return
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return
+	}
+
+	code := generated.ErrorCodeInvalid
+	if schemaErr.SchemaField == "required" {
+		code = generated.ErrorCodeRequired
+	}
+	errs.add(strings.Join(schemaErr.JSONPointer(), "."), validationError{code: code})
+}
+
+// bufferedWriter holds back the response until it is validated.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) flush(writer http.ResponseWriter) {
+	if w.status == 0 {
+		return
+	}
+
+	writer.WriteHeader(w.status)
+	_, _ = writer.Write(w.body.Bytes())
+}
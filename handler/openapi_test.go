@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPIMiddleware(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		strict   bool
+		body     string
+		response any
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name:     "request matching the specification",
+			body:     `{"phone":"+6281234567890","password":"secret"}`,
+			response: generated.LoginResponse{Id: "01H2X3Y4Z5A6B7C8D9E0F1G2H3", Token: "token"},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "request missing required property",
+			body:     `{"password":"secret"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "request with mistyped property",
+			body:     `{"phone":6281234567890,"password":"secret"}`,
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "drifting response outside strict mode",
+			body:     `{"phone":"+6281234567890","password":"secret"}`,
+			response: map[string]any{"id": "1"},
+			expected: http.StatusOK,
+		},
+		{
+			name:     "drifting response in strict mode",
+			strict:   true,
+			body:     `{"phone":"+6281234567890","password":"secret"}`,
+			response: map[string]any{"id": "1"},
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name:     "matching response in strict mode",
+			strict:   true,
+			body:     `{"phone":"+6281234567890","password":"secret"}`,
+			response: generated.LoginResponse{Id: "01H2X3Y4Z5A6B7C8D9E0F1G2H3", Token: "token"},
+			expected: http.StatusOK,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(OpenAPIMiddleware(OpenAPIMiddlewareOptions{Strict: cases.strict}))
+			e.POST("/login", func(c echo.Context) error {
+				return c.JSON(http.StatusOK, cases.response)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(cases.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, cases.expected, rec.Code)
+		})
+	}
+}
+
+func TestOpenAPIMiddlewareFieldErrors(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.Use(OpenAPIMiddleware(OpenAPIMiddlewareOptions{}))
+	e.POST("/register", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"full_name":"test case"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var response generated.ErrorResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	if assert.NotNil(t, response.Errors) {
+		var fields []string
+		for _, e := range *response.Errors {
+			assert.Equal(t, generated.ErrorCodeRequired, e.Code)
+			fields = append(fields, e.Field)
+		}
+		assert.ElementsMatch(t, []string{"phone", "password"}, fields)
+	}
+}
+
+func TestOpenAPIMiddlewareMergePatch(t *testing.T) {
+	t.Parallel()
+
+	e := echo.New()
+	e.Use(OpenAPIMiddleware(OpenAPIMiddlewareOptions{}))
+	e.PATCH("/profile", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for body, expected := range map[string]int{
+		`{"full_name":"new name"}`: http.StatusOK,
+		`{}`:                       http.StatusBadRequest,
+		`{"full_name":null}`:       http.StatusBadRequest,
+		`{"address":null}`:         http.StatusOK,
+		`{"gender":"unknown"}`:     http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/profile", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, mimeMergePatchJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, expected, rec.Code, body)
+	}
+}
+
+func TestOpenAPIMiddlewareAfterAuthentication(t *testing.T) {
+	t.Parallel()
+
+	// in the order of serve
+	e := echo.New()
+	e.Use(Middleware(MiddlewareOptions{}))
+	e.Use(OpenAPIMiddleware(OpenAPIMiddlewareOptions{}))
+	e.POST("/login", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.PATCH("/profile", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	type Case struct {
+		name     string
+		method   string
+		path     string
+		code     generated.ErrorCode
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name:     "invalid request without token",
+			method:   http.MethodPatch,
+			path:     "/profile",
+			code:     generated.ErrorCodeTokenMissing,
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "invalid request to a public operation",
+			method:   http.MethodPost,
+			path:     "/login",
+			code:     generated.ErrorCodeRequired,
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			req := httptest.NewRequest(cases.method, cases.path, strings.NewReader(`{}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, cases.expected, rec.Code)
+			var response generated.ErrorResponse
+			assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			assert.Equal(t, cases.code, response.Code)
+		})
+	}
+}
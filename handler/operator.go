@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The methods below back the operator commands, see cmd. They validate like
+// the endpoints and return the failures as errors, fieldErrors for invalid
+// input and validationError for the rest.
+
+// CreateUserInput is an account created by an operator.
+type CreateUserInput struct {
+	FullName string
+	Phone    string
+	Password string
+	// Admin appoints the user administrator, which no endpoint does
+	Admin bool
+}
+
+// CreateUser registers the user like Register and returns its public id.
+func (s *Server) CreateUser(ctx context.Context, input CreateUserInput) (string, error) {
+	var (
+		errs fieldErrors
+		err  error
+	)
+	input.FullName, err = s.NameRules.normalize(input.FullName)
+	errs.add("full_name", err)
+	input.Phone = NormalizePhone(input.Phone)
+	errs.add("phone", validatePhone(input.Phone))
+	errs.add("password", validatePassword(input.Password))
+	if len(errs) > 0 {
+		return "", errs
+	}
+
+	taken, err := s.phoneTaken(ctx, input.Phone)
+	if nil != err {
+		return "", err
+	}
+	if taken {
+		errs.add("phone", validationError{code: generated.ErrorCodePhoneTaken})
+		return "", errs
+	}
+
+	publicId, err := NewPublicId(time.Now())
+	if nil != err {
+		return "", err
+	}
+	p, _ := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if _, err = s.Repository.Store(ctx, repository.RegistrationInput{
+		PublicId: publicId,
+		FullName: input.FullName,
+		Phone:    input.Phone,
+		Password: string(p),
+		Admin:    input.Admin,
+	}); nil != err {
+		return "", err
+	}
+
+	return publicId, nil
+}
+
+// SetUserPassword replaces the password of the user, found by phone number or
+// public id, and revokes every token issued so far. A pending forced reset is
+// settled by it. It is recorded as a password reset on behalf of the service.
+func (s *Server) SetUserPassword(ctx context.Context, user, password, reason string) error {
+	var errs fieldErrors
+	errs.add("password", validatePassword(password))
+	reason = strings.TrimSpace(reason)
+	errs.add("reason", validateReason(reason))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	found, err := s.findUser(ctx, user)
+	if nil != err {
+		return err
+	}
+	p, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	err = s.Repository.SetPassword(ctx, repository.SetPasswordInput{
+		AdminActionInput: repository.AdminActionInput{
+			PublicId: found.PublicId,
+			Admin:    repository.SystemActor,
+			Reason:   reason,
+		},
+		Password: string(p),
+	})
+	if err == sql.ErrNoRows {
+		return validationError{code: generated.ErrorCodeUserNotFound}
+	}
+
+	return err
+}
+
+// SuspendAccount suspends the user, found by phone number or public id, on
+// behalf of the service.
+func (s *Server) SuspendAccount(ctx context.Context, user, reason string) error {
+	var errs fieldErrors
+	reason = strings.TrimSpace(reason)
+	errs.add("reason", validateReason(reason))
+	if len(errs) > 0 {
+		return errs
+	}
+
+	found, err := s.findUser(ctx, user)
+	if nil != err {
+		return err
+	}
+	err = s.Repository.SetStatus(ctx, repository.SetStatusInput{
+		AdminActionInput: repository.AdminActionInput{
+			PublicId: found.PublicId,
+			Admin:    repository.SystemActor,
+			Reason:   reason,
+		},
+		Status: repository.StatusSuspended,
+	})
+	switch err {
+	case sql.ErrNoRows:
+		return validationError{code: generated.ErrorCodeUserNotFound}
+	case repository.ErrStatusTransition:
+		return validationError{code: generated.ErrorCodeStatusTransition}
+	}
+
+	return err
+}
+
+// findUser finds the user by phone number, or by public id when user is none.
+func (s *Server) findUser(ctx context.Context, user string) (repository.FindByPhoneOutput, error) {
+	phone := NormalizePhone(user)
+	if nil != validatePhone(phone) {
+		profile, err := s.Repository.FindByPublicId(ctx, repository.FindByPublicIdInput{PublicId: user})
+		if nil != err {
+			if err == sql.ErrNoRows {
+				return repository.FindByPhoneOutput{}, validationError{code: generated.ErrorCodeUserNotFound}
+			}
+
+			return repository.FindByPhoneOutput{}, err
+		}
+		phone = profile.Phone
+	}
+
+	found, err := s.Repository.FindByPhone(ctx, repository.FindByPhoneInput{Phone: phone})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return repository.FindByPhoneOutput{}, validationError{code: generated.ErrorCodeUserNotFound}
+		}
+
+		return repository.FindByPhoneOutput{}, err
+	}
+
+	return found, nil
+}
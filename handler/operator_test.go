@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestServer_CreateUser(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo, NameRules: DefaultNameRules})
+
+	repo.EXPECT().
+		FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6281234567890"}).
+		Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+	repo.EXPECT().
+		Store(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.RegistrationInput) (repository.RegistrationOutput, error) {
+			assert.Equal(t, "Budi Santoso", input.FullName)
+			assert.True(t, input.Admin)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(input.Password), []byte("T3stv@lid")))
+			return repository.RegistrationOutput{Id: 1}, nil
+		})
+
+	publicId, err := s.CreateUser(context.Background(), CreateUserInput{
+		FullName: " Budi  Santoso",
+		Phone:    "081234567890",
+		Password: "T3stv@lid",
+		Admin:    true,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, publicId, 26)
+
+	_, err = s.CreateUser(context.Background(), CreateUserInput{FullName: "Budi", Phone: "0812", Password: "weak"})
+	assert.IsType(t, fieldErrors{}, err)
+	assert.Len(t, err.(fieldErrors), 2)
+}
+
+func TestServer_SuspendAccount(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+
+	repo.EXPECT().
+		FindByPublicId(gomock.Any(), repository.FindByPublicIdInput{PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3"}).
+		Return(repository.FindByPublicIdOutput{Phone: "+6281234567890"}, nil)
+	repo.EXPECT().
+		FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6281234567890"}).
+		Return(repository.FindByPhoneOutput{Id: 1, PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3"}, nil)
+	repo.EXPECT().
+		SetStatus(gomock.Any(), repository.SetStatusInput{
+			AdminActionInput: repository.AdminActionInput{
+				PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3",
+				Admin:    repository.SystemActor,
+				Reason:   "fraud report",
+			},
+			Status: repository.StatusSuspended,
+		}).
+		Return(repository.ErrStatusTransition)
+
+	err := s.SuspendAccount(context.Background(), "01H2X3Y4Z5A6B7C8D9E0F1G2H3", " fraud report ")
+	assert.Equal(t, validationError{code: generated.ErrorCodeStatusTransition}, err)
+
+	repo.EXPECT().
+		FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6281234567891"}).
+		Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+
+	err = s.SuspendAccount(context.Background(), "081234567891", "fraud report")
+	assert.Equal(t, validationError{code: generated.ErrorCodeUserNotFound}, err)
+}
+
+func TestServer_SetUserPassword(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo})
+
+	repo.EXPECT().
+		FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6281234567890"}).
+		Return(repository.FindByPhoneOutput{Id: 1, PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3"}, nil)
+	repo.EXPECT().
+		SetPassword(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.SetPasswordInput) error {
+			assert.Equal(t, repository.AdminActionInput{
+				PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3",
+				Admin:    repository.SystemActor,
+				Reason:   "locked out",
+			}, input.AdminActionInput)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(input.Password), []byte("T3stv@lid")))
+			return nil
+		})
+
+	err := s.SetUserPassword(context.Background(), "081234567890", "T3stv@lid", " locked out ")
+	assert.NoError(t, err)
+
+	err = s.SetUserPassword(context.Background(), "081234567890", "weak", "")
+	assert.IsType(t, fieldErrors{}, err)
+	assert.Len(t, err.(fieldErrors), 2)
+}
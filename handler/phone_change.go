@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	otpLength = 6
+	// maxOtpAttempts cancels the phone number change after too many wrong
+	// codes, so the OTP can not be brute forced
+	maxOtpAttempts = 5
+)
+
+// requestPhoneChange stores newPhone as pending change of the user, sends the
+// OTP to newPhone and lets oldPhone know how to cancel the change. A change
+// failing to be announced is withdrawn again.
+func (s *Server) requestPhoneChange(ctx echo.Context, publicId, oldPhone, newPhone string) (generated.PhoneChangeResponse, error) {
+	c := ctx.Request().Context()
+
+	otp, err := randomDigits(otpLength)
+	if nil != err {
+		return generated.PhoneChangeResponse{}, err
+	}
+	token, err := randomToken()
+	if nil != err {
+		return generated.PhoneChangeResponse{}, err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(otp), bcrypt.DefaultCost)
+	if nil != err {
+		return generated.PhoneChangeResponse{}, err
+	}
+
+	expiresAt := time.Now().Add(s.PhoneChangeTTL).UTC().Truncate(time.Second)
+	if err = s.Repository.StorePhoneChange(c, repository.StorePhoneChangeInput{
+		PublicId:    publicId,
+		Phone:       newPhone,
+		Otp:         string(hashed),
+		CancelToken: hashToken(token),
+		ExpiresAt:   expiresAt,
+	}); nil != err {
+		return generated.PhoneChangeResponse{}, err
+	}
+
+	if err = s.SMSSender.Send(c, newPhone, fmt.Sprintf(
+		"Your verification code is %s, valid until %s.",
+		otp,
+		expiresAt.Format(time.RFC3339),
+	)); nil != err {
+		s.withdrawPhoneChange(ctx, publicId)
+		return generated.PhoneChangeResponse{}, err
+	}
+	if err = s.SMSSender.Send(c, oldPhone, fmt.Sprintf(
+		"Your phone number is being changed to %s. If this was not you, cancel it with token %s before %s, or revert it within %s of the confirmation.",
+		newPhone,
+		token,
+		expiresAt.Format(time.RFC3339),
+		s.PhoneChangeCancel,
+	)); nil != err {
+		s.withdrawPhoneChange(ctx, publicId)
+		return generated.PhoneChangeResponse{}, err
+	}
+
+	return generated.PhoneChangeResponse{
+		Phone:     newPhone,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// withdrawPhoneChange cancels the pending change of a request which failed
+// after storing it, so the OTP already sent can not confirm it.
+func (s *Server) withdrawPhoneChange(ctx echo.Context, publicId string) {
+	c := ctx.Request().Context()
+
+	change, err := s.Repository.FindPendingPhoneChange(c, repository.FindPendingPhoneChangeInput{PublicId: publicId})
+	if nil == err {
+		err = s.Repository.CancelPhoneChange(c, repository.PhoneChangeInput{Id: change.Id, Actor: requestActor(ctx, publicId)})
+	}
+	if nil != err && err != sql.ErrNoRows {
+		ctx.Logger().Errorf("withdrawing the phone number change of %s: %s", publicId, err)
+	}
+}
+
+func (s *Server) ConfirmPhoneChange(ctx echo.Context) error {
+	var (
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		request  generated.ConfirmPhoneChangeRequest
+		c        = ctx.Request().Context()
+	)
+	if err := ctx.Bind(&request); nil != err {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	var errs fieldErrors
+	if request.Code == "" {
+		errs.add("code", errRequired)
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	change, err := s.Repository.FindPendingPhoneChange(c, repository.FindPendingPhoneChangeInput{PublicId: publicId})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodePhoneChangeNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(change.Otp), []byte(request.Code)); nil != err {
+		if change.Attempts+1 >= maxOtpAttempts {
+			err = s.Repository.CancelPhoneChange(c, repository.PhoneChangeInput{Id: change.Id, Actor: requestActor(ctx, publicId)})
+		} else {
+			err = s.Repository.PutPhoneChangeAttempt(c, repository.PhoneChangeInput{Id: change.Id})
+		}
+		if nil != err {
+			return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+		}
+
+		errs.add("code", validationError{code: generated.ErrorCodeOtpInvalid})
+		return errs.write(ctx)
+	}
+
+	// the number may have been registered by someone else after the change
+	// was requested
+	taken, err := s.phoneTaken(c, change.Phone)
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	if taken {
+		return errorResponse(ctx, http.StatusConflict, generated.ErrorCodePhoneTaken)
+	}
+
+	if err = s.Repository.ConfirmPhoneChange(c, repository.PhoneChangeInput{
+		Id:          change.Id,
+		Actor:       requestActor(ctx, publicId),
+		CancelUntil: time.Now().Add(s.PhoneChangeCancel).UTC().Truncate(time.Second),
+	}); nil != err {
+		switch err {
+		case sql.ErrNoRows:
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodePhoneChangeNotFound)
+		// registered between the check above and the update
+		case repository.ErrPhoneTaken:
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodePhoneTaken)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+// CancelPhoneChange cancels the change with the token sent to the old number.
+// A change confirmed in the meantime, e.g. through a hijacked session, is
+// reverted within PhoneChangeCancel of the confirmation and every token issued
+// so far is revoked.
+func (s *Server) CancelPhoneChange(ctx echo.Context) error {
+	var (
+		request generated.CancelPhoneChangeRequest
+		c       = ctx.Request().Context()
+	)
+	if err := ctx.Bind(&request); nil != err {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	var errs fieldErrors
+	if request.Token == "" {
+		errs.add("token", errRequired)
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	change, err := s.Repository.FindPhoneChangeByCancelToken(c, repository.FindPhoneChangeByCancelTokenInput{
+		CancelToken: hashToken(request.Token),
+	})
+	if nil != err {
+		if err == sql.ErrNoRows {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodePhoneChangeNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	// the holder of the old number acts for the owner of the account
+	if err = s.Repository.CancelPhoneChange(c, repository.PhoneChangeInput{
+		Id:    change.Id,
+		Actor: requestActor(ctx, change.PublicId),
+	}); nil != err {
+		if err == repository.ErrPhoneTaken {
+			return errorResponse(ctx, http.StatusConflict, generated.ErrorCodePhoneTaken)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	if change.Confirmed {
+		s.sessions.forget(change.PublicId)
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+// randomDigits returns n cryptographically random decimal digits.
+func randomDigits(n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if nil != err {
+			return "", err
+		}
+		b[i] = byte('0' + d.Int64())
+	}
+
+	return string(b), nil
+}
+
+// randomToken returns 128 bits of cryptographic randomness hex encoded.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); nil != err {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken is how secret tokens are persisted, so a leaked table can not be
+// used to act on behalf of the users.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/notification"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// smsRecorder keeps every sent message per phone number.
+type smsRecorder struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+func (r *smsRecorder) Send(_ context.Context, phone string, message string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.messages == nil {
+		r.messages = make(map[string][]string)
+	}
+	r.messages[phone] = append(r.messages[phone], message)
+
+	return nil
+}
+
+// authActor is the actor recorded for requests of newAuthContext, httptest
+// requests come from 192.0.2.1.
+var authActor = repository.Actor{PublicId: "slug", IP: "192.0.2.1"}
+
+func newAuthContext(e *echo.Echo, method string, body any, publicId string) (echo.Context, *httptest.ResponseRecorder) {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, "/", bytes.NewReader(b))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.Set("user", map[string]any{"sub": publicId})
+
+	return ctx, rec
+}
+
+func TestServer_UpdateProfilePhoneChange(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	sms := &smsRecorder{}
+	s := NewServer(NewServerOptions{Repository: repo, SMSSender: sms})
+
+	repo.EXPECT().
+		FindByPublicId(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).
+		Return(repository.FindByPublicIdOutput{PublicId: "slug", FullName: "old name", Phone: "+6281234567890"}, nil)
+	repo.EXPECT().
+		FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: "+6289876543210"}).
+		Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+	repo.EXPECT().
+		Put(gomock.Any(), repository.UpdateUserInput{PublicId: "slug", FullName: "new name", Phone: "+6281234567890", Actor: authActor}).
+		Return(repository.UpdateUserOutput{Version: 4}, nil)
+	repo.EXPECT().
+		StorePhoneChange(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input repository.StorePhoneChangeInput) error {
+			assert.Equal(t, "slug", input.PublicId)
+			assert.Equal(t, "+6289876543210", input.Phone)
+			assert.Len(t, input.CancelToken, 64)
+			return nil
+		})
+
+	ctx, rec := newAuthContext(e, http.MethodPut, generated.UpdateRequest{FullName: "new name", Phone: "089876543210"}, "slug")
+	assert.NoError(t, s.UpdateProfile(ctx))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	var response generated.PhoneChangeResponse
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "+6289876543210", response.Phone)
+
+	assert.Len(t, sms.messages["+6289876543210"], 1)
+	assert.Contains(t, sms.messages["+6281234567890"][0], "cancel it with token")
+}
+
+// failingSMS fails every message, like a gateway which is down.
+type failingSMS struct{}
+
+func (failingSMS) Send(context.Context, string, string) error {
+	return errors.New("gateway unavailable")
+}
+
+func TestServer_UpdateProfilePhoneChangeFailure(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		sms      notification.SMSSender
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name: "text message failing before the profile is written",
+			sms:  failingSMS{},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), repository.FindPendingPhoneChangeInput{PublicId: "slug"}).
+					Return(repository.FindPendingPhoneChangeOutput{Id: 7}, nil)
+				repo.EXPECT().CancelPhoneChange(gomock.Any(), repository.PhoneChangeInput{Id: 7, Actor: authActor}).Return(nil)
+			},
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name: "profile changed in the meantime",
+			sms:  &smsRecorder{},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().Put(gomock.Any(), gomock.Any()).Return(repository.UpdateUserOutput{}, repository.ErrVersionConflict)
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), repository.FindPendingPhoneChangeInput{PublicId: "slug"}).
+					Return(repository.FindPendingPhoneChangeOutput{Id: 7}, nil)
+				repo.EXPECT().CancelPhoneChange(gomock.Any(), repository.PhoneChangeInput{Id: 7, Actor: authActor}).Return(nil)
+			},
+			expected: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, SMSSender: cases.sms})
+			repo.EXPECT().
+				FindByPublicId(gomock.Any(), gomock.Any()).
+				Return(repository.FindByPublicIdOutput{PublicId: "slug", FullName: "old name", Phone: "+6281234567890"}, nil)
+			repo.EXPECT().
+				FindByPhone(gomock.Any(), gomock.Any()).
+				Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+			repo.EXPECT().StorePhoneChange(gomock.Any(), gomock.Any()).Return(nil)
+			cases.mock(repo)
+
+			// the pending change is withdrawn, the OTP sent can not confirm it
+			ctx, rec := newAuthContext(e, http.MethodPut, generated.UpdateRequest{FullName: "new name", Phone: "089876543210"}, "slug")
+			assert.NoError(t, s.UpdateProfile(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+		})
+	}
+}
+
+func TestServer_UpdateProfileSamePhone(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo, SMSSender: &smsRecorder{}})
+
+	repo.EXPECT().
+		FindByPublicId(gomock.Any(), gomock.Any()).
+		Return(repository.FindByPublicIdOutput{PublicId: "slug", FullName: "old name", Phone: "+6281234567890"}, nil)
+	repo.EXPECT().
+		Put(gomock.Any(), repository.UpdateUserInput{PublicId: "slug", FullName: "new name", Phone: "+6281234567890", Actor: authActor}).
+		Return(repository.UpdateUserOutput{Version: 4}, nil)
+
+	ctx, rec := newAuthContext(e, http.MethodPut, generated.UpdateRequest{FullName: "new name", Phone: "+6281234567890"}, "slug")
+	assert.NoError(t, s.UpdateProfile(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestServer_ConfirmPhoneChange(t *testing.T) {
+	t.Parallel()
+
+	otp, _ := bcrypt.GenerateFromPassword([]byte("123456"), bcrypt.MinCost)
+	pending := repository.FindPendingPhoneChangeOutput{Id: 7, UserId: 1, Phone: "+6289876543210", Otp: string(otp)}
+
+	type Case struct {
+		name     string
+		code     string
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+	}
+	var testCases = []Case{
+		{
+			name: "request with valid code",
+			code: "123456",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), repository.FindPendingPhoneChangeInput{PublicId: "slug"}).Return(pending, nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), repository.FindByPhoneInput{Phone: pending.Phone}).Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+				repo.EXPECT().
+					ConfirmPhoneChange(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, input repository.PhoneChangeInput) error {
+						assert.Equal(t, 7, input.Id)
+						assert.Equal(t, authActor, input.Actor)
+						assert.WithinDuration(t, time.Now().Add(24*time.Hour), input.CancelUntil, time.Minute, "counted from the confirmation")
+						return nil
+					})
+			},
+			expected: http.StatusOK,
+		},
+		{
+			name: "request with invalid code",
+			code: "654321",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), gomock.Any()).Return(pending, nil)
+				repo.EXPECT().PutPhoneChangeAttempt(gomock.Any(), repository.PhoneChangeInput{Id: 7}).Return(nil)
+			},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name: "request with invalid code on the last attempt",
+			code: "654321",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				last := pending
+				last.Attempts = maxOtpAttempts - 1
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), gomock.Any()).Return(last, nil)
+				repo.EXPECT().CancelPhoneChange(gomock.Any(), repository.PhoneChangeInput{Id: 7, Actor: authActor}).Return(nil)
+			},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name: "request with phone number taken in the meantime",
+			code: "123456",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), gomock.Any()).Return(pending, nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), gomock.Any()).Return(repository.FindByPhoneOutput{Id: 2, Phone: pending.Phone}, nil)
+			},
+			expected: http.StatusConflict,
+		},
+		{
+			name: "request with phone number taken while confirming",
+			code: "123456",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), gomock.Any()).Return(pending, nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), gomock.Any()).Return(repository.FindByPhoneOutput{}, sql.ErrNoRows)
+				repo.EXPECT().ConfirmPhoneChange(gomock.Any(), gomock.Any()).Return(repository.ErrPhoneTaken)
+			},
+			expected: http.StatusConflict,
+		},
+		{
+			name: "request without pending change",
+			code: "123456",
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().FindPendingPhoneChange(gomock.Any(), gomock.Any()).Return(repository.FindPendingPhoneChangeOutput{}, sql.ErrNoRows)
+			},
+			expected: http.StatusNotFound,
+		},
+		{
+			name:     "request without code",
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusBadRequest,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, SMSSender: &smsRecorder{}})
+			cases.mock(repo)
+
+			ctx, rec := newAuthContext(e, http.MethodPost, generated.ConfirmPhoneChangeRequest{Code: cases.code}, "slug")
+			assert.NoError(t, s.ConfirmPhoneChange(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+		})
+	}
+}
+
+func TestServer_CancelPhoneChange(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		change   repository.FindPendingPhoneChangeOutput
+		findErr  error
+		err      error
+		expected int
+	}
+	var testCases = []Case{
+		{name: "cancel pending change", change: repository.FindPendingPhoneChangeOutput{Id: 7, PublicId: "01H"}, expected: http.StatusOK},
+		{name: "revert confirmed change", change: repository.FindPendingPhoneChangeOutput{Id: 7, PublicId: "01H", Confirmed: true}, expected: http.StatusOK},
+		{
+			name:     "revert to a number registered since",
+			change:   repository.FindPendingPhoneChangeOutput{Id: 7, PublicId: "01H", Confirmed: true},
+			err:      repository.ErrPhoneTaken,
+			expected: http.StatusConflict,
+		},
+		{name: "cancel expired or unknown change", findErr: sql.ErrNoRows, expected: http.StatusNotFound},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo, SMSSender: &smsRecorder{}})
+			s.sessions.put("01H", cachedSession{expiresAt: time.Now().Add(time.Minute)})
+
+			repo.EXPECT().
+				FindPhoneChangeByCancelToken(gomock.Any(), repository.FindPhoneChangeByCancelTokenInput{CancelToken: hashToken("token")}).
+				Return(cases.change, cases.findErr)
+			if nil == cases.findErr {
+				repo.EXPECT().
+					CancelPhoneChange(gomock.Any(), repository.PhoneChangeInput{Id: 7, Actor: repository.Actor{PublicId: "01H", IP: "192.0.2.1"}}).
+					Return(cases.err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"token":"token"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			assert.NoError(t, s.CancelPhoneChange(e.NewContext(req, rec)))
+			assert.Equal(t, cases.expected, rec.Code)
+			_, cached := s.sessions.get("01H")
+			assert.Equal(t, !cases.change.Confirmed || nil != cases.err, cached, "the revoked session is looked up again")
+		})
+	}
+}
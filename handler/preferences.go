@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// preferenceDefaults apply to every preference the user did not set, keep
+// them in sync with the defaults documented in api.yml. Every call returns
+// new pointers, decoding into them leaves the defaults alone.
+func preferenceDefaults() generated.Preferences {
+	var (
+		language  = generated.PreferencesLanguage(defaultLanguage)
+		units     = generated.Metric
+		security  = true
+		product   = true
+		marketing = false
+	)
+
+	return generated.Preferences{
+		Language:       &language,
+		Units:          &units,
+		NotifySecurity: &security,
+		NotifyProduct:  &product,
+		MarketingOptIn: &marketing,
+	}
+}
+
+func (s *Server) Preferences(ctx echo.Context) error {
+	publicId := ctx.Get("user").(map[string]any)["sub"].(string)
+
+	stored, err := s.Repository.FindPreferences(ctx.Request().Context(), repository.FindByPublicIdInput{PublicId: publicId})
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	preferences, err := withPreferenceDefaults(stored)
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	return ctx.JSON(http.StatusOK, preferences)
+}
+
+func (s *Server) UpdatePreferences(ctx echo.Context) error {
+	var (
+		publicId = ctx.Get("user").(map[string]any)["sub"].(string)
+		request  generated.Preferences
+	)
+	if err := ctx.Bind(&request); nil != err {
+		return errorResponse(ctx, http.StatusBadRequest, generated.ErrorCodeInvalidRequest)
+	}
+
+	var errs fieldErrors
+	if nil != request.Language {
+		errs.add("language", validateLanguage(*request.Language))
+	}
+	if nil != request.Units {
+		errs.add("units", validateUnits(*request.Units))
+	}
+	if len(errs) > 0 {
+		return errs.write(ctx)
+	}
+
+	// only the preferences in the request are stored, the others keep
+	// following the server default even when it changes
+	values, err := preferenceValues(request)
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+	if err = s.Repository.PutPreferences(ctx.Request().Context(), repository.PutPreferencesInput{
+		PublicId: publicId,
+		Values:   values,
+	}); nil != err {
+		if err == sql.ErrNoRows {
+			return errorResponse(ctx, http.StatusNotFound, generated.ErrorCodeUserNotFound)
+		}
+
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	preferences, err := withPreferenceDefaults(values)
+	if nil != err {
+		return errorResponse(ctx, http.StatusInternalServerError, generated.ErrorCodeInternalError)
+	}
+
+	return ctx.JSON(http.StatusOK, preferences)
+}
+
+func validateLanguage(language generated.PreferencesLanguage) error {
+	switch language {
+	case generated.En, generated.Id:
+		return nil
+	default:
+		return validationError{code: generated.ErrorCodeInvalid}
+	}
+}
+
+func validateUnits(units generated.PreferencesUnits) error {
+	switch units {
+	case generated.Metric, generated.Imperial:
+		return nil
+	default:
+		return validationError{code: generated.ErrorCodeInvalid}
+	}
+}
+
+// preferenceValues splits the preferences set in p into JSON documents by
+// key, the form they are stored in.
+func preferenceValues(p generated.Preferences) (map[string]string, error) {
+	b, err := json.Marshal(p)
+	if nil != err {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err = json.Unmarshal(b, &raw); nil != err {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		values[key] = string(value)
+	}
+
+	return values, nil
+}
+
+// withPreferenceDefaults lays the stored preferences over the defaults.
+// Stored keys no longer known are dropped, a stored value of the wrong type
+// fails.
+func withPreferenceDefaults(stored map[string]string) (generated.Preferences, error) {
+	preferences := preferenceDefaults()
+	for key, value := range stored {
+		b, err := json.Marshal(map[string]json.RawMessage{key: json.RawMessage(value)})
+		if nil != err {
+			return generated.Preferences{}, err
+		}
+		if err = json.Unmarshal(b, &preferences); nil != err {
+			return generated.Preferences{}, err
+		}
+	}
+
+	return preferences, nil
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Preferences(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		stored   map[string]string
+		err      error
+		expected int
+		response string
+	}
+	var testCases = []Case{
+		{
+			name:     "nothing set returns the defaults",
+			stored:   map[string]string{},
+			expected: http.StatusOK,
+			response: `{"language":"en","units":"metric","notify_security":true,"notify_product":true,"marketing_opt_in":false}`,
+		},
+		{
+			name:     "set preferences replace the defaults",
+			stored:   map[string]string{"language": `"id"`, "marketing_opt_in": "true", "removed": "1"},
+			expected: http.StatusOK,
+			response: `{"language":"id","units":"metric","notify_security":true,"notify_product":true,"marketing_opt_in":true}`,
+		},
+		{
+			name:     "stored value of the wrong type",
+			stored:   map[string]string{"units": "1"},
+			expected: http.StatusInternalServerError,
+		},
+		{
+			name:     "database failure",
+			err:      sql.ErrConnDone,
+			expected: http.StatusInternalServerError,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().
+				FindPreferences(gomock.Any(), repository.FindByPublicIdInput{PublicId: "slug"}).
+				Return(cases.stored, cases.err)
+
+			ctx, rec := newAuthContext(e, http.MethodGet, nil, "slug")
+			assert.NoError(t, s.Preferences(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.expected == http.StatusOK {
+				assert.JSONEq(t, cases.response, rec.Body.String())
+			}
+		})
+	}
+
+	// the defaults are not changed by decoding stored values into them
+	_, err := withPreferenceDefaults(map[string]string{"units": `"imperial"`})
+	assert.NoError(t, err)
+	assert.Equal(t, "metric", string(*preferenceDefaults().Units))
+}
+
+func TestServer_UpdatePreferences(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		name     string
+		body     map[string]any
+		mock     func(repo *repository.MockRepositoryInterface)
+		expected int
+		response string
+	}
+	var testCases = []Case{
+		{
+			name: "only the sent preferences are stored",
+			body: map[string]any{"units": "imperial", "notify_product": false},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().PutPreferences(gomock.Any(), repository.PutPreferencesInput{
+					PublicId: "slug",
+					Values:   map[string]string{"units": `"imperial"`, "notify_product": "false"},
+				}).Return(nil)
+			},
+			expected: http.StatusOK,
+			response: `{"language":"en","units":"imperial","notify_security":true,"notify_product":false,"marketing_opt_in":false}`,
+		},
+		{
+			name: "empty body resets every preference",
+			body: map[string]any{},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().PutPreferences(gomock.Any(), repository.PutPreferencesInput{
+					PublicId: "slug",
+					Values:   map[string]string{},
+				}).Return(nil)
+			},
+			expected: http.StatusOK,
+			response: `{"language":"en","units":"metric","notify_security":true,"notify_product":true,"marketing_opt_in":false}`,
+		},
+		{
+			name:     "unknown values",
+			body:     map[string]any{"language": "fr", "units": "furlong"},
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusBadRequest,
+			response: `{"code":"invalid","message":"value is invalid","errors":[
+				{"field":"language","code":"invalid","message":"value is invalid"},
+				{"field":"units","code":"invalid","message":"value is invalid"}
+			]}`,
+		},
+		{
+			name:     "value of the wrong type",
+			body:     map[string]any{"marketing_opt_in": "yes"},
+			mock:     func(repo *repository.MockRepositoryInterface) {},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name: "purged user",
+			body: map[string]any{},
+			mock: func(repo *repository.MockRepositoryInterface) {
+				repo.EXPECT().PutPreferences(gomock.Any(), gomock.Any()).Return(sql.ErrNoRows)
+			},
+			expected: http.StatusNotFound,
+		},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			cases.mock(repo)
+
+			ctx, rec := newAuthContext(e, http.MethodPut, cases.body, "slug")
+			assert.NoError(t, s.UpdatePreferences(ctx))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.response != "" {
+				assert.JSONEq(t, cases.response, rec.Body.String())
+			}
+		})
+	}
+}
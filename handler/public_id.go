@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// crockford is the base32 alphabet of ULIDs, without I, L, O and U.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewPublicId returns a ULID, 48 bits of milliseconds since the epoch followed
+// by 80 random bits. Unlike the legacy slug it reveals nothing about the user
+// and never changes, ids of the same millisecond are not ordered.
+func NewPublicId(now time.Time) (string, error) {
+	var b [16]byte
+	ms := uint64(now.UnixMilli())
+	b[0], b[1], b[2], b[3], b[4], b[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	if _, err := rand.Read(b[6:]); nil != err {
+		return "", err
+	}
+
+	// 26 characters of 5 bits hold the 128 bits, the first one only 3
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+	var id [26]byte
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = crockford[lo&0x1F]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(id[:]), nil
+}
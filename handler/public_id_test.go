@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPublicId(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	id, err := NewPublicId(now)
+	assert.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), id)
+	// the first 10 characters encode the milliseconds
+	assert.Equal(t, "01H1T42300", id[:10])
+
+	other, err := NewPublicId(now)
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, other)
+
+	later, err := NewPublicId(now.Add(time.Millisecond))
+	assert.NoError(t, err)
+	assert.Less(t, id[:10], later[:10])
+}
+
+// legacySubjects resolves legacy slugs into public ids, every session is
+// valid.
+type legacySubjects map[string]string
+
+func (l legacySubjects) ValidSession(_ context.Context, sub string, _ int, _ time.Time) (string, bool, error) {
+	if id, ok := l[sub]; ok {
+		return id, true, nil
+	}
+
+	return sub, true, nil
+}
+
+func TestMiddlewareLegacySlug(t *testing.T) {
+	t.Parallel()
+
+	publicId := "01H2X3Y4Z5A6B7C8D9E0F1G2H3"
+	sessions := legacySubjects{"KzYyODEyMzQ1Njc4OTA": publicId}
+
+	for _, sub := range []string{"KzYyODEyMzQ1Njc4OTA", publicId} {
+		token, err := Create(map[string]any{"sub": sub})
+		assert.NoError(t, err)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+
+		var got any
+		handler := Middleware(MiddlewareOptions{Sessions: sessions})(func(c echo.Context) error {
+			got = c.Get("user").(map[string]any)["sub"]
+			return c.NoContent(http.StatusOK)
+		})
+		assert.NoError(t, handler(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, publicId, got, sub)
+	}
+}
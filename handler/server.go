@@ -1,19 +1,113 @@
 package handler
 
 import (
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/notification"
 	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/SawitProRecruitment/UserService/storage"
 )
 
 type Server struct {
-	Repository repository.RepositoryInterface
+	Repository           repository.RepositoryInterface
+	SMSSender            notification.SMSSender
+	BlobStore            storage.BlobStore
+	NameRules            NameRules
+	PhoneChangeTTL       time.Duration
+	PhoneChangeCancel    time.Duration
+	RequireIfMatch       bool
+	AvatarMaxBytes       int64
+	DeletionGrace        time.Duration
+	Exports              storage.BlobStore
+	ExportTTL            time.Duration
+	Mailer               notification.Mailer
+	PublicURL            string
+	EmailVerificationTTL time.Duration
+
+	sessions *sessionCache
 }
 
 type NewServerOptions struct {
 	Repository repository.RepositoryInterface
+	SMSSender  notification.SMSSender
+	// BlobStore keeps the avatar thumbnails
+	BlobStore storage.BlobStore
+	// NameRules defaults into DefaultNameRules when left empty
+	NameRules NameRules
+	// PhoneChangeTTL is how long a phone number change waits for the OTP,
+	// defaults into ten minutes
+	PhoneChangeTTL time.Duration
+	// PhoneChangeCancel is how long the old number can revert a confirmed
+	// change, counted from the confirmation, defaults into 24 hours
+	PhoneChangeCancel time.Duration
+	// RequireIfMatch rejects profile writes without If-Match header with 428
+	RequireIfMatch bool
+	// AvatarMaxBytes limits the uploaded avatar file, defaults into 5 MiB
+	AvatarMaxBytes int64
+	// DeletionGrace is how long a deleted account can be restored by logging
+	// in before it is purged, defaults into 30 days
+	DeletionGrace time.Duration
+	// Exports keeps the personal data archives, it must not be served
+	// publicly
+	Exports storage.BlobStore
+	// ExportTTL is how long an export can be downloaded, defaults into 24
+	// hours
+	ExportTTL time.Duration
+	// Mailer delivers the email verification links
+	Mailer notification.Mailer
+	// PublicURL is where clients reach the API, links sent to users start
+	// with it
+	PublicURL string
+	// EmailVerificationTTL is how long a verification link works, defaults
+	// into 24 hours
+	EmailVerificationTTL time.Duration
+	// SessionCacheTTL is how long ValidSession trusts a looked up session,
+	// which bounds how late other instances see a suspension. Defaults into
+	// 30 seconds.
+	SessionCacheTTL time.Duration
 }
 
 func NewServer(opts NewServerOptions) *Server {
+	if opts.NameRules.MaxLength == 0 {
+		opts.NameRules = DefaultNameRules
+	}
+	if opts.PhoneChangeTTL == 0 {
+		opts.PhoneChangeTTL = 10 * time.Minute
+	}
+	if opts.PhoneChangeCancel == 0 {
+		opts.PhoneChangeCancel = 24 * time.Hour
+	}
+	if opts.AvatarMaxBytes == 0 {
+		opts.AvatarMaxBytes = 5 << 20
+	}
+	if opts.DeletionGrace == 0 {
+		opts.DeletionGrace = 30 * 24 * time.Hour
+	}
+	if opts.ExportTTL == 0 {
+		opts.ExportTTL = 24 * time.Hour
+	}
+	if opts.EmailVerificationTTL == 0 {
+		opts.EmailVerificationTTL = 24 * time.Hour
+	}
+	if opts.SessionCacheTTL == 0 {
+		opts.SessionCacheTTL = 30 * time.Second
+	}
+
 	return &Server{
-		Repository: opts.Repository,
+		Repository:           opts.Repository,
+		SMSSender:            opts.SMSSender,
+		BlobStore:            opts.BlobStore,
+		NameRules:            opts.NameRules,
+		PhoneChangeTTL:       opts.PhoneChangeTTL,
+		PhoneChangeCancel:    opts.PhoneChangeCancel,
+		RequireIfMatch:       opts.RequireIfMatch,
+		AvatarMaxBytes:       opts.AvatarMaxBytes,
+		DeletionGrace:        opts.DeletionGrace,
+		Exports:              opts.Exports,
+		ExportTTL:            opts.ExportTTL,
+		Mailer:               opts.Mailer,
+		PublicURL:            opts.PublicURL,
+		EmailVerificationTTL: opts.EmailVerificationTTL,
+		sessions:             newSessionCache(opts.SessionCacheTTL),
 	}
 }
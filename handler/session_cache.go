@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/repository"
+)
+
+// maxCachedSessions bounds the memory of the cache, it starts over when full
+// of sessions which did not expire yet.
+const maxCachedSessions = 100000
+
+// sessionCache keeps the sessions looked up by ValidSession for a short
+// while, so Middleware does not query the database on every request. Changes
+// made through this instance are seen at once, changes made through another
+// instance within the TTL.
+type sessionCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cachedSession
+}
+
+type cachedSession struct {
+	session repository.FindSessionOutput
+	// missing users are cached as well, tokens of purged users keep coming
+	missing   bool
+	expiresAt time.Time
+}
+
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{ttl: ttl, entries: map[string]cachedSession{}}
+}
+
+// get returns the cached session of the token subject, ok is false when it
+// has to be looked up.
+func (c *sessionCache) get(sub string) (entry cachedSession, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok = c.entries[sub]
+	if ok && time.Now().After(entry.expiresAt) {
+		delete(c.entries, sub)
+		return cachedSession{}, false
+	}
+
+	return entry, ok
+}
+
+func (c *sessionCache) put(sub string, entry cachedSession) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxCachedSessions {
+		for key, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCachedSessions {
+			c.entries = map[string]cachedSession{}
+		}
+	}
+	entry.expiresAt = now.Add(c.ttl)
+	c.entries[sub] = entry
+}
+
+// forget drops the session of the user, whichever subject it was cached by,
+// after its status or session version changed.
+func (c *sessionCache) forget(publicId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, e := range c.entries {
+		if key == publicId || e.session.PublicId == publicId {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/generated"
+	"github.com/SawitProRecruitment/UserService/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestServer_LoginStatus(t *testing.T) {
+	t.Parallel()
+
+	password, _ := bcrypt.GenerateFromPassword([]byte("T3stv@lid"), bcrypt.MinCost)
+
+	type Case struct {
+		name     string
+		status   string
+		password string
+		expected int
+		code     generated.ErrorCode
+	}
+	var testCases = []Case{
+		{name: "active account", status: repository.StatusActive, password: "T3stv@lid", expected: http.StatusOK},
+		{name: "suspended account", status: repository.StatusSuspended, password: "T3stv@lid", expected: http.StatusForbidden, code: generated.ErrorCodeAccountSuspended},
+		{name: "suspended account with a wrong password", status: repository.StatusSuspended, password: "wrong", expected: http.StatusForbidden, code: generated.ErrorCodeInvalidCredentials},
+	}
+
+	for _, cases := range testCases {
+		t.Run(cases.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			e := echo.New()
+
+			repo := repository.NewMockRepositoryInterface(ctrl)
+			s := NewServer(NewServerOptions{Repository: repo})
+			repo.EXPECT().FindByPhone(gomock.Any(), gomock.Any()).Return(repository.FindByPhoneOutput{
+				Id:       1,
+				PublicId: "slug",
+				Phone:    "+6281234567890",
+				Password: string(password),
+				Status:   cases.status,
+			}, nil)
+
+			b, _ := json.Marshal(generated.LoginRequest{Phone: "+6281234567890", Password: cases.password})
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(b))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			assert.NoError(t, s.Login(e.NewContext(req, rec)))
+			assert.Equal(t, cases.expected, rec.Code)
+			if cases.code != "" {
+				var response generated.ErrorResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+				assert.Equal(t, cases.code, response.Code)
+			}
+		})
+	}
+}
+
+func TestServer_ValidSessionCache(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	e := echo.New()
+
+	repo := repository.NewMockRepositoryInterface(ctrl)
+	s := NewServer(NewServerOptions{Repository: repo, SessionCacheTTL: time.Hour})
+	issuedAt := time.Now().Add(-time.Minute)
+
+	// looked up once while it is cached, again after the account was deleted
+	// through this instance
+	repo.EXPECT().
+		FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).
+		Return(repository.FindSessionOutput{PublicId: "slug", Status: repository.StatusActive}, nil)
+	for i := 0; i < 3; i++ {
+		_, valid, err := s.ValidSession(context.Background(), "slug", 0, issuedAt)
+		assert.NoError(t, err)
+		assert.True(t, valid)
+	}
+
+	repo.EXPECT().SoftDelete(gomock.Any(), gomock.Any()).Return(repository.DeleteUserOutput{DeletedAt: time.Now()}, nil)
+	ctx, rec := newAuthContext(e, http.MethodDelete, nil, "slug")
+	assert.NoError(t, s.DeleteProfile(ctx))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	repo.EXPECT().
+		FindSession(gomock.Any(), repository.FindSessionInput{Subject: "slug"}).
+		Return(repository.FindSessionOutput{PublicId: "slug", Status: repository.StatusDeleted, SessionVersion: 1}, nil)
+	_, valid, err := s.ValidSession(context.Background(), "slug", 0, issuedAt)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestSessionCache(t *testing.T) {
+	t.Parallel()
+
+	c := newSessionCache(time.Hour)
+	c.put("legacy-slug", cachedSession{session: repository.FindSessionOutput{PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3"}})
+	c.put("01H2X3Y4Z5A6B7C8D9E0F1G2H3", cachedSession{session: repository.FindSessionOutput{PublicId: "01H2X3Y4Z5A6B7C8D9E0F1G2H3"}})
+	c.put("other", cachedSession{missing: true})
+
+	c.forget("01H2X3Y4Z5A6B7C8D9E0F1G2H3")
+	_, ok := c.get("legacy-slug")
+	assert.False(t, ok)
+	_, ok = c.get("01H2X3Y4Z5A6B7C8D9E0F1G2H3")
+	assert.False(t, ok)
+	entry, ok := c.get("other")
+	assert.True(t, ok)
+	assert.True(t, entry.missing)
+
+	expired := newSessionCache(-time.Second)
+	expired.put("slug", cachedSession{})
+	_, ok = expired.get("slug")
+	assert.False(t, ok)
+}
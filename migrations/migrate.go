@@ -0,0 +1,382 @@
+// Package migrations holds the numbered schema migrations, embedded into the
+// binary, and applies them. Every migration is a pair of files,
+// NNNN_name.up.sql and NNNN_name.down.sql, run in a transaction each, in a
+// directory per driver with the same versions. Applied migrations are
+// recorded in schema_migrations with the checksum of their up file, an
+// applied migration must never be edited, add a new one instead.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var Files embed.FS
+
+// schemaMigrations creates the table recording the applied migrations by
+// driver, the same as repository.DriverPostgres and DriverSQLite.
+var schemaMigrations = map[string]string{
+	"postgres": `CREATE TABLE IF NOT EXISTS schema_migrations
+	(
+		version    integer PRIMARY KEY,
+		name       varchar(100) not null,
+		checksum   char(64)     not null,
+		applied_at timestamptz  not null default now()
+	)`,
+	"sqlite": `CREATE TABLE IF NOT EXISTS schema_migrations
+	(
+		version    integer PRIMARY KEY,
+		name       varchar(100) not null,
+		checksum   char(64)     not null,
+		applied_at timestamp    not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now'))
+	)`,
+}
+
+// lockKey is the advisory lock held while migrating, so replicas starting
+// together apply every migration once.
+const lockKey = 7_265_431_049
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrChecksum is returned when an applied migration differs from its file.
+var ErrChecksum = errors.New("applied migration was changed")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of Up, hex encoded
+	Checksum string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Status is a migration with the time it was applied, nil while pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load reads the migrations in fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if nil != err {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, name := range names {
+		match := fileName.FindStringSubmatch(path.Base(name))
+		if nil == match {
+			return nil, fmt.Errorf("migration %s: name is not NNNN_name.up.sql or NNNN_name.down.sql", name)
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(fsys, name)
+		if nil != err {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d: named both %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s: up and down files are both required", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+type NewMigratorOptions struct {
+	Db *sql.DB
+	// Driver the database was opened with, postgres when empty
+	Driver string
+	// Files holds the migrations of the driver, the embedded ones when nil
+	Files fs.FS
+}
+
+func NewMigrator(opts NewMigratorOptions) (*Migrator, error) {
+	driver := opts.Driver
+	if driver == "" {
+		driver = "postgres"
+	}
+	if _, ok := schemaMigrations[driver]; !ok {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	files := opts.Files
+	if nil == files {
+		var err error
+		if files, err = fs.Sub(Files, driver); nil != err {
+			return nil, err
+		}
+	}
+	migrations, err := Load(files)
+	if nil != err {
+		return nil, err
+	}
+
+	return &Migrator{db: opts.Db, driver: driver, migrations: migrations}, nil
+}
+
+// Up applies the pending migrations in order and returns them. Migrations
+// applied by a newer binary, unknown to this one, are left alone.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int]string) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.run(ctx, conn, migration, migration.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(
+					ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					migration.Version,
+					migration.Name,
+					migration.Checksum,
+				)
+
+				return err
+			}); nil != err {
+				return err
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns
+// them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn, applied map[int]string) error {
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.run(ctx, conn, migration, migration.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version=$1`, migration.Version)
+
+				return err
+			}); nil != err {
+				return err
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Baseline records the migrations up to version as applied without running
+// them, for databases created from the schema file before migrations.
+func (m *Migrator) Baseline(ctx context.Context, version int) error {
+	return m.locked(ctx, func(conn *sql.Conn, applied map[int]string) error {
+		if len(applied) > 0 {
+			return errors.New("migrations were applied already, baseline an unmigrated database only")
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if nil != err {
+			return err
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+				migration.Version,
+				migration.Name,
+				migration.Checksum,
+			); nil != err {
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
+}
+
+// Status lists the known migrations, applied or pending.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn, _ map[int]string) error {
+		rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+		if nil != err {
+			return err
+		}
+		defer func() {
+			_ = rows.Close()
+		}()
+
+		appliedAt := map[int]time.Time{}
+		for rows.Next() {
+			var (
+				version int
+				at      time.Time
+			)
+			if err = rows.Scan(&version, &at); nil != err {
+				return err
+			}
+			appliedAt[version] = at
+		}
+		if err = rows.Err(); nil != err {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if at, ok := appliedAt[migration.Version]; ok {
+				status.AppliedAt = &at
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// locked runs fn holding the migration lock, with the checksums of the
+// applied migrations by version. It fails when one of them was changed.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, applied map[int]string) error) error {
+	conn, err := m.db.Conn(ctx)
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// SQLite databases belong to a single process, there is nobody to race
+	if m.driver == "postgres" {
+		// the lock belongs to the session, it is released on the same
+		// connection
+		if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); nil != err {
+			return err
+		}
+		defer func() {
+			_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		}()
+	}
+
+	if _, err = conn.ExecContext(ctx, schemaMigrations[m.driver]); nil != err {
+		return err
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if nil != err {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]string, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, checksum FROM schema_migrations`)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	applied := map[int]string{}
+	for rows.Next() {
+		var (
+			version  int
+			checksum string
+		)
+		if err = rows.Scan(&version, &checksum); nil != err {
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	if err = rows.Err(); nil != err {
+		return nil, err
+	}
+
+	for _, migration := range m.migrations {
+		if checksum, ok := applied[migration.Version]; ok && checksum != migration.Checksum {
+			return nil, fmt.Errorf("migration %s: %w", migration, ErrChecksum)
+		}
+	}
+
+	return applied, nil
+}
+
+// run executes the script of the migration and records it in one transaction.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration Migration, script string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.ExecContext(ctx, script); nil != err {
+		return fmt.Errorf("migration %s: %w", migration, err)
+	}
+	if err = record(tx); nil != err {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := Load(fstest.MapFS{
+		"0010_add_email.up.sql":   {Data: []byte("ALTER TABLE users ADD COLUMN email text;")},
+		"0010_add_email.down.sql": {Data: []byte("ALTER TABLE users DROP COLUMN email;")},
+		"0002_users.up.sql":       {Data: []byte("CREATE TABLE users (id serial);")},
+		"0002_users.down.sql":     {Data: []byte("DROP TABLE users;")},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, 2, migrations[0].Version)
+	assert.Equal(t, "0002_users", migrations[0].String())
+	assert.Equal(t, "DROP TABLE users;", migrations[0].Down)
+	assert.Equal(t, 10, migrations[1].Version)
+	assert.Len(t, migrations[1].Checksum, 64)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	var testCases = map[string]fstest.MapFS{
+		"without down": {
+			"0001_users.up.sql": {Data: []byte("CREATE TABLE users (id serial);")},
+		},
+		"unnumbered": {
+			"users.up.sql":   {Data: []byte("CREATE TABLE users (id serial);")},
+			"users.down.sql": {Data: []byte("DROP TABLE users;")},
+		},
+		"version named twice": {
+			"0001_users.up.sql":      {Data: []byte("CREATE TABLE users (id serial);")},
+			"0001_accounts.down.sql": {Data: []byte("DROP TABLE users;")},
+		},
+	}
+
+	for name, files := range testCases {
+		files := files
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(files)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	t.Parallel()
+
+	var versions []string
+	for driver := range schemaMigrations {
+		files, err := fs.Sub(Files, driver)
+		assert.NoError(t, err)
+		migrations, err := Load(files)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+
+		var names []string
+		for i, m := range migrations {
+			assert.Equal(t, i+1, m.Version, "migrations are numbered without gaps")
+			names = append(names, m.String())
+		}
+		// every driver has the same migrations
+		if nil != versions {
+			assert.Equal(t, versions, names)
+		}
+		versions = names
+	}
+}
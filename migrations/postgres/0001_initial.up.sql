@@ -0,0 +1,12 @@
+/** The users table of the original database.sql, which ended with a trailing
+    comma and never ran as written. Databases created from it, fixed by hand,
+    record it with migrate -baseline 1 and migrate from there. */
+
+CREATE TABLE users
+(
+    id        serial PRIMARY KEY,
+    slug      char(20)           not null,
+    full_name varchar(60)        not null,
+    phone     varchar(15) unique not null,
+    password  char(60)           not null
+);
@@ -0,0 +1,5 @@
+DROP INDEX users_slug_idx;
+/** users registered since get the slug they would have had, the base64 of the phone number */
+UPDATE users SET slug = rtrim(encode(convert_to(phone, 'UTF8'), 'base64'), '=') WHERE slug IS NULL;
+ALTER TABLE users ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE users DROP COLUMN public_id;
@@ -0,0 +1,26 @@
+/** The ULID exposed in tokens and responses, it never changes. Existing users
+    get one like handler.NewPublicId, 48 bits of milliseconds followed by 80
+    random bits in Crockford base32, before the column becomes required. */
+ALTER TABLE users ADD COLUMN public_id char(26);
+
+UPDATE users
+SET public_id = (
+    SELECT string_agg(substr('0123456789ABCDEFGHJKMNPQRSTVWXYZ', 1 + CASE
+        WHEN i < 10 THEN ((ms >> (5 * (9 - i))) & 31)::int
+        ELSE floor(random() * 32)::int
+    END, 1), '' ORDER BY i)
+    FROM generate_series(0, 25) i,
+         (SELECT (extract(epoch FROM clock_timestamp()) * 1000)::bigint AS ms) t
+    /** correlated, so every row draws its own random bits */
+    WHERE users.id IS NOT NULL
+)
+WHERE public_id IS NULL;
+
+ALTER TABLE users ALTER COLUMN public_id SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_public_id_key UNIQUE (public_id);
+
+/** legacy base64 of the phone number, still accepted as token subject, null since public ids */
+ALTER TABLE users ALTER COLUMN slug DROP NOT NULL;
+
+/** tokens issued before public ids carry the slug */
+CREATE INDEX users_slug_idx ON users (slug) WHERE slug IS NOT NULL;
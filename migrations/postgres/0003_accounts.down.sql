@@ -0,0 +1,26 @@
+DROP TABLE admin_actions;
+DROP TABLE profile_history;
+DROP TABLE user_preferences;
+DROP TABLE data_exports;
+DROP TABLE phone_changes;
+/** the indexes and checks on the columns go with them */
+ALTER TABLE users
+    DROP COLUMN version,
+    DROP COLUMN email,
+    DROP COLUMN email_verified_at,
+    DROP COLUMN birth_date,
+    DROP COLUMN gender,
+    DROP COLUMN address_street,
+    DROP COLUMN address_city,
+    DROP COLUMN address_province,
+    DROP COLUMN address_postal_code,
+    DROP COLUMN address_country,
+    DROP COLUMN avatar,
+    DROP COLUMN session_version,
+    DROP COLUMN status,
+    DROP COLUMN suspended_at,
+    DROP COLUMN deleted_at,
+    DROP COLUMN password_reset_required,
+    DROP COLUMN admin,
+    DROP COLUMN pii_access,
+    DROP COLUMN created_at;
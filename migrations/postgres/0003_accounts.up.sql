@@ -0,0 +1,127 @@
+/** The profile and the lifecycle of the accounts, and the tables around the
+    users: the changes of their phone numbers, their preferences, the history
+    of their profiles, their data exports and the actions of administrators
+    on them. */
+
+ALTER TABLE users
+    ADD COLUMN version             integer            not null default 1,
+    ADD COLUMN email               varchar(254),
+    /** login by email is only possible once it is verified */
+    ADD COLUMN email_verified_at   timestamptz,
+    ADD COLUMN birth_date          date,
+    ADD COLUMN gender              varchar(6) CHECK (gender IN ('female', 'male', 'other')),
+    /** the address columns are either all NULL or street, city, postal code and country are set */
+    ADD COLUMN address_street      varchar(200),
+    ADD COLUMN address_city        varchar(100),
+    ADD COLUMN address_province    varchar(100),
+    ADD COLUMN address_postal_code varchar(10),
+    ADD COLUMN address_country     char(2),
+    /** blob key prefix of the avatar thumbnails */
+    ADD COLUMN avatar              char(32),
+    /** incremented to revoke every token issued before, embedded into the tokens */
+    ADD COLUMN session_version     integer            not null default 0,
+    /** see repository.CanTransition for the allowed changes */
+    ADD COLUMN status              varchar(9)         not null default 'active'
+        CHECK (status IN ('active', 'suspended', 'deleted')),
+    /** last suspension, tokens issued before it stay rejected after reactivation */
+    ADD COLUMN suspended_at        timestamptz,
+    /** set while the account waits for the purge, it can be restored until then */
+    ADD COLUMN deleted_at          timestamptz,
+    /** set by an administrator, the password has to be changed at the next login */
+    ADD COLUMN password_reset_required boolean        not null default false,
+    /** administrators can see and manage every user */
+    ADD COLUMN admin               boolean            not null default false,
+    /** administrators with it see phone numbers and email addresses in bulk exports */
+    ADD COLUMN pii_access          boolean            not null default false,
+    /** existing users get the time of the migration */
+    ADD COLUMN created_at          timestamptz        not null default now(),
+    ADD CHECK ((address_street IS NULL) = (address_city IS NULL)
+        AND (address_street IS NULL) = (address_postal_code IS NULL)
+        AND (address_street IS NULL) = (address_country IS NULL)),
+    ADD CHECK (email_verified_at IS NULL OR email IS NOT NULL),
+    ADD CHECK ((status = 'deleted') = (deleted_at IS NOT NULL));
+
+/** a verified email address logs in, at most one user per address regardless of case */
+CREATE UNIQUE INDEX users_verified_email_idx ON users (lower(email)) WHERE email_verified_at IS NOT NULL;
+
+/** the admin directory pages through the users newest first, narrowed down by
+    status, registration time or the start of the phone number or name */
+CREATE INDEX users_status_idx ON users (status, id);
+CREATE INDEX users_created_at_idx ON users (created_at);
+CREATE INDEX users_phone_prefix_idx ON users (phone varchar_pattern_ops);
+CREATE INDEX users_full_name_prefix_idx ON users (lower(full_name) varchar_pattern_ops);
+
+/** the purge looks for accounts past their grace period */
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+
+/** Phone number changes waiting for the new number to be verified with an OTP. */
+CREATE TABLE phone_changes
+(
+    id           serial PRIMARY KEY,
+    user_id      integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    phone        varchar(15) not null,
+    otp          char(60)    not null,
+    cancel_token char(64)    not null unique,
+    attempts     smallint    not null default 0,
+    expires_at   timestamptz not null,
+    created_at   timestamptz not null default now(),
+    confirmed_at timestamptz,
+    cancelled_at timestamptz
+);
+
+CREATE INDEX phone_changes_user_id_idx ON phone_changes (user_id);
+
+/** Personal data exports, the archive is kept in the blob store until expires_at. */
+CREATE TABLE data_exports
+(
+    id         serial PRIMARY KEY,
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    status     varchar(7)  not null default 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    blob_key   char(36),
+    created_at timestamptz not null default now(),
+    expires_at timestamptz
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id);
+CREATE INDEX data_exports_expires_at_idx ON data_exports (expires_at);
+
+/** Preferences set by the user, the server default applies to every key without a row. */
+CREATE TABLE user_preferences
+(
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    key        varchar(50) not null,
+    value      jsonb       not null,
+    updated_at timestamptz not null default now(),
+    PRIMARY KEY (user_id, key)
+);
+
+/** Every changed field of a profile, written in the transaction of the change. */
+CREATE TABLE profile_history
+(
+    id         bigserial PRIMARY KEY,
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    field      varchar(30) not null,
+    old_value  text,
+    new_value  text,
+    /** public id of the user making the change, or system */
+    actor      varchar(26) not null,
+    source_ip  inet,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX profile_history_user_id_idx ON profile_history (user_id, id);
+
+/** Actions of administrators on accounts, written in the transaction of the action. */
+CREATE TABLE admin_actions
+(
+    id         bigserial PRIMARY KEY,
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    /** public id of the administrator */
+    admin      varchar(26) not null,
+    action     varchar(20) not null CHECK (action IN ('suspend', 'reactivate', 'password_reset', 'revoke_sessions')),
+    reason     varchar(500) not null,
+    source_ip  inet,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX admin_actions_user_id_idx ON admin_actions (user_id, id);
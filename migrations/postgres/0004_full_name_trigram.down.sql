@@ -0,0 +1,2 @@
+/** the extension is left installed, other databases or schemas may use it */
+DROP INDEX IF EXISTS users_full_name_trgm_idx;
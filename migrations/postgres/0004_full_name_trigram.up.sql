@@ -0,0 +1,12 @@
+/** fuzzy name search matches the words of the name by their trigrams. Installing
+    pg_trgm may need privileges the service lacks, without it the service compares
+    the names itself and the migration only leaves a notice. */
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE INDEX IF NOT EXISTS users_full_name_trgm_idx ON users USING gin (lower(full_name) gin_trgm_ops);
+EXCEPTION
+    WHEN insufficient_privilege OR undefined_file THEN
+        RAISE NOTICE 'pg_trgm is not available, fuzzy name search falls back: %', SQLERRM;
+END
+$$;
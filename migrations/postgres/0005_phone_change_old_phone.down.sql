@@ -0,0 +1 @@
+ALTER TABLE phone_changes DROP COLUMN old_phone;
@@ -0,0 +1,3 @@
+/** the number a confirmed change replaced, the cancel token sent to it reverts
+    the change */
+ALTER TABLE phone_changes ADD COLUMN old_phone varchar(15);
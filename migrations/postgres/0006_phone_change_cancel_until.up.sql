@@ -0,0 +1,3 @@
+/** set at confirmation, the cancel token sent to the old number reverts the
+    change until then, however late in its OTP window it was confirmed */
+ALTER TABLE phone_changes ADD COLUMN cancel_until timestamptz;
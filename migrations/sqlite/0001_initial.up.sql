@@ -0,0 +1,13 @@
+/** The users table of postgres/0001_initial.up.sql for SQLite, the migrations
+    of both databases keep the same versions. Times are stored as text in UTC,
+    2006-01-02 15:04:05.000000000+00:00 as written by the repository, so
+    comparing the text compares the times. */
+
+CREATE TABLE users
+(
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    slug      char(20)           not null,
+    full_name varchar(60)        not null,
+    phone     varchar(15) unique not null,
+    password  char(60)           not null
+);
@@ -0,0 +1,16 @@
+/** SQLite databases never had users without a public id, the users
+    registered since keep a placeholder slug. */
+CREATE TABLE users_new
+(
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    slug      char(20)           not null,
+    full_name varchar(60)        not null,
+    phone     varchar(15) unique not null,
+    password  char(60)           not null
+);
+
+INSERT INTO users_new (id, slug, full_name, phone, password)
+SELECT id, coalesce(slug, substr(public_id, 1, 20)), full_name, phone, password FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
@@ -0,0 +1,39 @@
+/** The ULID exposed in tokens and responses, it never changes. Existing users
+    get one like handler.NewPublicId, 48 bits of milliseconds followed by 80
+    random bits in Crockford base32. SQLite cannot make a column required or
+    drop a constraint in place, the table is copied into its new shape. */
+CREATE TABLE users_new
+(
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    public_id char(26) unique    not null,
+    /** legacy base64 of the phone number, still accepted as token subject, null since public ids */
+    slug      char(20),
+    full_name varchar(60)        not null,
+    phone     varchar(15) unique not null,
+    password  char(60)           not null
+);
+
+INSERT INTO users_new (id, public_id, slug, full_name, phone, password)
+SELECT id,
+       substr(a, 1 + ((ms >> 45) & 31), 1) || substr(a, 1 + ((ms >> 40) & 31), 1) ||
+       substr(a, 1 + ((ms >> 35) & 31), 1) || substr(a, 1 + ((ms >> 30) & 31), 1) ||
+       substr(a, 1 + ((ms >> 25) & 31), 1) || substr(a, 1 + ((ms >> 20) & 31), 1) ||
+       substr(a, 1 + ((ms >> 15) & 31), 1) || substr(a, 1 + ((ms >> 10) & 31), 1) ||
+       substr(a, 1 + ((ms >> 5) & 31), 1) || substr(a, 1 + ((ms >> 0) & 31), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1) ||
+       substr(a, 1 + abs(random() % 32), 1) || substr(a, 1 + abs(random() % 32), 1),
+       slug, full_name, phone, password
+FROM users, (SELECT '0123456789ABCDEFGHJKMNPQRSTVWXYZ' AS a,
+                    CAST((julianday('now') - 2440587.5) * 86400000 AS integer) AS ms);
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+/** tokens issued before public ids carry the slug */
+CREATE INDEX users_slug_idx ON users (slug) WHERE slug IS NOT NULL;
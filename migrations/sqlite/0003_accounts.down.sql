@@ -0,0 +1,24 @@
+DROP TABLE admin_actions;
+DROP TABLE profile_history;
+DROP TABLE user_preferences;
+DROP TABLE data_exports;
+DROP TABLE phone_changes;
+
+CREATE TABLE users_new
+(
+    id        integer PRIMARY KEY AUTOINCREMENT,
+    public_id char(26) unique    not null,
+    slug      char(20),
+    full_name varchar(60)        not null,
+    phone     varchar(15) unique not null,
+    password  char(60)           not null
+);
+
+INSERT INTO users_new (id, public_id, slug, full_name, phone, password)
+SELECT id, public_id, slug, full_name, phone, password FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+/** tokens issued before public ids carry the slug */
+CREATE INDEX users_slug_idx ON users (slug) WHERE slug IS NOT NULL;
@@ -0,0 +1,144 @@
+/** The profile and the lifecycle of the accounts, and the tables around the
+    users, see postgres/0003_accounts.up.sql. SQLite cannot add the checks
+    to the table in place, it is copied into its new shape. */
+CREATE TABLE users_new
+(
+    id                      integer PRIMARY KEY AUTOINCREMENT,
+    /** ULID exposed in tokens and responses, it never changes */
+    public_id               char(26) unique    not null,
+    /** legacy base64 of the phone number, still accepted as token subject, null since public ids */
+    slug                    char(20),
+    full_name               varchar(60)        not null,
+    phone                   varchar(15) unique not null,
+    password                char(60)           not null,
+    version                 integer            not null default 1,
+    email                   varchar(254),
+    /** login by email is only possible once it is verified */
+    email_verified_at       timestamp,
+    birth_date              date,
+    gender                  varchar(6) CHECK (gender IN ('female', 'male', 'other')),
+    /** the address columns are either all NULL or street, city, postal code and country are set */
+    address_street          varchar(200),
+    address_city            varchar(100),
+    address_province        varchar(100),
+    address_postal_code     varchar(10),
+    address_country         char(2),
+    /** blob key prefix of the avatar thumbnails */
+    avatar                  char(32),
+    /** incremented to revoke every token issued before, embedded into the tokens */
+    session_version         integer            not null default 0,
+    /** see repository.CanTransition for the allowed changes */
+    status                  varchar(9)         not null default 'active'
+        CHECK (status IN ('active', 'suspended', 'deleted')),
+    /** last suspension, tokens issued before it stay rejected after reactivation */
+    suspended_at            timestamp,
+    /** set while the account waits for the purge, it can be restored until then */
+    deleted_at              timestamp,
+    /** set by an administrator, the password has to be changed at the next login */
+    password_reset_required boolean            not null default false,
+    /** administrators can see and manage every user */
+    admin                   boolean            not null default false,
+    /** administrators with it see phone numbers and email addresses in bulk exports */
+    pii_access              boolean            not null default false,
+    created_at              timestamp          not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now')),
+    CHECK ((address_street IS NULL) = (address_city IS NULL)
+        AND (address_street IS NULL) = (address_postal_code IS NULL)
+        AND (address_street IS NULL) = (address_country IS NULL)),
+    CHECK (email_verified_at IS NULL OR email IS NOT NULL),
+    CHECK ((status = 'deleted') = (deleted_at IS NOT NULL))
+);
+
+INSERT INTO users_new (id, public_id, slug, full_name, phone, password)
+SELECT id, public_id, slug, full_name, phone, password FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+/** tokens issued before public ids carry the slug */
+CREATE INDEX users_slug_idx ON users (slug) WHERE slug IS NOT NULL;
+
+/** a verified email address logs in, at most one user per address regardless of case */
+CREATE UNIQUE INDEX users_verified_email_idx ON users (lower(email)) WHERE email_verified_at IS NOT NULL;
+
+/** the admin directory pages through the users newest first, narrowed down by
+    status, registration time or the start of the phone number or name */
+CREATE INDEX users_status_idx ON users (status, id);
+CREATE INDEX users_created_at_idx ON users (created_at);
+CREATE INDEX users_phone_prefix_idx ON users (phone);
+CREATE INDEX users_full_name_prefix_idx ON users (lower(full_name));
+
+/** the purge looks for accounts past their grace period */
+CREATE INDEX users_deleted_at_idx ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+
+/** Phone number changes waiting for the new number to be verified with an OTP. */
+CREATE TABLE phone_changes
+(
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    user_id      integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    phone        varchar(15) not null,
+    otp          char(60)    not null,
+    cancel_token char(64)    not null unique,
+    attempts     smallint    not null default 0,
+    expires_at   timestamp   not null,
+    created_at   timestamp   not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now')),
+    confirmed_at timestamp,
+    cancelled_at timestamp
+);
+
+CREATE INDEX phone_changes_user_id_idx ON phone_changes (user_id);
+
+/** Personal data exports, the archive is kept in the blob store until expires_at. */
+CREATE TABLE data_exports
+(
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    status     varchar(7)  not null default 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    blob_key   char(36),
+    created_at timestamp   not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now')),
+    expires_at timestamp
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id);
+CREATE INDEX data_exports_expires_at_idx ON data_exports (expires_at);
+
+/** Preferences set by the user, the server default applies to every key without a row. */
+CREATE TABLE user_preferences
+(
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    key        varchar(50) not null,
+    /** JSON document */
+    value      text        not null,
+    updated_at timestamp   not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now')),
+    PRIMARY KEY (user_id, key)
+);
+
+/** Every changed field of a profile, written in the transaction of the change. */
+CREATE TABLE profile_history
+(
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer     not null REFERENCES users (id) ON DELETE CASCADE,
+    field      varchar(30) not null,
+    old_value  text,
+    new_value  text,
+    /** public id of the user making the change, or system */
+    actor      varchar(26) not null,
+    source_ip  varchar(45),
+    created_at timestamp   not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now'))
+);
+
+CREATE INDEX profile_history_user_id_idx ON profile_history (user_id, id);
+
+/** Actions of administrators on accounts, written in the transaction of the action. */
+CREATE TABLE admin_actions
+(
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    user_id    integer      not null REFERENCES users (id) ON DELETE CASCADE,
+    /** public id of the administrator */
+    admin      varchar(26)  not null,
+    action     varchar(20)  not null CHECK (action IN ('suspend', 'reactivate', 'password_reset', 'revoke_sessions')),
+    reason     varchar(500) not null,
+    source_ip  varchar(45),
+    created_at timestamp    not null default (strftime('%Y-%m-%d %H:%M:%f000000+00:00', 'now'))
+);
+
+CREATE INDEX admin_actions_user_id_idx ON admin_actions (user_id, id);
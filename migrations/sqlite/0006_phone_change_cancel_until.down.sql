@@ -0,0 +1 @@
+ALTER TABLE phone_changes DROP COLUMN cancel_until;
@@ -0,0 +1,14 @@
+// This file contains the interfaces for the notification layer.
+// The notification layer is responsible for delivering messages to users
+// outside of the API, such as one time passwords.
+package notification
+
+import "context"
+
+type SMSSender interface {
+	Send(ctx context.Context, phone string, message string) error
+}
+
+type Mailer interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
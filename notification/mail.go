@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+	"time"
+)
+
+// FileMailer writes every mail as an .eml file into a directory instead of
+// delivering it, meant for local development until an SMTP relay or mail API
+// is plugged in. The files open in any mail client.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+type NewFileMailerOptions struct {
+	Dir  string
+	From string
+}
+
+func NewFileMailer(opts NewFileMailerOptions) *FileMailer {
+	return &FileMailer{dir: opts.Dir, from: opts.From}
+}
+
+func (m *FileMailer) Send(_ context.Context, to string, subject string, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("notification: line break in mail header")
+	}
+	if err := os.MkdirAll(m.dir, 0o755); nil != err {
+		return err
+	}
+
+	f, err := os.CreateTemp(m.dir, time.Now().UTC().Format("20060102T150405")+"-*.eml")
+	if nil != err {
+		return err
+	}
+
+	_, err = fmt.Fprintf(f, "From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s",
+		m.from,
+		to,
+		mime.QEncoding.Encode("utf-8", subject),
+		time.Now().Format(time.RFC1123Z),
+		strings.ReplaceAll(body, "\n", "\r\n"),
+	)
+	if closeErr := f.Close(); nil == err {
+		err = closeErr
+	}
+
+	return err
+}
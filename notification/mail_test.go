@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := NewFileMailer(NewFileMailerOptions{Dir: dir, From: "no-reply@example.com"})
+
+	assert.NoError(t, m.Send(context.Background(), "budi@example.com", "Verify your email", "Open the link.\n"))
+	assert.Error(t, m.Send(context.Background(), "budi@example.com\r\nBcc: ani@example.com", "Verify", ""))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		data, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "To: budi@example.com\r\n")
+		assert.Contains(t, string(data), "\r\n\r\nOpen the link.\r\n")
+	}
+}
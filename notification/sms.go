@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LogSMSSender writes every text message into a writer instead of delivering
+// it, meant for local development until an SMS gateway is plugged in.
+type LogSMSSender struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLogSMSSender(w io.Writer) *LogSMSSender {
+	return &LogSMSSender{w: w}
+}
+
+func (s *LogSMSSender) Send(_ context.Context, phone string, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := fmt.Fprintf(s.w, "sms to %s: %s\n", phone, message)
+	return err
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Drivers NewRepository opens, see NewRepositoryOptions.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// dialect covers what differs between the databases. Queries are written for
+// Postgres with ? placeholders, numbered into $1, $2... before they run,
+// which both drivers accept.
+type dialect struct {
+	// forUpdate locks the selected rows until the transaction ends
+	forUpdate string
+	// dateText formats the date column as YYYY-MM-DD
+	dateText func(column string) string
+	// arg converts an argument into what the driver stores
+	arg func(v any) any
+	// uniqueViolation tells whether err violates a unique constraint
+	uniqueViolation func(err error) bool
+	// trigram tells whether the database may have pg_trgm
+	trigram bool
+	// open opens the database at dsn
+	open func(dsn string) (*sql.DB, error)
+}
+
+// dialects by driver, SQLite registers itself when built with the sqlite tag.
+var dialects = map[string]dialect{
+	DriverPostgres: postgres,
+}
+
+// DB runs the queries of the repository on the database of its driver.
+type DB struct {
+	*sql.DB
+	driver  string
+	dialect dialect
+}
+
+// Driver is the driver the database was opened with.
+func (db *DB) Driver() string {
+	return db.driver
+}
+
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.DB.ExecContext(ctx, number(query), db.dialect.args(args)...)
+}
+
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.DB.QueryContext(ctx, number(query), db.dialect.args(args)...)
+}
+
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.DB.QueryRowContext(ctx, number(query), db.dialect.args(args)...)
+}
+
+func (db *DB) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	stmt, err := db.DB.PrepareContext(ctx, number(query))
+	if nil != err {
+		return nil, err
+	}
+
+	return &Stmt{Stmt: stmt, dialect: db.dialect}, nil
+}
+
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if nil != err {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
+}
+
+// Tx is a transaction of DB.
+type Tx struct {
+	*sql.Tx
+	dialect dialect
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, number(query), tx.dialect.args(args)...)
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, number(query), tx.dialect.args(args)...)
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, number(query), tx.dialect.args(args)...)
+}
+
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*Stmt, error) {
+	stmt, err := tx.Tx.PrepareContext(ctx, number(query))
+	if nil != err {
+		return nil, err
+	}
+
+	return &Stmt{Stmt: stmt, dialect: tx.dialect}, nil
+}
+
+// Stmt is a prepared statement of DB.
+type Stmt struct {
+	*sql.Stmt
+	dialect dialect
+}
+
+func (s *Stmt) QueryRow(args ...any) *sql.Row {
+	return s.Stmt.QueryRow(s.dialect.args(args)...)
+}
+
+func (s *Stmt) QueryRowContext(ctx context.Context, args ...any) *sql.Row {
+	return s.Stmt.QueryRowContext(ctx, s.dialect.args(args)...)
+}
+
+func (d dialect) args(args []any) []any {
+	if nil == d.arg {
+		return args
+	}
+
+	converted := make([]any, len(args))
+	for i, v := range args {
+		converted[i] = d.arg(v)
+	}
+
+	return converted
+}
+
+// number replaces the ? placeholders of query with $1, $2..., leaving the
+// ones in string literals alone.
+func number(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var (
+		b       strings.Builder
+		n       int
+		literal bool
+	)
+	b.Grow(len(query) + 8)
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			literal = !literal
+		case c == '?' && !literal:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		query, expected string
+	}
+	var testCases = []Case{
+		{query: `SELECT 1`, expected: `SELECT 1`},
+		{query: `SELECT id FROM users WHERE phone=? AND (?=0 OR version=?)`, expected: `SELECT id FROM users WHERE phone=$1 AND ($2=0 OR version=$3)`},
+		// placeholders in literals are text
+		{query: `SELECT '?', ? FROM users WHERE name LIKE ? ESCAPE '\'`, expected: `SELECT '?', $1 FROM users WHERE name LIKE $2 ESCAPE '\'`},
+		{query: `SELECT 'it''s ?', ?`, expected: `SELECT 'it''s ?', $1`},
+	}
+
+	for _, cases := range testCases {
+		assert.Equal(t, cases.expected, number(cases.query))
+	}
+}
@@ -1,9 +1,25 @@
 package repository
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 func (r *Repository) FindByPhone(ctx context.Context, input FindByPhoneInput) (FindByPhoneOutput, error) {
-	stmt, err := r.Db.PrepareContext(ctx, `SELECT id, slug, full_name, phone, password FROM users where phone=?`)
+	return r.findLogin(ctx, `phone=?`, input.Phone)
+}
+
+// FindByEmail only finds verified email addresses, ignoring their case.
+func (r *Repository) FindByEmail(ctx context.Context, input FindByEmailInput) (FindByPhoneOutput, error) {
+	return r.findLogin(ctx, `lower(email)=lower(?) AND email_verified_at IS NOT NULL`, input.Email)
+}
+
+// findLogin finds the user logging in by the condition.
+func (r *Repository) findLogin(ctx context.Context, condition string, args ...any) (FindByPhoneOutput, error) {
+	stmt, err := r.Db.PrepareContext(ctx, `SELECT id, public_id, full_name, phone, password, session_version, status, password_reset_required, deleted_at FROM users where `+condition)
 	if nil != err {
 		return FindByPhoneOutput{}, err
 	}
@@ -13,13 +29,17 @@ func (r *Repository) FindByPhone(ctx context.Context, input FindByPhoneInput) (F
 
 	var output FindByPhoneOutput
 	if err = stmt.QueryRow(
-		input.Phone,
+		args...,
 	).Scan(
 		&output.Id,
-		&output.Slug,
+		&output.PublicId,
 		&output.FullName,
 		&output.Phone,
 		&output.Password,
+		&output.SessionVersion,
+		&output.Status,
+		&output.PasswordResetRequired,
+		&output.DeletedAt,
 	); nil != err {
 		return FindByPhoneOutput{}, err
 	}
@@ -27,32 +47,130 @@ func (r *Repository) FindByPhone(ctx context.Context, input FindByPhoneInput) (F
 	return output, nil
 }
 
-func (r *Repository) FindBySlug(ctx context.Context, input FindBySlugInput) (FindBySlugOutput, error) {
-	stmt, err := r.Db.PrepareContext(ctx, `SELECT slug, full_name, phone, password FROM users where slug=?`)
+func (r *Repository) FindByPublicId(ctx context.Context, input FindByPublicIdInput) (FindByPublicIdOutput, error) {
+	stmt, err := r.Db.PrepareContext(ctx, `SELECT public_id, full_name, phone, password, version, email, email_verified_at, birth_date, gender,
+		address_street, address_city, address_province, address_postal_code, address_country, avatar FROM users where public_id=?`)
 	if nil != err {
-		return FindBySlugOutput{}, err
+		return FindByPublicIdOutput{}, err
 	}
 	defer func() {
 		_ = stmt.Close()
 	}()
 
-	var output FindBySlugOutput
+	var (
+		output  FindByPublicIdOutput
+		address [5]*string
+	)
 	if err = stmt.QueryRow(
-		input.Slug,
+		input.PublicId,
 	).Scan(
-		&output.Slug,
+		&output.PublicId,
 		&output.FullName,
 		&output.Phone,
 		&output.Password,
+		&output.Version,
+		&output.Email,
+		&output.EmailVerifiedAt,
+		&output.BirthDate,
+		&output.Gender,
+		&address[0],
+		&address[1],
+		&address[2],
+		&address[3],
+		&address[4],
+		&output.Avatar,
 	); nil != err {
-		return FindBySlugOutput{}, err
+		return FindByPublicIdOutput{}, err
+	}
+	if nil != address[0] {
+		output.Address = &Address{
+			Street:     *address[0],
+			City:       *address[1],
+			Province:   *address[2],
+			PostalCode: *address[3],
+			Country:    *address[4],
+		}
 	}
 
 	return output, nil
 }
 
+// attributeColumns are the users columns of ProfileAttributes in the order
+// of attributeArgs. Changing the email address takes its verification back.
+const attributeColumns = `email_verified_at=CASE WHEN email IS NOT DISTINCT FROM ? THEN email_verified_at END, email=?,
+	birth_date=?, gender=?, address_street=?, address_city=?, address_province=?, address_postal_code=?, address_country=?`
+
+// attributeArgs returns the values of attributeColumns, nil pointers are
+// stored as NULL.
+func attributeArgs(a ProfileAttributes) []any {
+	args := []any{a.Email, a.Email, a.BirthDate, a.Gender}
+	if nil == a.Address {
+		return append(args, nil, nil, nil, nil, nil)
+	}
+
+	return append(args, a.Address.Street, a.Address.City, a.Address.Province, a.Address.PostalCode, a.Address.Country)
+}
+
+// patchedAttributes returns the columns of the attributes set in a, or named
+// in remove to be cleared, leaving the other ones as they are. Of the address
+// only addressMembers are written, when there are any.
+func patchedAttributes(a *ProfileAttributes, remove, addressMembers []string) ([]string, []any) {
+	var set ProfileAttributes
+	if nil != a {
+		set = *a
+	}
+	removed := map[string]bool{}
+	for _, name := range remove {
+		removed[name] = true
+	}
+
+	var (
+		sets []string
+		args []any
+	)
+	if nil != set.Email || removed["email"] {
+		sets = append(sets, `email_verified_at=CASE WHEN email IS NOT DISTINCT FROM ? THEN email_verified_at END, email=?`)
+		args = append(args, set.Email, set.Email)
+	}
+	if nil != set.BirthDate || removed["birth_date"] {
+		sets = append(sets, `birth_date=?`)
+		args = append(args, set.BirthDate)
+	}
+	if nil != set.Gender || removed["gender"] {
+		sets = append(sets, `gender=?`)
+		args = append(args, set.Gender)
+	}
+	switch {
+	case removed["address"]:
+		sets = append(sets, `address_street=?, address_city=?, address_province=?, address_postal_code=?, address_country=?`)
+		args = append(args, nil, nil, nil, nil, nil)
+	case nil != set.Address:
+		written := map[string]bool{}
+		for _, member := range addressMembers {
+			written[member] = true
+		}
+		for _, column := range []struct {
+			member string
+			value  string
+		}{
+			{member: "street", value: set.Address.Street},
+			{member: "city", value: set.Address.City},
+			{member: "province", value: set.Address.Province},
+			{member: "postal_code", value: set.Address.PostalCode},
+			{member: "country", value: set.Address.Country},
+		} {
+			if len(written) == 0 || written[column.member] {
+				sets = append(sets, `address_`+column.member+`=?`)
+				args = append(args, column.value)
+			}
+		}
+	}
+
+	return sets, args
+}
+
 func (r *Repository) Store(ctx context.Context, input RegistrationInput) (RegistrationOutput, error) {
-	stmt, err := r.Db.PrepareContext(ctx, `INSERT INTO users (slug, full_name, phone, password) VALUES (?, ?, ?, ?) RETURNING id`)
+	stmt, err := r.Db.PrepareContext(ctx, `INSERT INTO users (public_id, full_name, phone, password, admin) VALUES (?, ?, ?, ?, ?) RETURNING id`)
 	if nil != err {
 		return RegistrationOutput{}, err
 	}
@@ -62,10 +180,11 @@ func (r *Repository) Store(ctx context.Context, input RegistrationInput) (Regist
 
 	var output RegistrationOutput
 	if err = stmt.QueryRow(
-		input.Slug,
+		input.PublicId,
 		input.FullName,
 		input.Phone,
 		input.Password,
+		input.Admin,
 	).Scan(&output.Id); nil != err {
 		return RegistrationOutput{}, err
 	}
@@ -73,19 +192,1161 @@ func (r *Repository) Store(ctx context.Context, input RegistrationInput) (Regist
 	return output, nil
 }
 
-func (r *Repository) Put(ctx context.Context, input UpdateUserInput) error {
-	stmt, err := r.Db.PrepareContext(ctx, `UPDATE users SET full_name=?, phone=? where slug=?`)
+func (r *Repository) Put(ctx context.Context, input UpdateUserInput) (UpdateUserOutput, error) {
+	var output UpdateUserOutput
+	err := r.tracked(ctx, input.Actor, `public_id=?`, input.PublicId, func(tx *Tx) error {
+		args := append([]any{input.FullName, input.Phone}, attributeArgs(input.ProfileAttributes)...)
+
+		return versionReturned(tx.QueryRowContext(
+			ctx,
+			`UPDATE users SET full_name=?, phone=?, `+attributeColumns+`, version=version+1
+			where public_id=? AND (?=0 OR version=?) RETURNING version`,
+			append(args, input.PublicId, input.Version, input.Version)...,
+		), &output.Version)
+	})
+	if nil != err {
+		return UpdateUserOutput{}, err
+	}
+
+	return output, nil
+}
+
+func (r *Repository) Patch(ctx context.Context, input PatchUserInput) (UpdateUserOutput, error) {
+	var (
+		sets []string
+		args []any
+	)
+	if nil != input.FullName {
+		sets = append(sets, "full_name=?")
+		args = append(args, *input.FullName)
+	}
+	if nil != input.Phone {
+		sets = append(sets, "phone=?")
+		args = append(args, *input.Phone)
+	}
+	attrSets, attrArgs := patchedAttributes(input.Attributes, input.Remove, input.AddressMembers)
+	sets = append(sets, attrSets...)
+	args = append(args, attrArgs...)
+	var output UpdateUserOutput
+	if len(sets) == 0 {
+		err := r.Db.QueryRowContext(ctx, `SELECT version FROM users WHERE public_id=?`, input.PublicId).Scan(&output.Version)
+
+		return output, err
+	}
+
+	err := r.tracked(ctx, input.Actor, `public_id=?`, input.PublicId, func(tx *Tx) error {
+		return versionReturned(tx.QueryRowContext(
+			ctx,
+			`UPDATE users SET `+strings.Join(sets, ", ")+`, version=version+1
+			where public_id=? AND (?=0 OR version=?) RETURNING version`,
+			append(args, input.PublicId, input.Version, input.Version)...,
+		), &output.Version)
+	})
+	if nil != err {
+		return UpdateUserOutput{}, err
+	}
+
+	return output, nil
+}
+
+// PutAvatar returns sql.ErrNoRows when the user does not exist.
+func (r *Repository) PutAvatar(ctx context.Context, input UpdateAvatarInput) error {
+	return r.tracked(ctx, input.Actor, `public_id=?`, input.PublicId, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET avatar=?, version=version+1 where public_id=?`, input.Avatar, input.PublicId)
+
+		return err
+	})
+}
+
+// historyFields are the fields recorded in profile_history with the users
+// columns they are read from, as text.
+var historyFields = []struct {
+	field  string
+	column string
+	// date columns are read as YYYY-MM-DD
+	date bool
+}{
+	{field: "full_name", column: "full_name"},
+	{field: "phone", column: "phone"},
+	{field: "email", column: "email"},
+	{field: "email_verified_at", column: "email_verified_at"},
+	{field: "birth_date", column: "birth_date", date: true},
+	{field: "gender", column: "gender"},
+	{field: "address.street", column: "address_street"},
+	{field: "address.city", column: "address_city"},
+	{field: "address.province", column: "address_province"},
+	{field: "address.postal_code", column: "address_postal_code"},
+	{field: "address.country", column: "address_country"},
+	{field: "avatar", column: "avatar"},
+}
+
+// tracked runs update in a transaction and writes every profile field it
+// changed into profile_history. The user is selected by condition and
+// locked, sql.ErrNoRows is returned when it does not exist.
+func (r *Repository) tracked(ctx context.Context, actor Actor, condition string, arg any, update func(tx *Tx) error) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
 	if nil != err {
 		return err
 	}
 	defer func() {
-		_ = stmt.Close()
+		_ = tx.Rollback()
+	}()
+
+	id, before, err := profileSnapshot(ctx, tx, condition+tx.dialect.forUpdate, arg)
+	if nil != err {
+		return err
+	}
+	if err = update(tx); nil != err {
+		return err
+	}
+	_, after, err := profileSnapshot(ctx, tx, `id=?`, id)
+	if nil != err {
+		return err
+	}
+
+	var ip *string
+	if actor.IP != "" {
+		ip = &actor.IP
+	}
+	for i, f := range historyFields {
+		if equalText(before[i], after[i]) {
+			continue
+		}
+		if _, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO profile_history (user_id, field, old_value, new_value, actor, source_ip) VALUES (?, ?, ?, ?, ?, ?)`,
+			id,
+			f.field,
+			before[i],
+			after[i],
+			actor.PublicId,
+			ip,
+		); nil != err {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// profileSnapshot reads the historyFields of the user selected by condition.
+func profileSnapshot(ctx context.Context, tx *Tx, condition string, arg any) (int, []*string, error) {
+	columns := make([]string, 0, len(historyFields))
+	for _, f := range historyFields {
+		if f.date {
+			columns = append(columns, tx.dialect.dateText(f.column))
+			continue
+		}
+		columns = append(columns, f.column)
+	}
+
+	var id int
+	values := make([]*string, len(historyFields))
+	dest := []any{&id}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	if err := tx.QueryRowContext(
+		ctx,
+		`SELECT id, `+strings.Join(columns, ", ")+` FROM users WHERE `+condition,
+		arg,
+	).Scan(dest...); nil != err {
+		return 0, nil, err
+	}
+
+	return id, values, nil
+}
+
+func equalText(a, b *string) bool {
+	if nil == a || nil == b {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// FindProfileHistory returns a page of the profile changes of the user,
+// newest first.
+func (r *Repository) FindProfileHistory(ctx context.Context, input FindProfileHistoryInput) ([]ProfileHistoryOutput, error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT h.id, h.field, h.old_value, h.new_value, h.actor, h.source_ip, h.created_at
+		FROM profile_history h JOIN users u ON u.id=h.user_id
+		WHERE u.public_id=? AND (?=0 OR h.id<?) ORDER BY h.id DESC LIMIT ?`,
+		input.PublicId,
+		input.Before,
+		input.Before,
+		input.Limit,
+	)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var output []ProfileHistoryOutput
+	for rows.Next() {
+		var row ProfileHistoryOutput
+		if err = rows.Scan(
+			&row.Id,
+			&row.Field,
+			&row.OldValue,
+			&row.NewValue,
+			&row.Actor,
+			&row.SourceIP,
+			&row.ChangedAt,
+		); nil != err {
+			return nil, err
+		}
+		output = append(output, row)
+	}
+
+	return output, rows.Err()
+}
+
+// FindSession looks the subject of a token up, either a public id or the
+// legacy slug of tokens issued before public ids. They never collide, a slug
+// is at most 20 characters and a public id 26.
+func (r *Repository) FindSession(ctx context.Context, input FindSessionInput) (FindSessionOutput, error) {
+	var output FindSessionOutput
+	if err := r.Db.QueryRowContext(
+		ctx,
+		`SELECT public_id, session_version, status, suspended_at, deleted_at, admin, pii_access FROM users where public_id=? OR slug=?`,
+		input.Subject,
+		input.Subject,
+	).Scan(
+		&output.PublicId,
+		&output.SessionVersion,
+		&output.Status,
+		&output.SuspendedAt,
+		&output.DeletedAt,
+		&output.Admin,
+		&output.PiiAccess,
+	); nil != err {
+		return FindSessionOutput{}, err
+	}
+
+	return output, nil
+}
+
+// SoftDelete marks the user as deleted and revokes every token issued so far,
+// the row stays until PurgeDeleted so the account can be restored.
+func (r *Repository) SoftDelete(ctx context.Context, input DeleteUserInput) (DeleteUserOutput, error) {
+	var output DeleteUserOutput
+	if err := r.Db.QueryRowContext(
+		ctx,
+		`UPDATE users SET status='deleted', deleted_at=now(), session_version=session_version+1, version=version+1
+		WHERE public_id=? AND status IN `+statusesTo(StatusDeleted)+` RETURNING deleted_at`,
+		input.PublicId,
+	).Scan(&output.DeletedAt); nil != err {
+		return DeleteUserOutput{}, err
+	}
+
+	return output, nil
+}
+
+func (r *Repository) Restore(ctx context.Context, input RestoreUserInput) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`UPDATE users SET status='active', deleted_at=NULL, version=version+1 WHERE id=? AND status='deleted'`,
+		input.Id,
+	)
+
+	return err
+}
+
+// statusesTo returns the SQL list of the statuses allowed to move to status.
+// The statuses are constants, they are safe to inline.
+func statusesTo(status string) string {
+	var from []string
+	for _, s := range []string{StatusActive, StatusSuspended, StatusDeleted} {
+		if CanTransition(s, status) {
+			from = append(from, "'"+s+"'")
+		}
+	}
+
+	return "(" + strings.Join(from, ", ") + ")"
+}
+
+// PurgeDeleted removes the users deleted before the given time for good,
+// which frees their phone numbers for new registrations.
+func (r *Repository) PurgeDeleted(ctx context.Context, input PurgeDeletedUsersInput) (PurgeDeletedUsersOutput, error) {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if nil != err {
+		return PurgeDeletedUsersOutput{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var output PurgeDeletedUsersOutput
+	// the archives live outside the database, hand their keys to the caller
+	// before the rows cascade away
+	if output.Exports, err = scanStrings(tx.QueryContext(
+		ctx,
+		`DELETE FROM data_exports WHERE blob_key IS NOT NULL AND user_id IN (SELECT id FROM users WHERE deleted_at < ?) RETURNING blob_key`,
+		input.DeletedBefore,
+	)); nil != err {
+		return PurgeDeletedUsersOutput{}, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM users WHERE deleted_at < ? RETURNING avatar`, input.DeletedBefore)
+	if nil != err {
+		return PurgeDeletedUsersOutput{}, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var avatar *string
+		if err = rows.Scan(&avatar); nil != err {
+			return PurgeDeletedUsersOutput{}, err
+		}
+		output.Count++
+		if nil != avatar {
+			output.Avatars = append(output.Avatars, *avatar)
+		}
+	}
+	if err = rows.Err(); nil != err {
+		return PurgeDeletedUsersOutput{}, err
+	}
+
+	return output, tx.Commit()
+}
+
+// scanStrings collects the single string column of rows.
+func scanStrings(rows *sql.Rows, err error) ([]string, error) {
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var output []string
+	for rows.Next() {
+		var value string
+		if err = rows.Scan(&value); nil != err {
+			return nil, err
+		}
+		output = append(output, value)
+	}
+
+	return output, rows.Err()
+}
+
+// versionReturned scans the version returned by a guarded update, no row
+// means ErrVersionConflict as the caller locked the existing row before.
+func versionReturned(row *sql.Row, version *int) error {
+	err := row.Scan(version)
+	if err == sql.ErrNoRows {
+		return ErrVersionConflict
+	}
+
+	return err
+}
+
+func (r *Repository) FindAllPhone(ctx context.Context) ([]FindAllPhoneOutput, error) {
+	rows, err := r.Db.QueryContext(ctx, `SELECT id, phone FROM users ORDER BY id`)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var output []FindAllPhoneOutput
+	for rows.Next() {
+		var row FindAllPhoneOutput
+		if err = rows.Scan(&row.Id, &row.Phone); nil != err {
+			return nil, err
+		}
+		output = append(output, row)
+	}
+
+	return output, rows.Err()
+}
+
+func (r *Repository) PutPhone(ctx context.Context, input UpdatePhoneInput) error {
+	return r.tracked(ctx, input.Actor, `id=?`, input.Id, func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET phone=?, version=version+1 where id=?`, input.Phone, input.Id)
+
+		return err
+	})
+}
+
+func (r *Repository) StorePhoneChange(ctx context.Context, input StorePhoneChangeInput) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// a user only has one pending change, the newest request wins
+	if _, err = tx.ExecContext(
+		ctx,
+		`UPDATE phone_changes SET cancelled_at=now() WHERE user_id=(SELECT id FROM users WHERE public_id=?) AND confirmed_at IS NULL AND cancelled_at IS NULL`,
+		input.PublicId,
+	); nil != err {
+		return err
+	}
+
+	if _, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO phone_changes (user_id, phone, otp, cancel_token, expires_at) SELECT id, ?, ?, ?, ? FROM users WHERE public_id=?`,
+		input.Phone,
+		input.Otp,
+		input.CancelToken,
+		input.ExpiresAt,
+		input.PublicId,
+	); nil != err {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) FindPendingPhoneChange(ctx context.Context, input FindPendingPhoneChangeInput) (FindPendingPhoneChangeOutput, error) {
+	return r.findPendingPhoneChange(
+		ctx,
+		`SELECT pc.id, pc.user_id, u.public_id, pc.phone, pc.otp, pc.attempts, pc.confirmed_at IS NOT NULL, pc.expires_at
+		FROM phone_changes pc JOIN users u ON u.id=pc.user_id
+		WHERE u.public_id=? AND pc.confirmed_at IS NULL AND pc.cancelled_at IS NULL AND pc.expires_at > now()`,
+		input.PublicId,
+	)
+}
+
+// FindPhoneChangeByCancelToken finds the change the token cancels, a pending
+// one until it expires and a confirmed one until its cancel_until.
+func (r *Repository) FindPhoneChangeByCancelToken(ctx context.Context, input FindPhoneChangeByCancelTokenInput) (FindPendingPhoneChangeOutput, error) {
+	return r.findPendingPhoneChange(
+		ctx,
+		`SELECT pc.id, pc.user_id, u.public_id, pc.phone, pc.otp, pc.attempts, pc.confirmed_at IS NOT NULL, pc.expires_at
+		FROM phone_changes pc JOIN users u ON u.id=pc.user_id
+		WHERE pc.cancel_token=? AND pc.cancelled_at IS NULL
+		AND (pc.confirmed_at IS NULL AND pc.expires_at > now() OR pc.cancel_until > now())`,
+		input.CancelToken,
+	)
+}
+
+func (r *Repository) findPendingPhoneChange(ctx context.Context, query string, args ...any) (FindPendingPhoneChangeOutput, error) {
+	var output FindPendingPhoneChangeOutput
+	if err := r.Db.QueryRowContext(ctx, query, args...).Scan(
+		&output.Id,
+		&output.UserId,
+		&output.PublicId,
+		&output.Phone,
+		&output.Otp,
+		&output.Attempts,
+		&output.Confirmed,
+		&output.ExpiresAt,
+	); nil != err {
+		return FindPendingPhoneChangeOutput{}, err
+	}
+
+	return output, nil
+}
+
+func (r *Repository) PutPhoneChangeAttempt(ctx context.Context, input PhoneChangeInput) error {
+	_, err := r.Db.ExecContext(ctx, `UPDATE phone_changes SET attempts=attempts+1 WHERE id=?`, input.Id)
+
+	return err
+}
+
+// ConfirmPhoneChange replaces the phone number of the user with the pending
+// one, ErrPhoneTaken is returned when the number was registered by someone
+// else since the change was requested.
+func (r *Repository) ConfirmPhoneChange(ctx context.Context, input PhoneChangeInput) error {
+	return r.tracked(ctx, input.Actor, `id=(SELECT user_id FROM phone_changes WHERE id=?)`, input.Id, func(tx *Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			`UPDATE phone_changes SET confirmed_at=now(), cancel_until=?, old_phone=(SELECT phone FROM users WHERE id=phone_changes.user_id)
+			WHERE id=? AND confirmed_at IS NULL AND cancelled_at IS NULL AND expires_at > now()`,
+			input.CancelUntil,
+			input.Id,
+		)
+		if nil != err {
+			return err
+		}
+		// the change was cancelled in between
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE users SET phone=(SELECT phone FROM phone_changes WHERE id=?), version=version+1 WHERE id=(SELECT user_id FROM phone_changes WHERE id=?)`,
+			input.Id,
+			input.Id,
+		)
+		if tx.dialect.uniqueViolation(err) {
+			return ErrPhoneTaken
+		}
+
+		return err
+	})
+}
+
+// CancelPhoneChange cancels a pending change. A confirmed one is reverted until
+// its cancel_until to the number it replaced, revoking every token
+// issued so far, ErrPhoneTaken is returned when the number was registered by
+// someone else since.
+func (r *Repository) CancelPhoneChange(ctx context.Context, input PhoneChangeInput) error {
+	return r.tracked(ctx, input.Actor, `id=(SELECT user_id FROM phone_changes WHERE id=?)`, input.Id, func(tx *Tx) error {
+		var (
+			userId   int
+			oldPhone *string
+		)
+		err := tx.QueryRowContext(
+			ctx,
+			`UPDATE phone_changes SET cancelled_at=now()
+			WHERE id=? AND cancelled_at IS NULL AND (confirmed_at IS NULL OR cancel_until > now()) RETURNING user_id, old_phone`,
+			input.Id,
+		).Scan(&userId, &oldPhone)
+		switch {
+		// cancelled already, or too late to revert
+		case err == sql.ErrNoRows:
+			return nil
+		case nil != err:
+			return err
+		case nil == oldPhone:
+			return nil
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE users SET phone=?, session_version=session_version+1, version=version+1 WHERE id=?`,
+			*oldPhone,
+			userId,
+		)
+		if tx.dialect.uniqueViolation(err) {
+			return ErrPhoneTaken
+		}
+
+		return err
+	})
+}
+
+func (r *Repository) FindPhoneChanges(ctx context.Context, input FindByPublicIdInput) ([]FindPhoneChangesOutput, error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT pc.phone, pc.attempts, pc.created_at, pc.expires_at, pc.confirmed_at, pc.cancelled_at
+		FROM phone_changes pc JOIN users u ON u.id=pc.user_id WHERE u.public_id=? ORDER BY pc.id`,
+		input.PublicId,
+	)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var output []FindPhoneChangesOutput
+	for rows.Next() {
+		var row FindPhoneChangesOutput
+		if err = rows.Scan(
+			&row.Phone,
+			&row.Attempts,
+			&row.CreatedAt,
+			&row.ExpiresAt,
+			&row.ConfirmedAt,
+			&row.CancelledAt,
+		); nil != err {
+			return nil, err
+		}
+		output = append(output, row)
+	}
+
+	return output, rows.Err()
+}
+
+func (r *Repository) StoreDataExport(ctx context.Context, input FindByPublicIdInput) (DataExportOutput, error) {
+	return r.findDataExport(
+		ctx,
+		`INSERT INTO data_exports (user_id) SELECT id FROM users WHERE public_id=?
+		RETURNING id, status, blob_key, created_at, expires_at`,
+		input.PublicId,
+	)
+}
+
+func (r *Repository) FindLatestDataExport(ctx context.Context, input FindByPublicIdInput) (DataExportOutput, error) {
+	return r.findDataExport(
+		ctx,
+		`SELECT de.id, de.status, de.blob_key, de.created_at, de.expires_at FROM data_exports de JOIN users u ON u.id=de.user_id
+		WHERE u.public_id=? ORDER BY de.id DESC LIMIT 1`,
+		input.PublicId,
+	)
+}
+
+func (r *Repository) FindDataExport(ctx context.Context, input DataExportInput) (DataExportOutput, error) {
+	return r.findDataExport(
+		ctx,
+		`SELECT id, status, blob_key, created_at, expires_at FROM data_exports WHERE id=?`,
+		input.Id,
+	)
+}
+
+func (r *Repository) findDataExport(ctx context.Context, query string, args ...any) (DataExportOutput, error) {
+	var output DataExportOutput
+	if err := r.Db.QueryRowContext(ctx, query, args...).Scan(
+		&output.Id,
+		&output.Status,
+		&output.BlobKey,
+		&output.CreatedAt,
+		&output.ExpiresAt,
+	); nil != err {
+		return DataExportOutput{}, err
+	}
+
+	return output, nil
+}
+
+func (r *Repository) CompleteDataExport(ctx context.Context, input CompleteDataExportInput) error {
+	_, err := r.Db.ExecContext(
+		ctx,
+		`UPDATE data_exports SET status='ready', blob_key=?, expires_at=? WHERE id=?`,
+		input.BlobKey,
+		input.ExpiresAt,
+		input.Id,
+	)
+
+	return err
+}
+
+func (r *Repository) FailDataExport(ctx context.Context, input DataExportInput) error {
+	_, err := r.Db.ExecContext(ctx, `UPDATE data_exports SET status='failed' WHERE id=?`, input.Id)
+
+	return err
+}
+
+// PurgeExpiredDataExports removes the exports whose link expired before the
+// given time and returns the keys of their archives.
+func (r *Repository) PurgeExpiredDataExports(ctx context.Context, input PurgeExpiredDataExportsInput) ([]string, error) {
+	return scanStrings(r.Db.QueryContext(
+		ctx,
+		`DELETE FROM data_exports WHERE expires_at < ? RETURNING blob_key`,
+		input.ExpiredBefore,
+	))
+}
+
+// VerifyEmail marks the email address of the user verified, provided it is
+// still the one the verification was sent to. Verifying again keeps the first
+// time.
+func (r *Repository) VerifyEmail(ctx context.Context, input VerifyEmailInput) (VerifyEmailOutput, error) {
+	var output VerifyEmailOutput
+	if err := r.tracked(ctx, input.Actor, `public_id=?`, input.PublicId, func(tx *Tx) error {
+		err := tx.QueryRowContext(
+			ctx,
+			`UPDATE users SET email_verified_at=coalesce(email_verified_at, now()),
+			version=version+CASE WHEN email_verified_at IS NULL THEN 1 ELSE 0 END
+			WHERE public_id=? AND email=? RETURNING email_verified_at`,
+			input.PublicId,
+			input.Email,
+		).Scan(&output.VerifiedAt)
+
+		if tx.dialect.uniqueViolation(err) {
+			return ErrEmailTaken
+		}
+
+		return err
+	}); nil != err {
+		return VerifyEmailOutput{}, err
+	}
+
+	return output, nil
+}
+
+// FindPreferences returns the preferences the user set as JSON documents by
+// key, preferences without a row are not part of it.
+func (r *Repository) FindPreferences(ctx context.Context, input FindByPublicIdInput) (map[string]string, error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT p.key, p.value FROM user_preferences p JOIN users u ON u.id=p.user_id WHERE u.public_id=?`,
+		input.PublicId,
+	)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	output := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err = rows.Scan(&key, &value); nil != err {
+			return nil, err
+		}
+		output[key] = value
+	}
+
+	return output, rows.Err()
+}
+
+// PutPreferences replaces every preference of the user, sql.ErrNoRows is
+// returned when the user does not exist.
+func (r *Repository) PutPreferences(ctx context.Context, input PutPreferencesInput) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var id int
+	if err = tx.QueryRowContext(ctx, `SELECT id FROM users WHERE public_id=?`+tx.dialect.forUpdate, input.PublicId).Scan(&id); nil != err {
+		return err
+	}
+	// the keys are listed as placeholders, there are only a few of them
+	args := []any{id}
+	condition := ``
+	if len(input.Values) > 0 {
+		condition = ` AND key NOT IN (?` + strings.Repeat(`, ?`, len(input.Values)-1) + `)`
+		for key := range input.Values {
+			args = append(args, key)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_preferences WHERE user_id=?`+condition, args...); nil != err {
+		return err
+	}
+	// unchanged values keep their updated_at
+	for key, value := range input.Values {
+		if _, err = tx.ExecContext(
+			ctx,
+			`INSERT INTO user_preferences (user_id, key, value) VALUES (?, ?, ?)
+			ON CONFLICT (user_id, key) DO UPDATE SET value=excluded.value, updated_at=now()
+			WHERE user_preferences.value IS DISTINCT FROM excluded.value`,
+			id,
+			key,
+			value,
+		); nil != err {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// FindUsers returns a page of the users, newest first.
+func (r *Repository) FindUsers(ctx context.Context, input FindUsersInput) ([]FindUsersOutput, error) {
+	var output []FindUsersOutput
+	if err := r.eachUser(ctx, input, func(row FindUsersOutput) error {
+		output = append(output, row)
+		return nil
+	}); nil != err {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// StreamUsers calls each with every user matching the input, newest first,
+// as the rows come from the database. Without Limit every user matches.
+// Stopping early is done by returning an error, it is passed on.
+func (r *Repository) StreamUsers(ctx context.Context, input FindUsersInput, each func(FindUsersOutput) error) error {
+	return r.eachUser(ctx, input, each)
+}
+
+func (r *Repository) eachUser(ctx context.Context, input FindUsersInput, each func(FindUsersOutput) error) error {
+	var (
+		conditions = []string{`TRUE`}
+		args       []any
+	)
+	if input.Before != 0 {
+		conditions = append(conditions, `id<?`)
+		args = append(args, input.Before)
+	}
+	if input.Status != "" {
+		conditions = append(conditions, `status=?`)
+		args = append(args, input.Status)
+	}
+	if nil != input.CreatedAfter {
+		conditions = append(conditions, `created_at>=?`)
+		args = append(args, *input.CreatedAfter)
+	}
+	if nil != input.CreatedBefore {
+		conditions = append(conditions, `created_at<?`)
+		args = append(args, *input.CreatedBefore)
+	}
+	if nil != input.Verified {
+		if *input.Verified {
+			conditions = append(conditions, `email_verified_at IS NOT NULL`)
+		} else {
+			conditions = append(conditions, `email_verified_at IS NULL`)
+		}
+	}
+	if input.PhonePrefix != "" {
+		conditions = append(conditions, `phone LIKE ? ESCAPE '\'`)
+		args = append(args, likePrefix(input.PhonePrefix))
+	}
+	if input.NamePrefix != "" {
+		conditions = append(conditions, `lower(full_name) LIKE lower(?) ESCAPE '\'`)
+		args = append(args, likePrefix(input.NamePrefix))
+	}
+
+	query := `SELECT id, public_id, full_name, phone, email, email_verified_at, status, admin, created_at, deleted_at
+		FROM users WHERE ` + strings.Join(conditions, ` AND `) + ` ORDER BY id DESC`
+	if input.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, input.Limit)
+	}
+	rows, err := r.Db.QueryContext(ctx, query, args...)
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var row FindUsersOutput
+		if err = rows.Scan(
+			&row.Id,
+			&row.PublicId,
+			&row.FullName,
+			&row.Phone,
+			&row.Email,
+			&row.EmailVerifiedAt,
+			&row.Status,
+			&row.Admin,
+			&row.CreatedAt,
+			&row.DeletedAt,
+		); nil != err {
+			return err
+		}
+		if err = each(row); nil != err {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// likePrefix is the LIKE pattern matching values starting with prefix, the
+// wildcards in prefix match themselves.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + `%`
+}
+
+// SetStatus suspends or reactivates the account. Deleted accounts are only
+// restored by their owner logging in within the grace period.
+func (r *Repository) SetStatus(ctx context.Context, input SetStatusInput) error {
+	action := ActionReactivate
+	if input.Status == StatusSuspended {
+		action = ActionSuspend
+	}
+
+	return r.adminAction(ctx, action, input.AdminActionInput, func(tx *Tx, id int, status string) error {
+		if status == StatusDeleted || !CanTransition(status, input.Status) {
+			return ErrStatusTransition
+		}
+		// tokens issued before the last suspension stay rejected
+		set := `status=?`
+		if input.Status == StatusSuspended {
+			set += `, suspended_at=now()`
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE users SET `+set+`, version=version+1 WHERE id=?`, input.Status, id)
+
+		return err
+	})
+}
+
+// RequirePasswordReset makes the user change the password at the next login
+// and revokes every token issued so far.
+func (r *Repository) RequirePasswordReset(ctx context.Context, input AdminActionInput) error {
+	return r.adminAction(ctx, ActionPasswordReset, input, func(tx *Tx, id int, _ string) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE users SET password_reset_required=true, session_version=session_version+1 WHERE id=?`,
+			id,
+		)
+
+		return err
+	})
+}
+
+// RevokeSessions revokes every token issued to the user so far.
+func (r *Repository) RevokeSessions(ctx context.Context, input AdminActionInput) error {
+	return r.adminAction(ctx, ActionRevokeSessions, input, func(tx *Tx, id int, _ string) error {
+		_, err := tx.ExecContext(ctx, `UPDATE users SET session_version=session_version+1 WHERE id=?`, id)
+
+		return err
+	})
+}
+
+// SetPassword replaces the password of the user on behalf of an
+// administrator, settles a pending forced reset and revokes every token
+// issued so far.
+func (r *Repository) SetPassword(ctx context.Context, input SetPasswordInput) error {
+	return r.adminAction(ctx, ActionPasswordReset, input.AdminActionInput, func(tx *Tx, id int, _ string) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE users SET password=?, password_reset_required=false, session_version=session_version+1, version=version+1
+			WHERE id=?`,
+			input.Password,
+			id,
+		)
+
+		return err
+	})
+}
+
+// FindAdminActions returns the actions of administrators on the account,
+// oldest first.
+func (r *Repository) FindAdminActions(ctx context.Context, input FindByPublicIdInput) ([]AdminActionOutput, error) {
+	rows, err := r.Db.QueryContext(
+		ctx,
+		`SELECT a.action, a.admin, a.reason, a.source_ip, a.created_at
+		FROM admin_actions a JOIN users u ON u.id=a.user_id WHERE u.public_id=? ORDER BY a.id`,
+		input.PublicId,
+	)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
 	}()
 
-	_, err = stmt.Exec(input.FullName, input.Phone, input.Slug)
+	var output []AdminActionOutput
+	for rows.Next() {
+		var row AdminActionOutput
+		if err = rows.Scan(&row.Action, &row.Admin, &row.Reason, &row.SourceIP, &row.CreatedAt); nil != err {
+			return nil, err
+		}
+		output = append(output, row)
+	}
+
+	return output, rows.Err()
+}
+
+// adminAction runs update on the locked account in a transaction and records
+// the action in admin_actions. sql.ErrNoRows is returned when the account
+// does not exist.
+func (r *Repository) adminAction(ctx context.Context, action string, input AdminActionInput, update func(tx *Tx, id int, status string) error) error {
+	tx, err := r.Db.BeginTx(ctx, nil)
 	if nil != err {
 		return err
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var (
+		id     int
+		status string
+	)
+	if err = tx.QueryRowContext(
+		ctx,
+		`SELECT id, status FROM users WHERE public_id=?`+tx.dialect.forUpdate,
+		input.PublicId,
+	).Scan(&id, &status); nil != err {
+		return err
+	}
+	if err = update(tx, id, status); nil != err {
+		return err
+	}
+
+	var ip *string
+	if input.Admin.IP != "" {
+		ip = &input.Admin.IP
+	}
+	if _, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO admin_actions (user_id, admin, action, reason, source_ip) VALUES (?, ?, ?, ?, ?)`,
+		id,
+		input.Admin.PublicId,
+		action,
+		input.Reason,
+		ip,
+	); nil != err {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword replaces the password the user was required to change and
+// revokes every token issued before.
+func (r *Repository) ResetPassword(ctx context.Context, input ResetPasswordInput) (ResetPasswordOutput, error) {
+	var output ResetPasswordOutput
+	if err := r.Db.QueryRowContext(
+		ctx,
+		`UPDATE users SET password=?, password_reset_required=false, session_version=session_version+1, version=version+1
+		WHERE id=? RETURNING session_version`,
+		input.Password,
+		input.Id,
+	).Scan(&output.SessionVersion); nil != err {
+		return ResetPasswordOutput{}, err
+	}
+
+	return output, nil
+}
+
+// ImportUsers registers the users in one transaction, skipping the ones whose
+// phone number is taken instead of failing.
+func (r *Repository) ImportUsers(ctx context.Context, input ImportUsersInput) (ImportUsersOutput, error) {
+	tx, err := r.Db.BeginTx(ctx, nil)
+	if nil != err {
+		return ImportUsersOutput{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(
+		ctx,
+		`INSERT INTO users (public_id, full_name, phone, password, password_reset_required) VALUES (?, ?, ?, ?, true)
+		ON CONFLICT (phone) DO NOTHING RETURNING id`,
+	)
+	if nil != err {
+		return ImportUsersOutput{}, err
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+
+	output := ImportUsersOutput{Created: make([]bool, 0, len(input.Users))}
+	for _, user := range input.Users {
+		var id int
+		err = stmt.QueryRowContext(ctx, user.PublicId, user.FullName, user.Phone, user.Password).Scan(&id)
+		switch {
+		case err == sql.ErrNoRows:
+			output.Created = append(output.Created, false)
+		case nil != err:
+			return ImportUsersOutput{}, err
+		default:
+			output.Created = append(output.Created, true)
+		}
+	}
+
+	if input.DryRun {
+		return output, nil
+	}
+
+	return output, tx.Commit()
+}
+
+// SearchUsers ranks the users by the word similarity of their full name,
+// best match first, leaving the deleted ones out. Without pg_trgm, e.g. on
+// other databases, every name is compared in Go instead of by the index.
+func (r *Repository) SearchUsers(ctx context.Context, input SearchUsersInput) ([]SearchUsersOutput, error) {
+	if !r.hasTrigram(ctx) {
+		return r.searchUsersFallback(ctx, input)
+	}
+
+	tx, err := r.Db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// <% only uses the index with the threshold set for the session
+	if _, err = tx.ExecContext(
+		ctx,
+		`SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)`,
+		strconv.FormatFloat(input.Threshold, 'f', -1, 64),
+	); nil != err {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT id, public_id, full_name, phone, email, email_verified_at, status, admin, created_at, deleted_at,
+		word_similarity(lower(?), lower(full_name)) AS score
+		FROM users WHERE lower(?) <% lower(full_name) AND status<>'deleted' ORDER BY score DESC, id DESC LIMIT ?`,
+		input.Name,
+		input.Name,
+		input.Limit,
+	)
+	if nil != err {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var output []SearchUsersOutput
+	for rows.Next() {
+		var row SearchUsersOutput
+		if err = rows.Scan(
+			&row.Id,
+			&row.PublicId,
+			&row.FullName,
+			&row.Phone,
+			&row.Email,
+			&row.EmailVerifiedAt,
+			&row.Status,
+			&row.Admin,
+			&row.CreatedAt,
+			&row.DeletedAt,
+			&row.Score,
+		); nil != err {
+			return nil, err
+		}
+		output = append(output, row)
+	}
+
+	return output, rows.Err()
+}
+
+// searchUsersFallback scores every user in Go, keeping the best Limit ones.
+func (r *Repository) searchUsersFallback(ctx context.Context, input SearchUsersInput) ([]SearchUsersOutput, error) {
+	var output []SearchUsersOutput
+	if err := r.eachUser(ctx, FindUsersInput{}, func(row FindUsersOutput) error {
+		if row.Status == StatusDeleted {
+			return nil
+		}
+		score := wordSimilarity(input.Name, row.FullName)
+		if score < input.Threshold {
+			return nil
+		}
+
+		// the users come newest first, equal scores stay in that order
+		i := sort.Search(len(output), func(i int) bool { return output[i].Score < score })
+		if i == input.Limit {
+			return nil
+		}
+		output = append(output, SearchUsersOutput{})
+		copy(output[i+1:], output[i:])
+		output[i] = SearchUsersOutput{FindUsersOutput: row, Score: score}
+		if len(output) > input.Limit {
+			output = output[:input.Limit]
+		}
+
+		return nil
+	}); nil != err {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// hasTrigram tells whether pg_trgm is installed. A failing lookup, e.g. on
+// another database, is not remembered and tried again next time.
+func (r *Repository) hasTrigram(ctx context.Context) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.Db.dialect.trigram {
+		return false
+	}
+	if nil != r.trigram {
+		return *r.trigram
+	}
+	var installed bool
+	if err := r.Db.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname='pg_trgm')`,
+	).Scan(&installed); nil != err {
+		return false
+	}
+	r.trigram = &installed
 
-	return nil
+	return installed
 }
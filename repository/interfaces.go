@@ -8,7 +8,43 @@ import "context"
 
 type RepositoryInterface interface {
 	FindByPhone(ctx context.Context, input FindByPhoneInput) (FindByPhoneOutput, error)
-	FindBySlug(ctx context.Context, input FindBySlugInput) (FindBySlugOutput, error)
+	FindByEmail(ctx context.Context, input FindByEmailInput) (FindByPhoneOutput, error)
+	FindByPublicId(ctx context.Context, input FindByPublicIdInput) (FindByPublicIdOutput, error)
 	Store(ctx context.Context, input RegistrationInput) (RegistrationOutput, error)
-	Put(ctx context.Context, input UpdateUserInput) error
+	ImportUsers(ctx context.Context, input ImportUsersInput) (ImportUsersOutput, error)
+	Put(ctx context.Context, input UpdateUserInput) (UpdateUserOutput, error)
+	Patch(ctx context.Context, input PatchUserInput) (UpdateUserOutput, error)
+	PutAvatar(ctx context.Context, input UpdateAvatarInput) error
+	FindSession(ctx context.Context, input FindSessionInput) (FindSessionOutput, error)
+	SoftDelete(ctx context.Context, input DeleteUserInput) (DeleteUserOutput, error)
+	Restore(ctx context.Context, input RestoreUserInput) error
+	PurgeDeleted(ctx context.Context, input PurgeDeletedUsersInput) (PurgeDeletedUsersOutput, error)
+	FindPhoneChanges(ctx context.Context, input FindByPublicIdInput) ([]FindPhoneChangesOutput, error)
+	StoreDataExport(ctx context.Context, input FindByPublicIdInput) (DataExportOutput, error)
+	FindLatestDataExport(ctx context.Context, input FindByPublicIdInput) (DataExportOutput, error)
+	FindDataExport(ctx context.Context, input DataExportInput) (DataExportOutput, error)
+	CompleteDataExport(ctx context.Context, input CompleteDataExportInput) error
+	FailDataExport(ctx context.Context, input DataExportInput) error
+	PurgeExpiredDataExports(ctx context.Context, input PurgeExpiredDataExportsInput) ([]string, error)
+	VerifyEmail(ctx context.Context, input VerifyEmailInput) (VerifyEmailOutput, error)
+	FindUsers(ctx context.Context, input FindUsersInput) ([]FindUsersOutput, error)
+	StreamUsers(ctx context.Context, input FindUsersInput, each func(FindUsersOutput) error) error
+	SearchUsers(ctx context.Context, input SearchUsersInput) ([]SearchUsersOutput, error)
+	SetStatus(ctx context.Context, input SetStatusInput) error
+	RequirePasswordReset(ctx context.Context, input AdminActionInput) error
+	RevokeSessions(ctx context.Context, input AdminActionInput) error
+	SetPassword(ctx context.Context, input SetPasswordInput) error
+	FindAdminActions(ctx context.Context, input FindByPublicIdInput) ([]AdminActionOutput, error)
+	ResetPassword(ctx context.Context, input ResetPasswordInput) (ResetPasswordOutput, error)
+	FindProfileHistory(ctx context.Context, input FindProfileHistoryInput) ([]ProfileHistoryOutput, error)
+	FindPreferences(ctx context.Context, input FindByPublicIdInput) (map[string]string, error)
+	PutPreferences(ctx context.Context, input PutPreferencesInput) error
+	FindAllPhone(ctx context.Context) ([]FindAllPhoneOutput, error)
+	PutPhone(ctx context.Context, input UpdatePhoneInput) error
+	StorePhoneChange(ctx context.Context, input StorePhoneChangeInput) error
+	FindPendingPhoneChange(ctx context.Context, input FindPendingPhoneChangeInput) (FindPendingPhoneChangeOutput, error)
+	FindPhoneChangeByCancelToken(ctx context.Context, input FindPhoneChangeByCancelTokenInput) (FindPendingPhoneChangeOutput, error)
+	PutPhoneChangeAttempt(ctx context.Context, input PhoneChangeInput) error
+	ConfirmPhoneChange(ctx context.Context, input PhoneChangeInput) error
+	CancelPhoneChange(ctx context.Context, input PhoneChangeInput) error
 }
@@ -45,17 +45,30 @@ func (_mr *MockRepositoryInterfaceMockRecorder) FindByPhone(arg0, arg1 interface
 	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindByPhone", reflect.TypeOf((*MockRepositoryInterface)(nil).FindByPhone), arg0, arg1)
 }
 
-// FindBySlug mocks base method
-func (_m *MockRepositoryInterface) FindBySlug(ctx context.Context, input FindBySlugInput) (FindBySlugOutput, error) {
-	ret := _m.ctrl.Call(_m, "FindBySlug", ctx, input)
-	ret0, _ := ret[0].(FindBySlugOutput)
+// FindByEmail mocks base method
+func (_m *MockRepositoryInterface) FindByEmail(ctx context.Context, input FindByEmailInput) (FindByPhoneOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindByEmail", ctx, input)
+	ret0, _ := ret[0].(FindByPhoneOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail
+func (_mr *MockRepositoryInterfaceMockRecorder) FindByEmail(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindByEmail", reflect.TypeOf((*MockRepositoryInterface)(nil).FindByEmail), arg0, arg1)
+}
+
+// FindByPublicId mocks base method
+func (_m *MockRepositoryInterface) FindByPublicId(ctx context.Context, input FindByPublicIdInput) (FindByPublicIdOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindByPublicId", ctx, input)
+	ret0, _ := ret[0].(FindByPublicIdOutput)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindBySlug indicates an expected call of FindBySlug
-func (_mr *MockRepositoryInterfaceMockRecorder) FindBySlug(arg0, arg1 interface{}) *gomock.Call {
-	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindBySlug", reflect.TypeOf((*MockRepositoryInterface)(nil).FindBySlug), arg0, arg1)
+// FindByPublicId indicates an expected call of FindByPublicId
+func (_mr *MockRepositoryInterfaceMockRecorder) FindByPublicId(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindByPublicId", reflect.TypeOf((*MockRepositoryInterface)(nil).FindByPublicId), arg0, arg1)
 }
 
 // Store mocks base method
@@ -71,14 +84,455 @@ func (_mr *MockRepositoryInterfaceMockRecorder) Store(arg0, arg1 interface{}) *g
 	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Store", reflect.TypeOf((*MockRepositoryInterface)(nil).Store), arg0, arg1)
 }
 
+// ImportUsers mocks base method
+func (_m *MockRepositoryInterface) ImportUsers(ctx context.Context, input ImportUsersInput) (ImportUsersOutput, error) {
+	ret := _m.ctrl.Call(_m, "ImportUsers", ctx, input)
+	ret0, _ := ret[0].(ImportUsersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportUsers indicates an expected call of ImportUsers
+func (_mr *MockRepositoryInterfaceMockRecorder) ImportUsers(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "ImportUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).ImportUsers), arg0, arg1)
+}
+
 // Put mocks base method
-func (_m *MockRepositoryInterface) Put(ctx context.Context, input UpdateUserInput) error {
+func (_m *MockRepositoryInterface) Put(ctx context.Context, input UpdateUserInput) (UpdateUserOutput, error) {
 	ret := _m.ctrl.Call(_m, "Put", ctx, input)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Put indicates an expected call of Put
 func (_mr *MockRepositoryInterfaceMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
 	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Put", reflect.TypeOf((*MockRepositoryInterface)(nil).Put), arg0, arg1)
 }
+
+// Patch mocks base method
+func (_m *MockRepositoryInterface) Patch(ctx context.Context, input PatchUserInput) (UpdateUserOutput, error) {
+	ret := _m.ctrl.Call(_m, "Patch", ctx, input)
+	ret0, _ := ret[0].(UpdateUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Patch indicates an expected call of Patch
+func (_mr *MockRepositoryInterfaceMockRecorder) Patch(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Patch", reflect.TypeOf((*MockRepositoryInterface)(nil).Patch), arg0, arg1)
+}
+
+// PutAvatar mocks base method
+func (_m *MockRepositoryInterface) PutAvatar(ctx context.Context, input UpdateAvatarInput) error {
+	ret := _m.ctrl.Call(_m, "PutAvatar", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutAvatar indicates an expected call of PutAvatar
+func (_mr *MockRepositoryInterfaceMockRecorder) PutAvatar(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "PutAvatar", reflect.TypeOf((*MockRepositoryInterface)(nil).PutAvatar), arg0, arg1)
+}
+
+// FindSession mocks base method
+func (_m *MockRepositoryInterface) FindSession(ctx context.Context, input FindSessionInput) (FindSessionOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindSession", ctx, input)
+	ret0, _ := ret[0].(FindSessionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSession indicates an expected call of FindSession
+func (_mr *MockRepositoryInterfaceMockRecorder) FindSession(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindSession", reflect.TypeOf((*MockRepositoryInterface)(nil).FindSession), arg0, arg1)
+}
+
+// SoftDelete mocks base method
+func (_m *MockRepositoryInterface) SoftDelete(ctx context.Context, input DeleteUserInput) (DeleteUserOutput, error) {
+	ret := _m.ctrl.Call(_m, "SoftDelete", ctx, input)
+	ret0, _ := ret[0].(DeleteUserOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDelete indicates an expected call of SoftDelete
+func (_mr *MockRepositoryInterfaceMockRecorder) SoftDelete(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "SoftDelete", reflect.TypeOf((*MockRepositoryInterface)(nil).SoftDelete), arg0, arg1)
+}
+
+// Restore mocks base method
+func (_m *MockRepositoryInterface) Restore(ctx context.Context, input RestoreUserInput) error {
+	ret := _m.ctrl.Call(_m, "Restore", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore
+func (_mr *MockRepositoryInterfaceMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "Restore", reflect.TypeOf((*MockRepositoryInterface)(nil).Restore), arg0, arg1)
+}
+
+// PurgeDeleted mocks base method
+func (_m *MockRepositoryInterface) PurgeDeleted(ctx context.Context, input PurgeDeletedUsersInput) (PurgeDeletedUsersOutput, error) {
+	ret := _m.ctrl.Call(_m, "PurgeDeleted", ctx, input)
+	ret0, _ := ret[0].(PurgeDeletedUsersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted
+func (_mr *MockRepositoryInterfaceMockRecorder) PurgeDeleted(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "PurgeDeleted", reflect.TypeOf((*MockRepositoryInterface)(nil).PurgeDeleted), arg0, arg1)
+}
+
+// FindPhoneChanges mocks base method
+func (_m *MockRepositoryInterface) FindPhoneChanges(ctx context.Context, input FindByPublicIdInput) ([]FindPhoneChangesOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindPhoneChanges", ctx, input)
+	ret0, _ := ret[0].([]FindPhoneChangesOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPhoneChanges indicates an expected call of FindPhoneChanges
+func (_mr *MockRepositoryInterfaceMockRecorder) FindPhoneChanges(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindPhoneChanges", reflect.TypeOf((*MockRepositoryInterface)(nil).FindPhoneChanges), arg0, arg1)
+}
+
+// StoreDataExport mocks base method
+func (_m *MockRepositoryInterface) StoreDataExport(ctx context.Context, input FindByPublicIdInput) (DataExportOutput, error) {
+	ret := _m.ctrl.Call(_m, "StoreDataExport", ctx, input)
+	ret0, _ := ret[0].(DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StoreDataExport indicates an expected call of StoreDataExport
+func (_mr *MockRepositoryInterfaceMockRecorder) StoreDataExport(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "StoreDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).StoreDataExport), arg0, arg1)
+}
+
+// FindLatestDataExport mocks base method
+func (_m *MockRepositoryInterface) FindLatestDataExport(ctx context.Context, input FindByPublicIdInput) (DataExportOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindLatestDataExport", ctx, input)
+	ret0, _ := ret[0].(DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatestDataExport indicates an expected call of FindLatestDataExport
+func (_mr *MockRepositoryInterfaceMockRecorder) FindLatestDataExport(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindLatestDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).FindLatestDataExport), arg0, arg1)
+}
+
+// FindDataExport mocks base method
+func (_m *MockRepositoryInterface) FindDataExport(ctx context.Context, input DataExportInput) (DataExportOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindDataExport", ctx, input)
+	ret0, _ := ret[0].(DataExportOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDataExport indicates an expected call of FindDataExport
+func (_mr *MockRepositoryInterfaceMockRecorder) FindDataExport(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).FindDataExport), arg0, arg1)
+}
+
+// CompleteDataExport mocks base method
+func (_m *MockRepositoryInterface) CompleteDataExport(ctx context.Context, input CompleteDataExportInput) error {
+	ret := _m.ctrl.Call(_m, "CompleteDataExport", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteDataExport indicates an expected call of CompleteDataExport
+func (_mr *MockRepositoryInterfaceMockRecorder) CompleteDataExport(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "CompleteDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).CompleteDataExport), arg0, arg1)
+}
+
+// FailDataExport mocks base method
+func (_m *MockRepositoryInterface) FailDataExport(ctx context.Context, input DataExportInput) error {
+	ret := _m.ctrl.Call(_m, "FailDataExport", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailDataExport indicates an expected call of FailDataExport
+func (_mr *MockRepositoryInterfaceMockRecorder) FailDataExport(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FailDataExport", reflect.TypeOf((*MockRepositoryInterface)(nil).FailDataExport), arg0, arg1)
+}
+
+// PurgeExpiredDataExports mocks base method
+func (_m *MockRepositoryInterface) PurgeExpiredDataExports(ctx context.Context, input PurgeExpiredDataExportsInput) ([]string, error) {
+	ret := _m.ctrl.Call(_m, "PurgeExpiredDataExports", ctx, input)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredDataExports indicates an expected call of PurgeExpiredDataExports
+func (_mr *MockRepositoryInterfaceMockRecorder) PurgeExpiredDataExports(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "PurgeExpiredDataExports", reflect.TypeOf((*MockRepositoryInterface)(nil).PurgeExpiredDataExports), arg0, arg1)
+}
+
+// VerifyEmail mocks base method
+func (_m *MockRepositoryInterface) VerifyEmail(ctx context.Context, input VerifyEmailInput) (VerifyEmailOutput, error) {
+	ret := _m.ctrl.Call(_m, "VerifyEmail", ctx, input)
+	ret0, _ := ret[0].(VerifyEmailOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail
+func (_mr *MockRepositoryInterfaceMockRecorder) VerifyEmail(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "VerifyEmail", reflect.TypeOf((*MockRepositoryInterface)(nil).VerifyEmail), arg0, arg1)
+}
+
+// FindUsers mocks base method
+func (_m *MockRepositoryInterface) FindUsers(ctx context.Context, input FindUsersInput) ([]FindUsersOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindUsers", ctx, input)
+	ret0, _ := ret[0].([]FindUsersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsers indicates an expected call of FindUsers
+func (_mr *MockRepositoryInterfaceMockRecorder) FindUsers(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).FindUsers), arg0, arg1)
+}
+
+// StreamUsers mocks base method
+func (_m *MockRepositoryInterface) StreamUsers(ctx context.Context, input FindUsersInput, each func(FindUsersOutput) error) error {
+	ret := _m.ctrl.Call(_m, "StreamUsers", ctx, input, each)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamUsers indicates an expected call of StreamUsers
+func (_mr *MockRepositoryInterfaceMockRecorder) StreamUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "StreamUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).StreamUsers), arg0, arg1, arg2)
+}
+
+// SearchUsers mocks base method
+func (_m *MockRepositoryInterface) SearchUsers(ctx context.Context, input SearchUsersInput) ([]SearchUsersOutput, error) {
+	ret := _m.ctrl.Call(_m, "SearchUsers", ctx, input)
+	ret0, _ := ret[0].([]SearchUsersOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers
+func (_mr *MockRepositoryInterfaceMockRecorder) SearchUsers(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "SearchUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).SearchUsers), arg0, arg1)
+}
+
+// SetStatus mocks base method
+func (_m *MockRepositoryInterface) SetStatus(ctx context.Context, input SetStatusInput) error {
+	ret := _m.ctrl.Call(_m, "SetStatus", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetStatus indicates an expected call of SetStatus
+func (_mr *MockRepositoryInterfaceMockRecorder) SetStatus(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "SetStatus", reflect.TypeOf((*MockRepositoryInterface)(nil).SetStatus), arg0, arg1)
+}
+
+// SetPassword mocks base method
+func (_m *MockRepositoryInterface) SetPassword(ctx context.Context, input SetPasswordInput) error {
+	ret := _m.ctrl.Call(_m, "SetPassword", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPassword indicates an expected call of SetPassword
+func (_mr *MockRepositoryInterfaceMockRecorder) SetPassword(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "SetPassword", reflect.TypeOf((*MockRepositoryInterface)(nil).SetPassword), arg0, arg1)
+}
+
+// RequirePasswordReset mocks base method
+func (_m *MockRepositoryInterface) RequirePasswordReset(ctx context.Context, input AdminActionInput) error {
+	ret := _m.ctrl.Call(_m, "RequirePasswordReset", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequirePasswordReset indicates an expected call of RequirePasswordReset
+func (_mr *MockRepositoryInterfaceMockRecorder) RequirePasswordReset(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "RequirePasswordReset", reflect.TypeOf((*MockRepositoryInterface)(nil).RequirePasswordReset), arg0, arg1)
+}
+
+// RevokeSessions mocks base method
+func (_m *MockRepositoryInterface) RevokeSessions(ctx context.Context, input AdminActionInput) error {
+	ret := _m.ctrl.Call(_m, "RevokeSessions", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions
+func (_mr *MockRepositoryInterfaceMockRecorder) RevokeSessions(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "RevokeSessions", reflect.TypeOf((*MockRepositoryInterface)(nil).RevokeSessions), arg0, arg1)
+}
+
+// FindAdminActions mocks base method
+func (_m *MockRepositoryInterface) FindAdminActions(ctx context.Context, input FindByPublicIdInput) ([]AdminActionOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindAdminActions", ctx, input)
+	ret0, _ := ret[0].([]AdminActionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAdminActions indicates an expected call of FindAdminActions
+func (_mr *MockRepositoryInterfaceMockRecorder) FindAdminActions(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindAdminActions", reflect.TypeOf((*MockRepositoryInterface)(nil).FindAdminActions), arg0, arg1)
+}
+
+// ResetPassword mocks base method
+func (_m *MockRepositoryInterface) ResetPassword(ctx context.Context, input ResetPasswordInput) (ResetPasswordOutput, error) {
+	ret := _m.ctrl.Call(_m, "ResetPassword", ctx, input)
+	ret0, _ := ret[0].(ResetPasswordOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (_mr *MockRepositoryInterfaceMockRecorder) ResetPassword(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "ResetPassword", reflect.TypeOf((*MockRepositoryInterface)(nil).ResetPassword), arg0, arg1)
+}
+
+// FindProfileHistory mocks base method
+func (_m *MockRepositoryInterface) FindProfileHistory(ctx context.Context, input FindProfileHistoryInput) ([]ProfileHistoryOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindProfileHistory", ctx, input)
+	ret0, _ := ret[0].([]ProfileHistoryOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProfileHistory indicates an expected call of FindProfileHistory
+func (_mr *MockRepositoryInterfaceMockRecorder) FindProfileHistory(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindProfileHistory", reflect.TypeOf((*MockRepositoryInterface)(nil).FindProfileHistory), arg0, arg1)
+}
+
+// FindPreferences mocks base method
+func (_m *MockRepositoryInterface) FindPreferences(ctx context.Context, input FindByPublicIdInput) (map[string]string, error) {
+	ret := _m.ctrl.Call(_m, "FindPreferences", ctx, input)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPreferences indicates an expected call of FindPreferences
+func (_mr *MockRepositoryInterfaceMockRecorder) FindPreferences(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindPreferences", reflect.TypeOf((*MockRepositoryInterface)(nil).FindPreferences), arg0, arg1)
+}
+
+// PutPreferences mocks base method
+func (_m *MockRepositoryInterface) PutPreferences(ctx context.Context, input PutPreferencesInput) error {
+	ret := _m.ctrl.Call(_m, "PutPreferences", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutPreferences indicates an expected call of PutPreferences
+func (_mr *MockRepositoryInterfaceMockRecorder) PutPreferences(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "PutPreferences", reflect.TypeOf((*MockRepositoryInterface)(nil).PutPreferences), arg0, arg1)
+}
+
+// FindAllPhone mocks base method
+func (_m *MockRepositoryInterface) FindAllPhone(ctx context.Context) ([]FindAllPhoneOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindAllPhone", ctx)
+	ret0, _ := ret[0].([]FindAllPhoneOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllPhone indicates an expected call of FindAllPhone
+func (_mr *MockRepositoryInterfaceMockRecorder) FindAllPhone(arg0 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindAllPhone", reflect.TypeOf((*MockRepositoryInterface)(nil).FindAllPhone), arg0)
+}
+
+// PutPhone mocks base method
+func (_m *MockRepositoryInterface) PutPhone(ctx context.Context, input UpdatePhoneInput) error {
+	ret := _m.ctrl.Call(_m, "PutPhone", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutPhone indicates an expected call of PutPhone
+func (_mr *MockRepositoryInterfaceMockRecorder) PutPhone(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "PutPhone", reflect.TypeOf((*MockRepositoryInterface)(nil).PutPhone), arg0, arg1)
+}
+
+// StorePhoneChange mocks base method
+func (_m *MockRepositoryInterface) StorePhoneChange(ctx context.Context, input StorePhoneChangeInput) error {
+	ret := _m.ctrl.Call(_m, "StorePhoneChange", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StorePhoneChange indicates an expected call of StorePhoneChange
+func (_mr *MockRepositoryInterfaceMockRecorder) StorePhoneChange(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "StorePhoneChange", reflect.TypeOf((*MockRepositoryInterface)(nil).StorePhoneChange), arg0, arg1)
+}
+
+// FindPendingPhoneChange mocks base method
+func (_m *MockRepositoryInterface) FindPendingPhoneChange(ctx context.Context, input FindPendingPhoneChangeInput) (FindPendingPhoneChangeOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindPendingPhoneChange", ctx, input)
+	ret0, _ := ret[0].(FindPendingPhoneChangeOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPendingPhoneChange indicates an expected call of FindPendingPhoneChange
+func (_mr *MockRepositoryInterfaceMockRecorder) FindPendingPhoneChange(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindPendingPhoneChange", reflect.TypeOf((*MockRepositoryInterface)(nil).FindPendingPhoneChange), arg0, arg1)
+}
+
+// FindPhoneChangeByCancelToken mocks base method
+func (_m *MockRepositoryInterface) FindPhoneChangeByCancelToken(ctx context.Context, input FindPhoneChangeByCancelTokenInput) (FindPendingPhoneChangeOutput, error) {
+	ret := _m.ctrl.Call(_m, "FindPhoneChangeByCancelToken", ctx, input)
+	ret0, _ := ret[0].(FindPendingPhoneChangeOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPhoneChangeByCancelToken indicates an expected call of FindPhoneChangeByCancelToken
+func (_mr *MockRepositoryInterfaceMockRecorder) FindPhoneChangeByCancelToken(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "FindPhoneChangeByCancelToken", reflect.TypeOf((*MockRepositoryInterface)(nil).FindPhoneChangeByCancelToken), arg0, arg1)
+}
+
+// PutPhoneChangeAttempt mocks base method
+func (_m *MockRepositoryInterface) PutPhoneChangeAttempt(ctx context.Context, input PhoneChangeInput) error {
+	ret := _m.ctrl.Call(_m, "PutPhoneChangeAttempt", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutPhoneChangeAttempt indicates an expected call of PutPhoneChangeAttempt
+func (_mr *MockRepositoryInterfaceMockRecorder) PutPhoneChangeAttempt(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "PutPhoneChangeAttempt", reflect.TypeOf((*MockRepositoryInterface)(nil).PutPhoneChangeAttempt), arg0, arg1)
+}
+
+// ConfirmPhoneChange mocks base method
+func (_m *MockRepositoryInterface) ConfirmPhoneChange(ctx context.Context, input PhoneChangeInput) error {
+	ret := _m.ctrl.Call(_m, "ConfirmPhoneChange", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmPhoneChange indicates an expected call of ConfirmPhoneChange
+func (_mr *MockRepositoryInterfaceMockRecorder) ConfirmPhoneChange(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "ConfirmPhoneChange", reflect.TypeOf((*MockRepositoryInterface)(nil).ConfirmPhoneChange), arg0, arg1)
+}
+
+// CancelPhoneChange mocks base method
+func (_m *MockRepositoryInterface) CancelPhoneChange(ctx context.Context, input PhoneChangeInput) error {
+	ret := _m.ctrl.Call(_m, "CancelPhoneChange", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelPhoneChange indicates an expected call of CancelPhoneChange
+func (_mr *MockRepositoryInterfaceMockRecorder) CancelPhoneChange(arg0, arg1 interface{}) *gomock.Call {
+	return _mr.mock.ctrl.RecordCallWithMethodType(_mr.mock, "CancelPhoneChange", reflect.TypeOf((*MockRepositoryInterface)(nil).CancelPhoneChange), arg0, arg1)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var postgres = dialect{
+	forUpdate: ` FOR UPDATE`,
+	dateText: func(column string) string {
+		return `to_char(` + column + `, 'YYYY-MM-DD')`
+	},
+	uniqueViolation: func(err error) bool {
+		var pqErr *pq.Error
+		return errors.As(err, &pqErr) && pqErr.Code == "23505"
+	},
+	trigram: true,
+	open: func(dsn string) (*sql.DB, error) {
+		return sql.Open("postgres", dsn)
+	},
+}
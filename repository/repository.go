@@ -2,25 +2,39 @@
 package repository
 
 import (
-	"database/sql"
-
-	_ "github.com/lib/pq"
+	"fmt"
+	"sync"
 )
 
 type Repository struct {
-	Db *sql.DB
+	Db *DB
+
+	mu sync.Mutex
+	// trigram tells whether pg_trgm is installed, nil until looked up
+	trigram *bool
 }
 
 type NewRepositoryOptions struct {
-	Dsn string
+	// Driver is DriverPostgres, the default, or DriverSQLite in binaries
+	// built with the sqlite tag
+	Driver string
+	Dsn    string
 }
 
 func NewRepository(opts NewRepositoryOptions) *Repository {
-	db, err := sql.Open("postgres", opts.Dsn)
+	driver := opts.Driver
+	if driver == "" {
+		driver = DriverPostgres
+	}
+	d, ok := dialects[driver]
+	if !ok {
+		panic(fmt.Errorf("unknown database driver %q, sqlite is only built with -tags sqlite", driver))
+	}
+	db, err := d.open(opts.Dsn)
 	if err != nil {
 		panic(err)
 	}
 	return &Repository{
-		Db: db,
+		Db: &DB{DB: db, driver: driver, dialect: d},
 	}
 }
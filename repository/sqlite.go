@@ -0,0 +1,66 @@
+//go:build sqlite
+
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTime is how times are stored, in UTC and fixed width so comparing
+// the text compares the times. The driver parses it back into time.Time for
+// the timestamp and date columns.
+const sqliteTime = "2006-01-02 15:04:05.000000000-07:00"
+
+func init() {
+	// the queries set the current time with now(), as on Postgres
+	sqlite.MustRegisterScalarFunction("now", 0, func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error) {
+		return time.Now().UTC().Format(sqliteTime), nil
+	})
+
+	dialects[DriverSQLite] = dialect{
+		// a write transaction locks the whole database
+		forUpdate: ``,
+		dateText: func(column string) string {
+			return `substr(` + column + `, 1, 10)`
+		},
+		arg: func(v any) any {
+			switch t := v.(type) {
+			case time.Time:
+				return t.UTC().Format(sqliteTime)
+			case *time.Time:
+				if nil == t {
+					return nil
+				}
+				return t.UTC().Format(sqliteTime)
+			}
+			return v
+		},
+		uniqueViolation: func(err error) bool {
+			var sqliteErr *sqlite.Error
+			return errors.As(err, &sqliteErr) &&
+				(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+		},
+		open: func(dsn string) (*sql.DB, error) {
+			sep := "?"
+			if strings.Contains(dsn, "?") {
+				sep = "&"
+			}
+			db, err := sql.Open("sqlite", dsn+sep+"_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate")
+			if nil != err {
+				return nil, err
+			}
+			// SQLite allows one writer at a time, and every connection to
+			// :memory: would open a database of its own
+			db.SetMaxOpenConns(1)
+
+			return db, nil
+		},
+	}
+}
@@ -0,0 +1,349 @@
+//go:build sqlite
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/SawitProRecruitment/UserService/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSQLiteRepository returns a repository on a migrated in-memory database.
+func newSQLiteRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	repo := NewRepository(NewRepositoryOptions{Driver: DriverSQLite, Dsn: ":memory:"})
+	t.Cleanup(func() {
+		_ = repo.Db.Close()
+	})
+	migrator, err := migrations.NewMigrator(migrations.NewMigratorOptions{Db: repo.Db.DB, Driver: repo.Db.Driver()})
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	return repo
+}
+
+// sqliteContext fails the test instead of hanging when a query waits for the
+// single connection held elsewhere.
+func sqliteContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+
+	return ctx
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := sqliteContext(t)
+
+	migrator, err := migrations.NewMigrator(migrations.NewMigratorOptions{Db: repo.Db.DB, Driver: repo.Db.Driver()})
+	require.NoError(t, err)
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt, "%s is applied", s.Migration)
+	}
+
+	reverted, err := migrator.Down(ctx, len(statuses))
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(statuses))
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(statuses))
+}
+
+func TestSQLitePublicIdMigration(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := sqliteContext(t)
+
+	migrator, err := migrations.NewMigrator(migrations.NewMigratorOptions{Db: repo.Db.DB, Driver: repo.Db.Driver()})
+	require.NoError(t, err)
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	steps := len(statuses)
+	for _, s := range statuses {
+		if s.Name == "public_id" {
+			break
+		}
+		steps--
+	}
+	require.Less(t, steps, len(statuses), "public_id is migrated")
+	_, err = migrator.Down(ctx, steps)
+	require.NoError(t, err)
+
+	// users registered before public ids
+	for _, phone := range []string{"+628123456789", "+628123456780"} {
+		_, err = repo.Db.ExecContext(ctx, `INSERT INTO users (slug, full_name, phone, password) VALUES (?, ?, ?, ?)`, "slug"+phone[10:], "Budi", phone, "hash")
+		require.NoError(t, err)
+	}
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	ids := map[string]bool{}
+	for _, phone := range []string{"+628123456789", "+628123456780"} {
+		login, err := repo.FindByPhone(ctx, FindByPhoneInput{Phone: phone})
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`, login.PublicId)
+		ids[login.PublicId] = true
+
+		session, err := repo.FindSession(ctx, FindSessionInput{Subject: "slug" + phone[10:]})
+		require.NoError(t, err, "the legacy slug still resolves")
+		assert.Equal(t, login.PublicId, session.PublicId)
+	}
+	assert.Len(t, ids, 2, "every user draws its own id")
+}
+
+func TestSQLiteProfile(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := sqliteContext(t)
+
+	stored, err := repo.Store(ctx, RegistrationInput{PublicId: "01HBUDI", FullName: "Budi Santoso", Phone: "+628123456789", Password: "hash"})
+	require.NoError(t, err)
+	_, err = repo.Store(ctx, RegistrationInput{PublicId: "01HSITI", FullName: "Siti", Phone: "+628123456789", Password: "hash"})
+	assert.Error(t, err, "the phone number is unique")
+
+	login, err := repo.FindByPhone(ctx, FindByPhoneInput{Phone: "+628123456789"})
+	require.NoError(t, err)
+	assert.Equal(t, stored.Id, login.Id)
+	assert.Equal(t, StatusActive, login.Status)
+
+	email := "Budi@Example.com"
+	birth := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	updated, err := repo.Put(ctx, UpdateUserInput{
+		PublicId: "01HBUDI",
+		FullName: "Budi Santoso",
+		Phone:    "+628123456789",
+		ProfileAttributes: ProfileAttributes{
+			Email:     &email,
+			BirthDate: &birth,
+			Address:   &Address{Street: "Jl. Sudirman 1", City: "Jakarta", Province: "DKI Jakarta", PostalCode: "10210", Country: "ID"},
+		},
+		Version: 1,
+		Actor:   Actor{PublicId: "01HBUDI", IP: "127.0.0.1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+	_, err = repo.Put(ctx, UpdateUserInput{PublicId: "01HBUDI", FullName: "Budi", Phone: "+628123456789", Version: 1})
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	user, err := repo.FindByPublicId(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, user.Version)
+	require.NotNil(t, user.BirthDate)
+	assert.True(t, birth.Equal(*user.BirthDate))
+
+	// patches of different attributes, both without a version, keep each other
+	gender := "male"
+	_, err = repo.Patch(ctx, PatchUserInput{PublicId: "01HBUDI", Attributes: &ProfileAttributes{Gender: &gender}, Actor: SystemActor})
+	require.NoError(t, err)
+	// only the patched address members are written
+	_, err = repo.Patch(ctx, PatchUserInput{
+		PublicId:       "01HBUDI",
+		Attributes:     &ProfileAttributes{Address: &Address{City: "Jakarta Pusat"}},
+		AddressMembers: []string{"city"},
+		Actor:          SystemActor,
+	})
+	require.NoError(t, err)
+	user, err = repo.FindByPublicId(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.Equal(t, &Address{Street: "Jl. Sudirman 1", City: "Jakarta Pusat", Province: "DKI Jakarta", PostalCode: "10210", Country: "ID"}, user.Address)
+	updated, err = repo.Patch(ctx, PatchUserInput{PublicId: "01HBUDI", Remove: []string{"address"}, Actor: SystemActor})
+	require.NoError(t, err)
+	assert.Equal(t, 5, updated.Version)
+	user, err = repo.FindByPublicId(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.Equal(t, &gender, user.Gender)
+	assert.Nil(t, user.Address)
+	assert.Equal(t, &email, user.Email)
+	require.NotNil(t, user.BirthDate)
+
+	history, err := repo.FindProfileHistory(ctx, FindProfileHistoryInput{PublicId: "01HBUDI", Limit: 20})
+	require.NoError(t, err)
+	changed := map[string]*string{}
+	for _, h := range history {
+		changed[h.Field] = h.NewValue
+	}
+	assert.Len(t, changed, 8, "email, birth date, gender and five address fields")
+	require.NotNil(t, changed["birth_date"])
+	assert.Equal(t, "1990-05-17", *changed["birth_date"])
+
+	verified, err := repo.VerifyEmail(ctx, VerifyEmailInput{PublicId: "01HBUDI", Email: email, Actor: SystemActor})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), verified.VerifiedAt, time.Minute)
+	_, err = repo.VerifyEmail(ctx, VerifyEmailInput{PublicId: "01HBUDI", Email: email, Actor: SystemActor})
+	require.NoError(t, err)
+	profile, err := repo.FindByPublicId(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.Equal(t, user.Version+1, profile.Version, "the ETag changes with the first verification only")
+	login, err = repo.FindByEmail(ctx, FindByEmailInput{Email: "budi@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, stored.Id, login.Id)
+
+	_, err = repo.Store(ctx, RegistrationInput{PublicId: "01HSITI", FullName: "Siti", Phone: "+628111111111", Password: "hash"})
+	require.NoError(t, err)
+	_, err = repo.Patch(ctx, PatchUserInput{PublicId: "01HSITI", Attributes: &ProfileAttributes{Email: &email}, Actor: SystemActor})
+	require.NoError(t, err)
+	_, err = repo.VerifyEmail(ctx, VerifyEmailInput{PublicId: "01HSITI", Email: email, Actor: SystemActor})
+	assert.ErrorIs(t, err, ErrEmailTaken)
+
+	require.NoError(t, repo.PutPreferences(ctx, PutPreferencesInput{PublicId: "01HBUDI", Values: map[string]string{"language": `"id"`, "theme": `"dark"`}}))
+	require.NoError(t, repo.PutPreferences(ctx, PutPreferencesInput{PublicId: "01HBUDI", Values: map[string]string{"language": `"en"`}}))
+	preferences, err := repo.FindPreferences(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"language": `"en"`}, preferences)
+	assert.ErrorIs(t, repo.PutPreferences(ctx, PutPreferencesInput{PublicId: "01HNOBODY"}), sql.ErrNoRows)
+}
+
+func TestSQLiteAdministration(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := sqliteContext(t)
+
+	imported, err := repo.ImportUsers(ctx, ImportUsersInput{Users: []RegistrationInput{
+		{PublicId: "01HBUDI", FullName: "Budi Santoso", Phone: "+628123456789", Password: "hash"},
+		{PublicId: "01HSITI", FullName: "Siti Rahayu", Phone: "+628111111111", Password: "hash"},
+		{PublicId: "01HAGUS", FullName: "Agus_Salim", Phone: "+628123456789", Password: "hash"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, true, false}, imported.Created)
+
+	users, err := repo.FindUsers(ctx, FindUsersInput{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "01HSITI", users[0].PublicId, "newest first")
+	assert.WithinDuration(t, time.Now(), users[0].CreatedAt, time.Minute)
+
+	after := time.Now().Add(-time.Minute)
+	verified := false
+	users, err = repo.FindUsers(ctx, FindUsersInput{CreatedAfter: &after, Verified: &verified, PhonePrefix: "+62812", NamePrefix: "BUDI"})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "01HBUDI", users[0].PublicId)
+	users, err = repo.FindUsers(ctx, FindUsersInput{NamePrefix: "B_di"})
+	require.NoError(t, err)
+	assert.Empty(t, users, "wildcards match themselves")
+
+	found, err := repo.SearchUsers(ctx, SearchUsersInput{Name: "santosa", Threshold: 0.3, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, "01HBUDI", found[0].PublicId)
+
+	admin := Actor{PublicId: "01HADMIN"}
+	require.NoError(t, repo.SetStatus(ctx, SetStatusInput{AdminActionInput: AdminActionInput{PublicId: "01HSITI", Admin: admin, Reason: "spam"}, Status: StatusSuspended}))
+	assert.ErrorIs(t, repo.SetStatus(ctx, SetStatusInput{AdminActionInput: AdminActionInput{PublicId: "01HSITI", Admin: admin, Reason: "spam"}, Status: StatusSuspended}), ErrStatusTransition)
+	actions, err := repo.FindAdminActions(ctx, FindByPublicIdInput{PublicId: "01HSITI"})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, ActionSuspend, actions[0].Action)
+	assert.Equal(t, "spam", actions[0].Reason)
+	session, err := repo.FindSession(ctx, FindSessionInput{Subject: "01HSITI"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuspended, session.Status)
+	assert.NotNil(t, session.SuspendedAt)
+
+	login, err := repo.FindByPhone(ctx, FindByPhoneInput{Phone: "+628123456789"})
+	require.NoError(t, err)
+	assert.True(t, login.PasswordResetRequired)
+	reset, err := repo.ResetPassword(ctx, ResetPasswordInput{Id: login.Id, Password: "new hash"})
+	require.NoError(t, err)
+	assert.Equal(t, login.SessionVersion+1, reset.SessionVersion)
+
+	require.NoError(t, repo.SetPassword(ctx, SetPasswordInput{AdminActionInput: AdminActionInput{PublicId: "01HSITI", Admin: SystemActor, Reason: "locked out"}, Password: "set hash"}))
+	actions, err = repo.FindAdminActions(ctx, FindByPublicIdInput{PublicId: "01HSITI"})
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	assert.Equal(t, ActionPasswordReset, actions[1].Action)
+	assert.Equal(t, SystemActor.PublicId, actions[1].Admin)
+	assert.ErrorIs(t, repo.SetPassword(ctx, SetPasswordInput{AdminActionInput: AdminActionInput{PublicId: "01HNONE", Admin: SystemActor, Reason: "locked out"}}), sql.ErrNoRows)
+
+	deleted, err := repo.SoftDelete(ctx, DeleteUserInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), deleted.DeletedAt, time.Minute)
+	purged, err := repo.PurgeDeleted(ctx, PurgeDeletedUsersInput{DeletedBefore: deleted.DeletedAt.Add(-time.Second)})
+	require.NoError(t, err)
+	assert.Zero(t, purged.Count, "within the grace period")
+	purged, err = repo.PurgeDeleted(ctx, PurgeDeletedUsersInput{DeletedBefore: deleted.DeletedAt.Add(time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged.Count)
+	_, err = repo.FindByPhone(ctx, FindByPhoneInput{Phone: "+628123456789"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestSQLiteExpiry(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := sqliteContext(t)
+
+	_, err := repo.Store(ctx, RegistrationInput{PublicId: "01HBUDI", FullName: "Budi Santoso", Phone: "+628123456789", Password: "hash"})
+	require.NoError(t, err)
+
+	// times are compared as text, a zone other than UTC must not matter
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	require.NoError(t, repo.StorePhoneChange(ctx, StorePhoneChangeInput{
+		PublicId: "01HBUDI", Phone: "+628999999999", Otp: "otp", CancelToken: "expired", ExpiresAt: time.Now().Add(-time.Minute).In(jakarta),
+	}))
+	_, err = repo.FindPendingPhoneChange(ctx, FindPendingPhoneChangeInput{PublicId: "01HBUDI"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, repo.StorePhoneChange(ctx, StorePhoneChangeInput{
+		PublicId: "01HBUDI", Phone: "+628999999999", Otp: "otp", CancelToken: "pending", ExpiresAt: time.Now().Add(time.Hour).In(jakarta),
+	}))
+	pending, err := repo.FindPhoneChangeByCancelToken(ctx, FindPhoneChangeByCancelTokenInput{CancelToken: "pending"})
+	require.NoError(t, err)
+	require.NoError(t, repo.ConfirmPhoneChange(ctx, PhoneChangeInput{Id: pending.Id, Actor: SystemActor, CancelUntil: time.Now().Add(time.Hour).In(jakarta)}))
+	login, err := repo.FindByPhone(ctx, FindByPhoneInput{Phone: "+628999999999"})
+	require.NoError(t, err)
+	assert.Equal(t, "01HBUDI", login.PublicId)
+
+	// the old number reverts the confirmed change until its cancel window closes
+	confirmed, err := repo.FindPhoneChangeByCancelToken(ctx, FindPhoneChangeByCancelTokenInput{CancelToken: "pending"})
+	require.NoError(t, err)
+	assert.True(t, confirmed.Confirmed)
+	assert.Equal(t, "01HBUDI", confirmed.PublicId)
+	require.NoError(t, repo.CancelPhoneChange(ctx, PhoneChangeInput{Id: confirmed.Id, Actor: SystemActor}))
+	reverted, err := repo.FindByPhone(ctx, FindByPhoneInput{Phone: "+628123456789"})
+	require.NoError(t, err)
+	assert.Equal(t, login.SessionVersion+1, reverted.SessionVersion, "tokens issued so far are revoked")
+	_, err = repo.FindPhoneChangeByCancelToken(ctx, FindPhoneChangeByCancelTokenInput{CancelToken: "pending"})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, repo.StorePhoneChange(ctx, StorePhoneChangeInput{
+		PublicId: "01HBUDI", Phone: "+628999999999", Otp: "otp", CancelToken: "closed", ExpiresAt: time.Now().Add(time.Hour).In(jakarta),
+	}))
+	pending, err = repo.FindPhoneChangeByCancelToken(ctx, FindPhoneChangeByCancelTokenInput{CancelToken: "closed"})
+	require.NoError(t, err)
+	require.NoError(t, repo.ConfirmPhoneChange(ctx, PhoneChangeInput{Id: pending.Id, Actor: SystemActor, CancelUntil: time.Now().Add(-time.Minute)}))
+	_, err = repo.FindPhoneChangeByCancelToken(ctx, FindPhoneChangeByCancelTokenInput{CancelToken: "closed"})
+	assert.ErrorIs(t, err, sql.ErrNoRows, "the cancel window closed")
+
+	require.NoError(t, repo.StorePhoneChange(ctx, StorePhoneChangeInput{
+		PublicId: "01HBUDI", Phone: "+628777777777", Otp: "otp", CancelToken: "taken", ExpiresAt: time.Now().Add(time.Hour).In(jakarta),
+	}))
+	_, err = repo.Store(ctx, RegistrationInput{PublicId: "01HSITI", FullName: "Siti", Phone: "+628777777777", Password: "hash"})
+	require.NoError(t, err)
+	pending, err = repo.FindPhoneChangeByCancelToken(ctx, FindPhoneChangeByCancelTokenInput{CancelToken: "taken"})
+	require.NoError(t, err)
+	err = repo.ConfirmPhoneChange(ctx, PhoneChangeInput{Id: pending.Id, Actor: SystemActor, CancelUntil: time.Now().Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrPhoneTaken, "registered since the change was requested")
+
+	export, err := repo.StoreDataExport(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	assert.Equal(t, DataExportPending, export.Status)
+	expiresAt := time.Now().Add(time.Hour).In(jakarta)
+	require.NoError(t, repo.CompleteDataExport(ctx, CompleteDataExportInput{Id: export.Id, BlobKey: "archive", ExpiresAt: expiresAt}))
+	export, err = repo.FindLatestDataExport(ctx, FindByPublicIdInput{PublicId: "01HBUDI"})
+	require.NoError(t, err)
+	require.NotNil(t, export.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*export.ExpiresAt))
+
+	purged, err := repo.PurgeExpiredDataExports(ctx, PurgeExpiredDataExportsInput{ExpiredBefore: time.Now()})
+	require.NoError(t, err)
+	assert.Empty(t, purged)
+	purged, err = repo.PurgeExpiredDataExports(ctx, PurgeExpiredDataExportsInput{ExpiredBefore: expiresAt.Add(time.Second)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"archive"}, purged)
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// The fallback of SearchUsers for databases without pg_trgm ranks the names
+// in Go the way the extension does: every word, lower cased and padded with
+// two spaces in front and one behind, is split into its trigrams, and two
+// sets of trigrams are as similar as they share out of all of them.
+
+// trigrams returns the set of trigrams of the words of s, words being runs of
+// letters and digits.
+func trigrams(s string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// similarity is pg_trgm's similarity of two sets of trigrams, from 0 to 1.
+func similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// wordSimilarity approximates pg_trgm's word_similarity, the best similarity
+// of query to any run of consecutive words of name. The extension compares
+// runs of trigrams instead, which may cut words short and scores a little
+// higher for partial words.
+func wordSimilarity(query, name string) float64 {
+	q := trigrams(query)
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var best float64
+	for i := range words {
+		for j := i + 1; j <= len(words); j++ {
+			if score := similarity(q, trigrams(strings.Join(words[i:j], " "))); score > best {
+				best = score
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigrams(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[string]struct{}{
+		"  b": {}, " bu": {}, "bud": {}, "udi": {}, "di ": {},
+		"  s": {}, " s ": {},
+	}, trigrams("BUDI, S."), "words are lower cased, punctuation separates them")
+	assert.Empty(t, trigrams(" - "))
+}
+
+func TestWordSimilarity(t *testing.T) {
+	t.Parallel()
+
+	type Case struct {
+		query, name string
+		expected    float64
+	}
+	var testCases = []Case{
+		{query: "budi", name: "Budi Santoso", expected: 1},
+		// 7 shared out of 16 trigrams of the whole name
+		{query: "budy sant", name: "Budi Santoso", expected: 7.0 / 16},
+		{query: "santosa", name: "Budi Santoso", expected: 6.0 / 10},
+		{query: "siti", name: "Budi Santoso", expected: 1.0 / 12},
+		{query: "budi", name: "", expected: 0},
+	}
+
+	for _, cases := range testCases {
+		assert.InDelta(t, cases.expected, wordSimilarity(cases.query, cases.name), 1e-9, "%q in %q", cases.query, cases.name)
+	}
+}
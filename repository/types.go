@@ -1,11 +1,30 @@
 // This file contains types that are used in the repository layer.
 package repository
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrVersionConflict is returned by updates guarded by a version when the
+// row was changed by someone else in the meantime.
+var ErrVersionConflict = errors.New("repository: version conflict")
+
+// ErrEmailTaken is returned when the email address is verified by another
+// user already.
+var ErrEmailTaken = errors.New("repository: email taken")
+
+// ErrPhoneTaken is returned when the phone number is registered by another
+// user already.
+var ErrPhoneTaken = errors.New("repository: phone taken")
+
 type RegistrationInput struct {
-	Slug     string
+	PublicId string
 	FullName string
 	Phone    string
 	Password string
+	// Admin appoints the user administrator, only operators do
+	Admin bool
 }
 
 type RegistrationOutput struct {
@@ -18,25 +37,406 @@ type FindByPhoneInput struct {
 
 type FindByPhoneOutput struct {
 	Id       int
-	Slug     string
+	PublicId string
 	FullName string
 	Phone    string
 	Password string
+	// SessionVersion is embedded into the tokens, see FindSessionOutput
+	SessionVersion int
+	Status         string
+	// PasswordResetRequired is set by an administrator, the password has to
+	// be changed before logging in
+	PasswordResetRequired bool
+	// DeletedAt is set while the account waits for the purge
+	DeletedAt *time.Time
 }
 
-type FindBySlugInput struct {
-	Slug string
+type FindByEmailInput struct {
+	Email string
 }
 
-type FindBySlugOutput struct {
-	Slug     string
+type FindByPublicIdInput struct {
+	PublicId string
+}
+
+type FindByPublicIdOutput struct {
+	PublicId string
 	FullName string
 	Phone    string
 	Password string
+	ProfileAttributes
+	// EmailVerifiedAt is set once the owner of Email confirmed it
+	EmailVerifiedAt *time.Time
+	// Avatar is the blob key prefix of the profile photo, nil without photo
+	Avatar *string
+	// Version is incremented on every change of the row
+	Version int
+}
+
+// ProfileAttributes are the optional attributes of a user, nil when unknown.
+type ProfileAttributes struct {
+	Email     *string
+	BirthDate *time.Time
+	Gender    *string
+	Address   *Address
+}
+
+type Address struct {
+	Street     string
+	City       string
+	Province   string
+	PostalCode string
+	Country    string
 }
 
 type UpdateUserInput struct {
-	Slug     string
+	PublicId string
 	FullName string
 	Phone    string
+	ProfileAttributes
+	// Version guards the update against concurrent changes, zero skips the check
+	Version int
+	Actor   Actor
+}
+
+type UpdateUserOutput struct {
+	// Version of the user row after the update, the profile ETag derives
+	// from it
+	Version int
+}
+
+// PatchUserInput only changes the columns of the non nil fields, down to the
+// single attributes, so concurrent patches of other fields are kept.
+type PatchUserInput struct {
+	PublicId   string
+	FullName   *string
+	Phone      *string
+	Attributes *ProfileAttributes
+	// Remove clears the attributes named email, birth_date, gender or address
+	Remove []string
+	// AddressMembers limits the address columns written to the named members
+	// of Attributes.Address, street, city, province, postal_code or country.
+	// All of them are written when it is empty.
+	AddressMembers []string
+	// Version guards the update against concurrent changes, zero skips the check
+	Version int
+	Actor   Actor
+}
+
+type FindAllPhoneOutput struct {
+	Id    int
+	Phone string
+}
+
+type UpdatePhoneInput struct {
+	Id    int
+	Phone string
+	Actor Actor
+}
+
+type StorePhoneChangeInput struct {
+	PublicId    string
+	Phone       string
+	Otp         string
+	CancelToken string
+	ExpiresAt   time.Time
+}
+
+type FindPendingPhoneChangeInput struct {
+	PublicId string
+}
+
+type FindPhoneChangeByCancelTokenInput struct {
+	CancelToken string
+}
+
+type FindPendingPhoneChangeOutput struct {
+	Id       int
+	UserId   int
+	PublicId string
+	Phone    string
+	Otp      string
+	Attempts int
+	// Confirmed changes are still reverted by the cancel token until
+	// ExpiresAt
+	Confirmed bool
+	ExpiresAt time.Time
+}
+
+type PhoneChangeInput struct {
+	Id int
+	// Actor is recorded in the profile history by ConfirmPhoneChange and
+	// CancelPhoneChange
+	Actor Actor
+	// CancelUntil is set by ConfirmPhoneChange, the cancel token sent to the
+	// old number reverts the confirmed change until then
+	CancelUntil time.Time
+}
+
+type UpdateAvatarInput struct {
+	PublicId string
+	Avatar   *string
+	Actor    Actor
+}
+
+type FindSessionInput struct {
+	// Subject is the sub claim of the token
+	Subject string
+}
+
+type FindSessionOutput struct {
+	PublicId string
+	// SessionVersion is incremented to revoke every token issued before
+	SessionVersion int
+	Status         string
+	// SuspendedAt is the last suspension, kept after reactivation
+	SuspendedAt *time.Time
+	DeletedAt   *time.Time
+	Admin       bool
+	// PiiAccess lets an administrator see the contact details of the users
+	// in bulk exports
+	PiiAccess bool
+}
+
+type DeleteUserInput struct {
+	PublicId string
+}
+
+type DeleteUserOutput struct {
+	DeletedAt time.Time
+}
+
+type RestoreUserInput struct {
+	Id int
+}
+
+type PurgeDeletedUsersInput struct {
+	DeletedBefore time.Time
+}
+
+type PurgeDeletedUsersOutput struct {
+	Count int
+	// Avatars and Exports of the purged users, their blobs have to be
+	// deleted as well
+	Avatars []string
+	Exports []string
+}
+
+type FindPhoneChangesOutput struct {
+	Phone       string
+	Attempts    int
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	ConfirmedAt *time.Time
+	CancelledAt *time.Time
+}
+
+// Statuses of a data export.
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+type DataExportInput struct {
+	Id int
+}
+
+type DataExportOutput struct {
+	Id     int
+	Status string
+	// BlobKey and ExpiresAt are set once the export is ready
+	BlobKey   *string
+	CreatedAt time.Time
+	ExpiresAt *time.Time
+}
+
+type CompleteDataExportInput struct {
+	Id        int
+	BlobKey   string
+	ExpiresAt time.Time
+}
+
+type PurgeExpiredDataExportsInput struct {
+	ExpiredBefore time.Time
+}
+
+type VerifyEmailInput struct {
+	PublicId string
+	Email    string
+	Actor    Actor
+}
+
+type VerifyEmailOutput struct {
+	VerifiedAt time.Time
+}
+
+type PutPreferencesInput struct {
+	PublicId string
+	// Values are JSON documents by preference key, keys left out are removed
+	Values map[string]string
+}
+
+// Actor is who changed a profile, recorded in its history.
+type Actor struct {
+	// PublicId of the user making the change, SystemActor for changes made
+	// by the service itself
+	PublicId string
+	// IP the change was requested from, empty without a request
+	IP string
+}
+
+// SystemActor records changes made by the service itself, such as backfills.
+var SystemActor = Actor{PublicId: "system"}
+
+type FindProfileHistoryInput struct {
+	PublicId string
+	// Before is the id of the last change of the previous page, zero for
+	// the first page
+	Before int64
+	Limit  int
+}
+
+type ProfileHistoryOutput struct {
+	Id        int64
+	Field     string
+	OldValue  *string
+	NewValue  *string
+	Actor     string
+	SourceIP  *string
+	ChangedAt time.Time
+}
+
+// Statuses of a user account.
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	// StatusDeleted accounts wait for the purge, see SoftDelete
+	StatusDeleted = "deleted"
+)
+
+// statusTransitions are the statuses an account may move to from each status.
+var statusTransitions = map[string][]string{
+	StatusActive:    {StatusSuspended, StatusDeleted},
+	StatusSuspended: {StatusActive},
+	// restored by logging in within the grace period
+	StatusDeleted: {StatusActive},
+}
+
+// CanTransition tells whether an account may move from one status to the other.
+func CanTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindUsersInput narrows the users down by every field set.
+type FindUsersInput struct {
+	Status        string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Verified filters on a verified email address
+	Verified    *bool
+	PhonePrefix string
+	// NamePrefix matches the start of the full name, ignoring case
+	NamePrefix string
+	// Before is the id of the last user of the previous page, zero for the
+	// first page
+	Before int
+	Limit  int
+}
+
+type FindUsersOutput struct {
+	Id              int
+	PublicId        string
+	FullName        string
+	Phone           string
+	Email           *string
+	EmailVerifiedAt *time.Time
+	Status          string
+	Admin           bool
+	CreatedAt       time.Time
+	DeletedAt       *time.Time
+}
+
+// ErrStatusTransition is returned when the account may not move from its
+// current status to the requested one, see CanTransition.
+var ErrStatusTransition = errors.New("repository: status transition")
+
+// Actions of an administrator on an account, recorded in admin_actions.
+const (
+	ActionSuspend        = "suspend"
+	ActionReactivate     = "reactivate"
+	ActionPasswordReset  = "password_reset"
+	ActionRevokeSessions = "revoke_sessions"
+)
+
+type AdminActionInput struct {
+	PublicId string
+	// Admin acting on the account
+	Admin  Actor
+	Reason string
+}
+
+type AdminActionOutput struct {
+	Action string
+	// Admin is the public id of the administrator
+	Admin     string
+	Reason    string
+	SourceIP  *string
+	CreatedAt time.Time
+}
+
+type SetStatusInput struct {
+	AdminActionInput
+	// Status is either StatusSuspended or StatusActive
+	Status string
+}
+
+type SetPasswordInput struct {
+	AdminActionInput
+	// Password is the bcrypt hash of the new password
+	Password string
+}
+
+type ResetPasswordInput struct {
+	Id       int
+	Password string
+}
+
+type ResetPasswordOutput struct {
+	// SessionVersion to embed into the token issued with the new password
+	SessionVersion int
+}
+
+type ImportUsersInput struct {
+	// Users are registered with password_reset_required set
+	Users []RegistrationInput
+	// DryRun rolls the transaction back, the output still tells which
+	// users would be created
+	DryRun bool
+}
+
+type ImportUsersOutput struct {
+	// Created is false for the users whose phone number is registered
+	// already, in the order of the input
+	Created []bool
+}
+
+type SearchUsersInput struct {
+	// Name is compared with the words of the full names, ignoring case
+	Name string
+	// Threshold is the lowest score of a match, from 0 to 1
+	Threshold float64
+	Limit     int
+}
+
+type SearchUsersOutput struct {
+	FindUsersOutput
+	// Score is the word similarity of the name, from 0 to 1
+	Score float64
 }
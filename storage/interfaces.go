@@ -0,0 +1,23 @@
+// This file contains the interfaces for the storage layer.
+// The storage layer is responsible for keeping binary objects, such as
+// avatars, outside of the database.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Get for keys without object.
+var ErrNotFound = errors.New("storage: object not found")
+
+// BlobStore keeps objects addressed by slash separated keys.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get opens the object for reading, the caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the object from.
+	URL(key string) string
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalBlobStore keeps the objects as files below a directory, meant for
+// development and single instance deployments. Mount Handler under BaseURL to
+// serve them.
+type LocalBlobStore struct {
+	dir     string
+	baseURL string
+}
+
+type NewLocalBlobStoreOptions struct {
+	Dir string
+	// BaseURL prefixes the keys in URL, e.g. "/avatars" or
+	// "https://cdn.example.com/avatars"
+	BaseURL string
+}
+
+func NewLocalBlobStore(opts NewLocalBlobStoreOptions) *LocalBlobStore {
+	return &LocalBlobStore{
+		dir:     opts.Dir,
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+	}
+}
+
+func (s *LocalBlobStore) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	name, err := s.path(key)
+	if nil != err {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); nil != err {
+		return err
+	}
+
+	// write next to the target and rename, readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if nil != err {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = io.Copy(tmp, r); nil != err {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); nil != err {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); nil != err {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *LocalBlobStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if nil != err {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if nil != err {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (s *LocalBlobStore) Delete(_ context.Context, key string) error {
+	name, err := s.path(key)
+	if nil != err {
+		return err
+	}
+
+	if err = os.Remove(name); nil != err && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler serves the objects by their key, directories are not listed so the
+// keys can not be enumerated.
+func (s *LocalBlobStore) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, err := s.path(strings.TrimPrefix(r.URL.Path, "/"))
+		if nil != err {
+			http.NotFound(w, r)
+			return
+		}
+
+		f, err := os.Open(name)
+		if nil != err {
+			http.NotFound(w, r)
+			return
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		info, err := f.Stat()
+		if nil != err || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	})
+}
+
+// path maps key to a file below the directory, keys escaping it are rejected.
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || path.Clean("/"+key) != "/"+key {
+		return "", fmt.Errorf("storage: invalid key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	t.Parallel()
+
+	s := NewLocalBlobStore(NewLocalBlobStoreOptions{Dir: t.TempDir(), BaseURL: "/avatars/"})
+	ctx := context.Background()
+
+	assert.NoError(t, s.Put(ctx, "abc/small.jpg", strings.NewReader("thumbnail"), "image/jpeg"))
+	assert.Equal(t, "/avatars/abc/small.jpg", s.URL("abc/small.jpg"))
+
+	for path, expected := range map[string]int{
+		"/abc/small.jpg":  http.StatusOK,
+		"/abc/":           http.StatusNotFound,
+		"/abc/large.jpg":  http.StatusNotFound,
+		"/../small.jpg":   http.StatusNotFound,
+		"/abc//small.jpg": http.StatusNotFound,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = path
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, expected, rec.Code, path)
+	}
+
+	for _, key := range []string{"", "../escape.jpg", "/abs.jpg", "abc/../../escape.jpg"} {
+		assert.Error(t, s.Put(ctx, key, strings.NewReader(""), "image/jpeg"), key)
+	}
+
+	r, err := s.Get(ctx, "abc/small.jpg")
+	if assert.NoError(t, err) {
+		data, _ := io.ReadAll(r)
+		assert.Equal(t, "thumbnail", string(data))
+		assert.NoError(t, r.Close())
+	}
+
+	assert.NoError(t, s.Delete(ctx, "abc/small.jpg"))
+	assert.NoError(t, s.Delete(ctx, "abc/small.jpg"))
+	_, err = s.Get(ctx, "abc/small.jpg")
+	assert.ErrorIs(t, err, ErrNotFound)
+}